@@ -0,0 +1,199 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modbus
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgeo-scada/modbus/internal/transport"
+)
+
+// ASCII framing constants.
+const (
+	// ASCIIStart is the character that starts every ASCII frame.
+	ASCIIStart = ':'
+
+	// DefaultASCIIDelimiter is the default end-of-frame character (the LF of CR LF).
+	DefaultASCIIDelimiter = '\n'
+
+	// ASCIIMaxFrameSize is the maximum size of an ASCII frame in characters.
+	ASCIIMaxFrameSize = 513
+)
+
+// LRC computes the Modbus ASCII longitudinal redundancy check
+// (two's complement of the 8-bit sum of data).
+func LRC(data []byte) byte {
+	var sum byte
+	for _, b := range data {
+		sum += b
+	}
+	return -sum
+}
+
+// EncodeASCIIFrame builds an ASCII frame (':' + hex(unit ID, PDU, LRC) + CR + delimiter).
+func EncodeASCIIFrame(unitID UnitID, pdu []byte, delimiter byte) []byte {
+	raw := make([]byte, 1+len(pdu)+1)
+	raw[0] = byte(unitID)
+	copy(raw[1:], pdu)
+	raw[len(raw)-1] = LRC(raw[:len(raw)-1])
+
+	frame := make([]byte, 0, 1+hex.EncodedLen(len(raw))+2)
+	frame = append(frame, ASCIIStart)
+	frame = append(frame, strings.ToUpper(hex.EncodeToString(raw))...)
+	return append(frame, '\r', delimiter)
+}
+
+// DecodeASCIIFrame validates the LRC of an ASCII frame and returns its unit ID and PDU.
+// The frame must include the start character and the CR + delimiter trailer.
+func DecodeASCIIFrame(frame []byte, delimiter byte) (UnitID, []byte, error) {
+	if len(frame) < 1+6+2 {
+		return 0, nil, fmt.Errorf("%w: ASCII frame too short", ErrInvalidFrame)
+	}
+	if len(frame) > ASCIIMaxFrameSize {
+		return 0, nil, fmt.Errorf("%w: ASCII frame too long", ErrInvalidFrame)
+	}
+	if frame[0] != ASCIIStart {
+		return 0, nil, fmt.Errorf("%w: missing start character", ErrInvalidFrame)
+	}
+	if frame[len(frame)-2] != '\r' || frame[len(frame)-1] != delimiter {
+		return 0, nil, fmt.Errorf("%w: missing frame trailer", ErrInvalidFrame)
+	}
+
+	payload := frame[1 : len(frame)-2]
+	if len(payload)%2 != 0 {
+		return 0, nil, fmt.Errorf("%w: odd number of hex characters", ErrInvalidFrame)
+	}
+	raw := make([]byte, hex.DecodedLen(len(payload)))
+	if _, err := hex.Decode(raw, payload); err != nil {
+		return 0, nil, fmt.Errorf("%w: %v", ErrInvalidFrame, err)
+	}
+
+	n := len(raw) - 1
+	expected := LRC(raw[:n])
+	if raw[n] != expected {
+		return 0, nil, fmt.Errorf("%w: expected %02X, got %02X", ErrInvalidLRC, expected, raw[n])
+	}
+
+	pdu := make([]byte, n-1)
+	copy(pdu, raw[1:n])
+	return UnitID(raw[0]), pdu, nil
+}
+
+// readASCIIResponse reads characters until a complete frame terminated by
+// CR + delimiter has been received. Characters before the start character
+// are discarded.
+func readASCIIResponse(conn transport.Conn, deadline time.Time, delimiter byte) ([]byte, error) {
+	if err := conn.SetReadDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 0, ASCIIMaxFrameSize)
+	chunk := make([]byte, ASCIIMaxFrameSize)
+
+	for {
+		n, err := conn.Read(chunk)
+		for _, b := range chunk[:n] {
+			if b == ASCIIStart {
+				// A start character always begins a new frame
+				buf = buf[:0]
+			} else if len(buf) == 0 {
+				continue
+			}
+			buf = append(buf, b)
+			if b == delimiter && len(buf) >= 2 && buf[len(buf)-2] == '\r' {
+				return buf, nil
+			}
+			if len(buf) >= ASCIIMaxFrameSize {
+				return nil, fmt.Errorf("%w: ASCII frame too long", ErrInvalidFrame)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// asciiTransport frames PDUs for Modbus ASCII over a byte stream.
+type asciiTransport struct {
+	stream streamTransport
+
+	mu        sync.Mutex
+	delimiter byte
+}
+
+// Connect opens the underlying stream.
+func (t *asciiTransport) Connect(ctx context.Context) error {
+	return t.stream.Connect(ctx)
+}
+
+// Close closes the underlying stream.
+func (t *asciiTransport) Close() error {
+	return t.stream.Close()
+}
+
+// Delimiter returns the end-of-frame character currently in use.
+func (t *asciiTransport) Delimiter() byte {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.delimiter
+}
+
+// Send sends a PDU in an ASCII frame and returns the response PDU.
+func (t *asciiTransport) Send(ctx context.Context, unitID UnitID, pdu []byte) ([]byte, error) {
+	delimiter := t.Delimiter()
+
+	respData, err := t.stream.Exchange(ctx, EncodeASCIIFrame(unitID, pdu, delimiter),
+		func(conn transport.Conn, deadline time.Time) ([]byte, error) {
+			return readASCIIResponse(conn, deadline, delimiter)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	respUnit, respPDU, err := DecodeASCIIFrame(respData, delimiter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate unit ID
+	if respUnit != unitID {
+		return nil, fmt.Errorf("%w: unit ID mismatch (expected %d, got %d)",
+			ErrInvalidResponse, unitID, respUnit)
+	}
+
+	// The server answers a delimiter change with the old delimiter and
+	// expects the new one from then on.
+	if isChangeDelimiterRequest(pdu) && !IsExceptionResponse(respPDU) {
+		t.mu.Lock()
+		t.delimiter = pdu[3]
+		t.mu.Unlock()
+	}
+
+	return respPDU, nil
+}
+
+// isChangeDelimiterRequest reports whether pdu is an FC08 Change ASCII
+// Input Delimiter request.
+func isChangeDelimiterRequest(pdu []byte) bool {
+	return len(pdu) >= 5 &&
+		FunctionCode(pdu[0]) == FuncDiagnostics &&
+		binary.BigEndian.Uint16(pdu[1:3]) == DiagChangeASCIIInputDelimiter
+}
@@ -0,0 +1,126 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+
+package modbus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestASCIIClientIntegration(t *testing.T) {
+	master, slave := openPTY(t)
+	defer master.Close()
+
+	handler := NewMemoryHandler(65536, 65536)
+	handler.SetHoldingRegister(1, 0, 1234)
+	handler.SetHoldingRegister(1, 1, 5678)
+
+	go serveASCII(master, NewServer(handler), nil)
+
+	client, err := NewASCIIClient(slave, WithUnitID(1), WithBaudRate(115200), WithTimeout(2*time.Second))
+	if err != nil {
+		t.Fatalf("NewASCIIClient failed: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	t.Run("ReadHoldingRegisters", func(t *testing.T) {
+		regs, err := client.ReadHoldingRegisters(ctx, 0, 2)
+		if err != nil {
+			t.Fatalf("ReadHoldingRegisters failed: %v", err)
+		}
+		if regs[0] != 1234 || regs[1] != 5678 {
+			t.Errorf("Expected [1234 5678], got %v", regs)
+		}
+	})
+
+	t.Run("WriteSingleRegister", func(t *testing.T) {
+		if err := client.WriteSingleRegister(ctx, 5, 0xBEEF); err != nil {
+			t.Fatalf("WriteSingleRegister failed: %v", err)
+		}
+		regs, err := client.ReadHoldingRegisters(ctx, 5, 1)
+		if err != nil {
+			t.Fatalf("ReadHoldingRegisters failed: %v", err)
+		}
+		if regs[0] != 0xBEEF {
+			t.Errorf("Expected 0xBEEF, got 0x%04X", regs[0])
+		}
+	})
+
+	t.Run("Exception", func(t *testing.T) {
+		_, err := client.Diagnostics(ctx, 0x99, nil)
+		if !IsIllegalFunction(err) {
+			t.Errorf("Expected illegal function, got %v", err)
+		}
+	})
+
+	t.Run("ChangeASCIIInputDelimiter", func(t *testing.T) {
+		if err := client.ChangeASCIIInputDelimiter(ctx, '$'); err != nil {
+			t.Fatalf("ChangeASCIIInputDelimiter failed: %v", err)
+		}
+		if d := client.transport.(*asciiTransport).Delimiter(); d != '$' {
+			t.Errorf("Delimiter: expected '$', got %q", d)
+		}
+
+		regs, err := client.ReadHoldingRegisters(ctx, 0, 1)
+		if err != nil {
+			t.Fatalf("ReadHoldingRegisters after delimiter change failed: %v", err)
+		}
+		if regs[0] != 1234 {
+			t.Errorf("Expected 1234, got %d", regs[0])
+		}
+	})
+}
+
+func TestASCIIClientInvalidLRC(t *testing.T) {
+	master, slave := openPTY(t)
+	defer master.Close()
+
+	handler := NewMemoryHandler(65536, 65536)
+	go serveASCII(master, NewServer(handler), func(frame []byte) []byte {
+		// Corrupt the last LRC digit
+		i := len(frame) - 3
+		if frame[i] == '0' {
+			frame[i] = '1'
+		} else {
+			frame[i] = '0'
+		}
+		return frame
+	})
+
+	client, err := NewASCIIClient(slave, WithBaudRate(115200), WithTimeout(2*time.Second))
+	if err != nil {
+		t.Fatalf("NewASCIIClient failed: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	_, err = client.ReadHoldingRegisters(ctx, 0, 1)
+	if !errors.Is(err, ErrInvalidLRC) {
+		t.Errorf("Expected ErrInvalidLRC, got %v", err)
+	}
+}
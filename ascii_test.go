@@ -0,0 +1,200 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modbus
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestLRC(t *testing.T) {
+	tests := []struct {
+		data     []byte
+		expected byte
+	}{
+		{[]byte{0x11, 0x03, 0x00, 0x6B, 0x00, 0x03}, 0x7E},
+		{[]byte{0x01, 0x03, 0x00, 0x00, 0x00, 0x0A}, 0xF2},
+		{[]byte{}, 0x00},
+	}
+
+	for _, tt := range tests {
+		if lrc := LRC(tt.data); lrc != tt.expected {
+			t.Errorf("LRC(% X): expected %02X, got %02X", tt.data, tt.expected, lrc)
+		}
+	}
+}
+
+func TestEncodeASCIIFrame(t *testing.T) {
+	pdu := []byte{0x03, 0x00, 0x6B, 0x00, 0x03}
+
+	result := EncodeASCIIFrame(0x11, pdu, DefaultASCIIDelimiter)
+	if string(result) != ":1103006B00037E\r\n" {
+		t.Errorf("Expected %q, got %q", ":1103006B00037E\r\n", result)
+	}
+
+	result = EncodeASCIIFrame(0x11, pdu, '$')
+	if string(result) != ":1103006B00037E\r$" {
+		t.Errorf("Expected %q, got %q", ":1103006B00037E\r$", result)
+	}
+}
+
+func TestDecodeASCIIFrame(t *testing.T) {
+	unitID, pdu, err := DecodeASCIIFrame([]byte(":1103006B00037E\r\n"), DefaultASCIIDelimiter)
+	if err != nil {
+		t.Fatalf("DecodeASCIIFrame failed: %v", err)
+	}
+	if unitID != 0x11 {
+		t.Errorf("UnitID: expected 17, got %d", unitID)
+	}
+	if string(pdu) != string([]byte{0x03, 0x00, 0x6B, 0x00, 0x03}) {
+		t.Errorf("PDU: got % X", pdu)
+	}
+
+	// Lower case hex digits are accepted
+	if _, _, err := DecodeASCIIFrame([]byte(":1103006b00037e\r\n"), DefaultASCIIDelimiter); err != nil {
+		t.Errorf("Lower case frame rejected: %v", err)
+	}
+}
+
+func TestDecodeASCIIFrame_BadLRC(t *testing.T) {
+	_, _, err := DecodeASCIIFrame([]byte(":1103006B00037F\r\n"), DefaultASCIIDelimiter)
+	if !errors.Is(err, ErrInvalidLRC) {
+		t.Errorf("Expected ErrInvalidLRC, got %v", err)
+	}
+}
+
+func TestDecodeASCIIFrame_Malformed(t *testing.T) {
+	frames := []string{
+		":1103\r\n",           // too short
+		"1103006B00037E\r\n",  // missing start
+		":1103006B00037E\n",   // missing CR
+		":1103006B00037E\r$",  // wrong delimiter
+		":1103006B00037\r\n",  // odd length
+		":1103006B0003ZZ\r\n", // invalid hex
+	}
+
+	for _, frame := range frames {
+		_, _, err := DecodeASCIIFrame([]byte(frame), DefaultASCIIDelimiter)
+		if !errors.Is(err, ErrInvalidFrame) {
+			t.Errorf("%q: expected ErrInvalidFrame, got %v", frame, err)
+		}
+	}
+}
+
+func TestNewASCIIClient(t *testing.T) {
+	if _, err := NewASCIIClient(""); err == nil {
+		t.Error("Expected error for empty device")
+	}
+
+	client, err := NewASCIIClient("/dev/ttyS0", WithASCIIDelimiter('$'))
+	if err != nil {
+		t.Fatalf("NewASCIIClient failed: %v", err)
+	}
+	defer client.Close()
+
+	if client.opts.dataBits != 7 {
+		t.Errorf("DataBits: expected 7, got %d", client.opts.dataBits)
+	}
+	at, ok := client.transport.(*asciiTransport)
+	if !ok {
+		t.Fatalf("Expected ASCII transport, got %T", client.transport)
+	}
+	if at.Delimiter() != '$' {
+		t.Errorf("Delimiter: expected '$', got %q", at.Delimiter())
+	}
+}
+
+// serveASCII answers ASCII requests read from rw (a pty or a TCP
+// connection) using the server's request processing. Like a real device, it switches
+// to the new input delimiter after acknowledging a delimiter change.
+func serveASCII(rw io.ReadWriter, server *Server, transform func([]byte) []byte) {
+	delimiter := byte(DefaultASCIIDelimiter)
+	buf := make([]byte, 0, ASCIIMaxFrameSize)
+	chunk := make([]byte, ASCIIMaxFrameSize)
+
+	for {
+		n, err := rw.Read(chunk)
+		if err != nil {
+			return
+		}
+		buf = append(buf, chunk[:n]...)
+
+		if len(buf) < 2 || buf[len(buf)-2] != '\r' || buf[len(buf)-1] != delimiter {
+			if len(buf) >= ASCIIMaxFrameSize {
+				buf = buf[:0]
+			}
+			continue
+		}
+
+		unitID, pdu, err := DecodeASCIIFrame(buf, delimiter)
+		buf = buf[:0]
+		if err != nil {
+			continue
+		}
+
+		var respPDU []byte
+		next := delimiter
+		if isChangeDelimiterRequest(pdu) {
+			respPDU = pdu
+			next = pdu[3]
+		} else {
+			resp := server.processRequest(context.Background(), &Frame{
+				Header: MBAPHeader{UnitID: unitID},
+				PDU:    pdu,
+			}, nil)
+			respPDU = resp.PDU
+		}
+
+		frame := EncodeASCIIFrame(unitID, respPDU, delimiter)
+		if transform != nil {
+			frame = transform(frame)
+		}
+		rw.Write(frame)
+		delimiter = next
+	}
+}
+
+func TestASCIIOverTCPClient(t *testing.T) {
+	handler := NewMemoryHandler(65536, 65536)
+	handler.SetHoldingRegister(1, 0, 1234)
+	server := NewServer(handler)
+
+	addr := listenStream(t, func(conn net.Conn) {
+		serveASCII(conn, server, nil)
+	})
+
+	client, err := NewClient(addr, WithUnitID(1), WithFraming(FramingASCII), WithTimeout(2*time.Second))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	regs, err := client.ReadHoldingRegisters(ctx, 0, 1)
+	if err != nil {
+		t.Fatalf("ReadHoldingRegisters failed: %v", err)
+	}
+	if regs[0] != 1234 {
+		t.Errorf("Expected 1234, got %d", regs[0])
+	}
+}
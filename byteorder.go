@@ -0,0 +1,248 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modbus
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"strings"
+)
+
+// ByteOrder is the layout of a value held by several registers. Order names
+// list the bytes of the value, A being the most significant, in the order
+// they are transmitted: ABCD is big-endian, CDAB swaps the registers, BADC
+// swaps the bytes of each register and DCBA is little-endian. 64-bit values
+// have eight layouts, ABCDEFGH to FEHGBADC; the four 32-bit orders lay out
+// 64-bit values as ABCDEFGH, GHEFCDAB, BADCFEHG and HGFEDCBA.
+//
+// Values of other sizes are laid out with the byte swap of the order, and
+// with their registers reversed by CDAB and DCBA.
+type ByteOrder uint8
+
+// Building blocks of the byte orders.
+const (
+	orderSwapBytes  ByteOrder = 1 << iota // bytes of each register
+	orderSwapWords                        // registers of each 32-bit half
+	orderSwapDwords                       // 32-bit halves of a 64-bit value
+)
+
+// Byte orders of 32-bit and 64-bit values.
+const (
+	OrderABCD = ByteOrder(0)
+	OrderBADC = orderSwapBytes
+	OrderCDAB = orderSwapWords | orderSwapDwords
+	OrderDCBA = orderSwapBytes | orderSwapWords | orderSwapDwords
+
+	OrderABCDEFGH = OrderABCD
+	OrderBADCFEHG = OrderBADC
+	OrderGHEFCDAB = OrderCDAB
+	OrderHGFEDCBA = OrderDCBA
+	OrderCDABGHEF = orderSwapWords
+	OrderDCBAHGFE = orderSwapBytes | orderSwapWords
+	OrderEFGHABCD = orderSwapDwords
+	OrderFEHGBADC = orderSwapBytes | orderSwapDwords
+)
+
+// Byte orders of the two bytes of a register, for devices documented with
+// separate byte and word orders.
+const (
+	// BigEndian sends the most significant byte of a register first, as
+	// the protocol does.
+	BigEndian = OrderABCD
+	// LittleEndian swaps the bytes of each register.
+	LittleEndian = OrderBADC
+)
+
+// WordOrder is the order of the registers of a multi-register value, for
+// devices documented with separate byte and word orders. It combines with a
+// ByteOrder, see ByteOrder.WithWordOrder.
+type WordOrder int
+
+const (
+	// HighWordFirst stores the most significant register at the lowest address.
+	HighWordFirst WordOrder = iota
+	// LowWordFirst stores the least significant register at the lowest address.
+	LowWordFirst
+)
+
+// WithWordOrder returns the byte order combined with a word order:
+// LowWordFirst adds the register swap of CDAB, so that BigEndian becomes
+// OrderCDAB and LittleEndian OrderDCBA. Orders that already swap registers
+// keep their swap: OrderCDAB stays OrderCDAB, it is not swapped back.
+func (o ByteOrder) WithWordOrder(w WordOrder) ByteOrder {
+	if w == LowWordFirst {
+		return o | orderSwapWords | orderSwapDwords
+	}
+	return o
+}
+
+// byteOrderNames holds the names of the byte orders, 64-bit names last.
+var byteOrderNames = []struct {
+	name  string
+	order ByteOrder
+}{
+	{"ABCD", OrderABCD},
+	{"BADC", OrderBADC},
+	{"CDAB", OrderCDAB},
+	{"DCBA", OrderDCBA},
+	{"CDABGHEF", OrderCDABGHEF},
+	{"DCBAHGFE", OrderDCBAHGFE},
+	{"EFGHABCD", OrderEFGHABCD},
+	{"FEHGBADC", OrderFEHGBADC},
+	{"ABCDEFGH", OrderABCDEFGH},
+	{"BADCFEHG", OrderBADCFEHG},
+	{"GHEFCDAB", OrderGHEFCDAB},
+	{"HGFEDCBA", OrderHGFEDCBA},
+}
+
+// String returns the name of the byte order, using the 32-bit name of the
+// orders that have one.
+func (o ByteOrder) String() string {
+	for _, n := range byteOrderNames {
+		if n.order == o {
+			return n.name
+		}
+	}
+	return fmt.Sprintf("ByteOrder(%d)", uint8(o))
+}
+
+// ParseByteOrder parses the case-insensitive name of a 32-bit or 64-bit
+// byte order, or "big" and "little" for ABCD and DCBA.
+func ParseByteOrder(s string) (ByteOrder, error) {
+	name := strings.ToUpper(strings.TrimSpace(s))
+	switch name {
+	case "BIG":
+		return OrderABCD, nil
+	case "LITTLE":
+		return OrderDCBA, nil
+	}
+	for _, n := range byteOrderNames {
+		if n.name == name {
+			return n.order, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: unknown byte order %q", ErrInvalidValue, s)
+}
+
+// index returns the position in the registers of a value of n registers
+// of its register i, counted from the most significant.
+func (o ByteOrder) index(i, n int) int {
+	switch {
+	case o&orderSwapWords != 0 && o&orderSwapDwords != 0:
+		return n - 1 - i
+	case o&orderSwapWords != 0 && i^1 < n:
+		return i ^ 1
+	case o&orderSwapDwords != 0 && n == 4:
+		return i ^ 2
+	}
+	return i
+}
+
+// register returns register i of a value of n registers.
+func (o ByteOrder) register(regs []uint16, i, n int) uint16 {
+	r := regs[o.index(i, n)]
+	if o&orderSwapBytes != 0 {
+		r = bits.ReverseBytes16(r)
+	}
+	return r
+}
+
+// setRegister sets register i of a value of n registers.
+func (o ByteOrder) setRegister(regs []uint16, i, n int, r uint16) {
+	if o&orderSwapBytes != 0 {
+		r = bits.ReverseBytes16(r)
+	}
+	regs[o.index(i, n)] = r
+}
+
+// get returns the value of the first n registers of regs.
+func (o ByteOrder) get(regs []uint16, n int) uint64 {
+	_ = regs[n-1] // bounds check
+	var v uint64
+	for i := 0; i < n; i++ {
+		v = v<<16 | uint64(o.register(regs, i, n))
+	}
+	return v
+}
+
+// put stores v in the first n registers of regs.
+func (o ByteOrder) put(regs []uint16, n int, v uint64) {
+	_ = regs[n-1] // bounds check
+	for i := n - 1; i >= 0; i-- {
+		o.setRegister(regs, i, n, uint16(v))
+		v >>= 16
+	}
+}
+
+// Uint16 returns the value of the first register of regs.
+func (o ByteOrder) Uint16(regs []uint16) uint16 { return uint16(o.get(regs, 1)) }
+
+// Uint32 returns the value of the first two registers of regs.
+func (o ByteOrder) Uint32(regs []uint16) uint32 { return uint32(o.get(regs, 2)) }
+
+// Uint64 returns the value of the first four registers of regs.
+func (o ByteOrder) Uint64(regs []uint16) uint64 { return o.get(regs, 4) }
+
+// Float32 returns the float32 held by the first two registers of regs.
+func (o ByteOrder) Float32(regs []uint16) float32 {
+	return math.Float32frombits(o.Uint32(regs))
+}
+
+// Float64 returns the float64 held by the first four registers of regs.
+func (o ByteOrder) Float64(regs []uint16) float64 {
+	return math.Float64frombits(o.Uint64(regs))
+}
+
+// PutUint16 stores v in the first register of regs.
+func (o ByteOrder) PutUint16(regs []uint16, v uint16) { o.put(regs, 1, uint64(v)) }
+
+// PutUint32 stores v in the first two registers of regs.
+func (o ByteOrder) PutUint32(regs []uint16, v uint32) { o.put(regs, 2, uint64(v)) }
+
+// PutUint64 stores v in the first four registers of regs.
+func (o ByteOrder) PutUint64(regs []uint16, v uint64) { o.put(regs, 4, v) }
+
+// PutFloat32 stores f in the first two registers of regs.
+func (o ByteOrder) PutFloat32(regs []uint16, f float32) {
+	o.PutUint32(regs, math.Float32bits(f))
+}
+
+// PutFloat64 stores f in the first four registers of regs.
+func (o ByteOrder) PutFloat64(regs []uint16, f float64) {
+	o.PutUint64(regs, math.Float64bits(f))
+}
+
+// Bytes returns the bytes of the value held by regs, most significant
+// first.
+func (o ByteOrder) Bytes(regs []uint16) []byte {
+	b := make([]byte, 2*len(regs))
+	for i := range regs {
+		r := o.register(regs, i, len(regs))
+		b[2*i] = byte(r >> 8)
+		b[2*i+1] = byte(r)
+	}
+	return b
+}
+
+// Registers returns the registers holding the value of b, given most
+// significant byte first. An odd trailing byte is ignored.
+func (o ByteOrder) Registers(b []byte) []uint16 {
+	regs := make([]uint16, len(b)/2)
+	for i := range regs {
+		o.setRegister(regs, i, len(regs), uint16(b[2*i])<<8|uint16(b[2*i+1]))
+	}
+	return regs
+}
@@ -0,0 +1,200 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modbus
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// orderRegisters returns the registers holding the bytes of value, most
+// significant first, laid out as named by an order such as "CDAB".
+func orderRegisters(name string, value []byte) []uint16 {
+	regs := make([]uint16, len(name)/2)
+	for i := range regs {
+		regs[i] = uint16(value[name[2*i]-'A'])<<8 | uint16(value[name[2*i+1]-'A'])
+	}
+	return regs
+}
+
+func TestByteOrder32(t *testing.T) {
+	value := []byte{0x12, 0x34, 0x56, 0x78}
+	for _, name := range []string{"ABCD", "BADC", "CDAB", "DCBA"} {
+		t.Run(name, func(t *testing.T) {
+			order, err := ParseByteOrder(name)
+			if err != nil {
+				t.Fatalf("ParseByteOrder failed: %v", err)
+			}
+			if order.String() != name {
+				t.Errorf("Expected %s, got %s", name, order)
+			}
+
+			want := orderRegisters(name, value)
+			regs := make([]uint16, 2)
+			order.PutUint32(regs, 0x12345678)
+			if fmt.Sprint(regs) != fmt.Sprint(want) {
+				t.Errorf("PutUint32: expected %04X, got %04X", want, regs)
+			}
+			if v := order.Uint32(want); v != 0x12345678 {
+				t.Errorf("Uint32: expected 12345678, got %08X", v)
+			}
+
+			order.PutFloat32(regs, -1.5)
+			if v := order.Float32(regs); v != -1.5 {
+				t.Errorf("Float32: expected -1.5, got %v", v)
+			}
+		})
+	}
+}
+
+func TestByteOrder64(t *testing.T) {
+	value := []byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xAB, 0xCD, 0xEF}
+	names := []string{
+		"ABCDEFGH", "BADCFEHG", "CDABGHEF", "DCBAHGFE",
+		"EFGHABCD", "FEHGBADC", "GHEFCDAB", "HGFEDCBA",
+	}
+	seen := make(map[ByteOrder]bool)
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			order, err := ParseByteOrder(name)
+			if err != nil {
+				t.Fatalf("ParseByteOrder failed: %v", err)
+			}
+			if seen[order] {
+				t.Fatalf("%s parsed to duplicate order %s", name, order)
+			}
+			seen[order] = true
+
+			want := orderRegisters(name, value)
+			regs := make([]uint16, 4)
+			order.PutUint64(regs, 0x0123456789ABCDEF)
+			if fmt.Sprint(regs) != fmt.Sprint(want) {
+				t.Errorf("PutUint64: expected %04X, got %04X", want, regs)
+			}
+			if v := order.Uint64(want); v != 0x0123456789ABCDEF {
+				t.Errorf("Uint64: expected 0123456789ABCDEF, got %016X", v)
+			}
+			if b := order.Bytes(want); fmt.Sprint(b) != fmt.Sprint(value) {
+				t.Errorf("Bytes: expected % X, got % X", value, b)
+			}
+			if r := order.Registers(value); fmt.Sprint(r) != fmt.Sprint(want) {
+				t.Errorf("Registers: expected %04X, got %04X", want, r)
+			}
+
+			order.PutFloat64(regs, 3.141592653589793)
+			if v := order.Float64(regs); v != 3.141592653589793 {
+				t.Errorf("Float64: expected pi, got %v", v)
+			}
+		})
+	}
+}
+
+func TestByteOrderOtherSizes(t *testing.T) {
+	regs := []uint16{0x0102, 0x0304, 0x0506}
+	tests := []struct {
+		order ByteOrder
+		bytes string
+	}{
+		{OrderABCD, "[1 2 3 4 5 6]"},
+		{OrderBADC, "[2 1 4 3 6 5]"},
+		{OrderCDAB, "[5 6 3 4 1 2]"},
+		{OrderDCBA, "[6 5 4 3 2 1]"},
+	}
+	for _, tt := range tests {
+		b := tt.order.Bytes(regs)
+		if fmt.Sprint(b) != tt.bytes {
+			t.Errorf("%s: expected %s, got %v", tt.order, tt.bytes, b)
+		}
+		if r := tt.order.Registers(b); fmt.Sprint(r) != fmt.Sprint(regs) {
+			t.Errorf("%s: Registers expected %04X, got %04X", tt.order, regs, r)
+		}
+	}
+
+	if v := OrderBADC.Uint16([]uint16{0x1234}); v != 0x3412 {
+		t.Errorf("Uint16: expected 3412, got %04X", v)
+	}
+	if v := OrderCDAB.Uint16([]uint16{0x1234}); v != 0x1234 {
+		t.Errorf("Uint16: expected 1234, got %04X", v)
+	}
+}
+
+func TestWordOrder(t *testing.T) {
+	tests := []struct {
+		byteOrder ByteOrder
+		wordOrder WordOrder
+		expected  ByteOrder
+	}{
+		{BigEndian, HighWordFirst, OrderABCD},
+		{BigEndian, LowWordFirst, OrderCDAB},
+		{LittleEndian, HighWordFirst, OrderBADC},
+		{LittleEndian, LowWordFirst, OrderDCBA},
+	}
+	for _, tt := range tests {
+		if o := tt.byteOrder.WithWordOrder(tt.wordOrder); o != tt.expected {
+			t.Errorf("%s with word order %d: expected %s, got %s", tt.byteOrder, tt.wordOrder, tt.expected, o)
+		}
+
+		tag := &Tag{Table: TableHoldingRegisters, Type: TypeUint32, ByteOrder: tt.byteOrder, WordOrder: tt.wordOrder}
+		regs, err := tag.Encode(uint32(0x11223344))
+		if err != nil {
+			t.Fatalf("Encode failed: %v", err)
+		}
+		if v := tt.expected.Uint32(regs); v != 0x11223344 {
+			t.Errorf("%s: expected 11223344, got %08X", tt.expected, v)
+		}
+	}
+
+	// Strings only follow the byte swap
+	name := &Tag{Table: TableHoldingRegisters, Type: TypeString, Length: 2, WordOrder: LowWordFirst}
+	if value, err := name.Decode([]uint16{0x4142, 0x4344}); err != nil || value != "ABCD" {
+		t.Errorf("Expected ABCD, got %v (%v)", value, err)
+	}
+}
+
+func TestParseByteOrder(t *testing.T) {
+	tests := []struct {
+		s     string
+		order ByteOrder
+	}{
+		{"abcd", OrderABCD},
+		{"cdab", OrderCDAB},
+		{"GHEFCDAB", OrderCDAB},
+		{"big", OrderABCD},
+		{"little", OrderDCBA},
+	}
+	for _, tt := range tests {
+		order, err := ParseByteOrder(tt.s)
+		if err != nil || order != tt.order {
+			t.Errorf("ParseByteOrder(%q): expected %s, got %s (%v)", tt.s, tt.order, order, err)
+		}
+	}
+
+	if _, err := ParseByteOrder("ACBD"); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Expected ErrInvalidValue, got %v", err)
+	}
+}
+
+func TestRegisterHelpers(t *testing.T) {
+	if regs := Float32ToRegisters(1.5); regs != [2]uint16{0x3FC0, 0x0000} {
+		t.Errorf("Float32ToRegisters: got %04X", regs)
+	}
+	if v := RegistersToInt32(Int32ToRegisters(-123456)); v != -123456 {
+		t.Errorf("Int32 round trip: got %d", v)
+	}
+	if v := RegistersToUint32([2]uint16{0x1234, 0x5678}); v != 0x12345678 {
+		t.Errorf("RegistersToUint32: got %08X", v)
+	}
+}
@@ -27,14 +27,14 @@ import (
 	"github.com/edgeo-scada/modbus/internal/transport"
 )
 
-// Client is a Modbus TCP client with support for automatic reconnection.
+// Client is a Modbus client with support for automatic reconnection.
+// It speaks Modbus TCP, UDP, RTU or ASCII depending on how it was created.
 type Client struct {
 	addr   string
 	unitID UnitID
 	opts   *clientOptions
 
-	transport *transport.TCPTransport
-	txIDGen   TransactionIDGenerator
+	transport Transporter
 
 	mu      sync.Mutex
 	state   ConnectionState
@@ -44,7 +44,10 @@ type Client struct {
 	logger  *slog.Logger
 }
 
-// NewClient creates a new Modbus TCP client.
+// NewClient creates a new Modbus TCP client. By default requests are framed
+// with an MBAP header; use WithFraming to send RTU or ASCII frames over the
+// TCP connection instead, WithTLSConfig for Modbus/TCP Security, or
+// WithTransport to supply a custom transport.
 func NewClient(addr string, opts ...Option) (*Client, error) {
 	if addr == "" {
 		return nil, errors.New("modbus: address cannot be empty")
@@ -55,18 +58,125 @@ func NewClient(addr string, opts ...Option) (*Client, error) {
 		opt(options)
 	}
 
-	c := &Client{
+	conn := transport.NewTCPTransport(addr, options.timeout)
+	if options.tlsConfig != nil {
+		conn = transport.NewTLSTransport(addr, options.timeout, options.tlsConfig)
+	}
+
+	var t Transporter
+	switch {
+	case options.transport != nil:
+		t = options.transport
+	case options.framing == FramingTCP && options.maxInFlight > 0:
+		t = &pipelinedTransport{conn: transport.NewPipeline(conn, options.maxInFlight)}
+	case options.framing == FramingTCP:
+		t = &tcpTransport{conn: conn}
+	case options.framing == FramingRTU:
+		t = &rtuTransport{stream: conn}
+	case options.framing == FramingASCII:
+		t = &asciiTransport{stream: conn, delimiter: options.asciiDelimiter}
+	default:
+		return nil, fmt.Errorf("modbus: unknown framing %d", options.framing)
+	}
+
+	return newClient(addr, t, options), nil
+}
+
+// NewUDPClient creates a new Modbus client that sends MBAP frames over UDP.
+// Unanswered requests are retransmitted, see WithUDPRetransmits.
+func NewUDPClient(addr string, opts ...Option) (*Client, error) {
+	if addr == "" {
+		return nil, errors.New("modbus: address cannot be empty")
+	}
+
+	options := defaultOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return newClient(addr, newUDPTransport(addr, options.timeout, options.udpRetransmits), options), nil
+}
+
+// NewRTUClient creates a new Modbus RTU client on a serial device such as
+// /dev/ttyUSB0. Line settings are configured with WithBaudRate, WithDataBits,
+// WithParity and WithStopBits and default to 19200 8E1.
+func NewRTUClient(device string, opts ...Option) (*Client, error) {
+	if device == "" {
+		return nil, errors.New("modbus: serial device cannot be empty")
+	}
+
+	options := defaultOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	serial := transport.NewSerialTransport(transport.SerialConfig{
+		Device:     device,
+		BaudRate:   options.baudRate,
+		DataBits:   options.dataBits,
+		Parity:     byte(options.parity),
+		StopBits:   options.stopBits,
+		FrameDelay: rtuFrameDelay(options.baudRate),
+	}, options.timeout)
+
+	return newClient(device, &rtuTransport{stream: serial}, options), nil
+}
+
+// NewASCIIClient creates a new Modbus ASCII client on a serial device.
+// The line defaults to 7 data bits, even parity and 1 stop bit as
+// recommended by the specification.
+func NewASCIIClient(device string, opts ...Option) (*Client, error) {
+	if device == "" {
+		return nil, errors.New("modbus: serial device cannot be empty")
+	}
+
+	options := defaultOptions()
+	options.dataBits = 7
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	serial := transport.NewSerialTransport(transport.SerialConfig{
+		Device:   device,
+		BaudRate: options.baudRate,
+		DataBits: options.dataBits,
+		Parity:   byte(options.parity),
+		StopBits: options.stopBits,
+	}, options.timeout)
+
+	return newClient(device, &asciiTransport{
+		stream:    serial,
+		delimiter: options.asciiDelimiter,
+	}, options), nil
+}
+
+// NewClientWithTransport creates a client that sends requests through t,
+// such as a custom or in-memory transport. If t implements Connector, it is
+// called by Connect.
+func NewClientWithTransport(t Transporter, opts ...Option) (*Client, error) {
+	if t == nil {
+		return nil, errors.New("modbus: transport cannot be nil")
+	}
+
+	options := defaultOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return newClient("", t, options), nil
+}
+
+func newClient(addr string, t Transporter, options *clientOptions) *Client {
+	return &Client{
 		addr:      addr,
 		unitID:    options.unitID,
 		opts:      options,
-		transport: transport.NewTCPTransport(addr, options.timeout),
+		transport: t,
 		state:     StateDisconnected,
 		closeCh:   make(chan struct{}),
 		metrics:   NewMetrics(),
 		logger:    options.logger,
 	}
-
-	return c, nil
 }
 
 // Connect establishes a connection to the Modbus server.
@@ -85,11 +195,13 @@ func (c *Client) Connect(ctx context.Context) error {
 
 	c.logger.Debug("connecting", slog.String("addr", c.addr))
 
-	if err := c.transport.Connect(ctx); err != nil {
-		c.mu.Lock()
-		c.state = StateDisconnected
-		c.mu.Unlock()
-		return err
+	if conn, ok := c.transport.(Connector); ok {
+		if err := conn.Connect(ctx); err != nil {
+			c.mu.Lock()
+			c.state = StateDisconnected
+			c.mu.Unlock()
+			return err
+		}
 	}
 
 	c.mu.Lock()
@@ -157,7 +269,7 @@ func (c *Client) UnitID() UnitID {
 	return c.unitID
 }
 
-// Address returns the server address.
+// Address returns the server address or serial device.
 func (c *Client) Address() string {
 	return c.addr
 }
@@ -220,63 +332,30 @@ func (c *Client) doSend(ctx context.Context, unitID UnitID, pdu []byte) ([]byte,
 	start := time.Now()
 	c.metrics.RequestsTotal.Add(1)
 
-	// Build frame
-	txID := c.txIDGen.Next()
-	frame := Frame{
-		Header: MBAPHeader{
-			TransactionID: txID,
-			ProtocolID:    ProtocolID,
-			UnitID:        unitID,
-		},
-		PDU: pdu,
-	}
-
 	expectedFC := FunctionCode(pdu[0])
 
 	c.logger.Debug("sending request",
-		slog.Uint64("tx_id", uint64(txID)),
 		slog.Uint64("unit_id", uint64(unitID)),
 		slog.String("func", expectedFC.String()))
 
 	// Send and receive
-	respData, err := c.transport.Send(ctx, frame.Encode())
+	respPDU, err := c.transport.Send(ctx, unitID, pdu)
 	if err != nil {
 		c.metrics.RequestsErrors.Add(1)
 		return nil, err
 	}
 
-	// Parse response frame
-	var respFrame Frame
-	if err := respFrame.Decode(respData); err != nil {
-		c.metrics.RequestsErrors.Add(1)
-		return nil, err
-	}
-
-	// Validate transaction ID
-	if respFrame.Header.TransactionID != txID {
-		c.metrics.RequestsErrors.Add(1)
-		return nil, fmt.Errorf("%w: transaction ID mismatch (expected %d, got %d)",
-			ErrInvalidResponse, txID, respFrame.Header.TransactionID)
-	}
-
-	// Validate unit ID
-	if respFrame.Header.UnitID != unitID {
-		c.metrics.RequestsErrors.Add(1)
-		return nil, fmt.Errorf("%w: unit ID mismatch (expected %d, got %d)",
-			ErrInvalidResponse, unitID, respFrame.Header.UnitID)
-	}
-
 	// Check for exception response
-	if IsExceptionResponse(respFrame.PDU) {
+	if IsExceptionResponse(respPDU) {
 		c.metrics.RequestsErrors.Add(1)
-		return nil, ParseExceptionResponse(respFrame.PDU)
+		return nil, ParseExceptionResponse(respPDU)
 	}
 
 	// Validate function code
-	if len(respFrame.PDU) > 0 && FunctionCode(respFrame.PDU[0]) != expectedFC {
+	if len(respPDU) > 0 && FunctionCode(respPDU[0]) != expectedFC {
 		c.metrics.RequestsErrors.Add(1)
 		return nil, fmt.Errorf("%w: function code mismatch (expected %02X, got %02X)",
-			ErrInvalidResponse, expectedFC, respFrame.PDU[0])
+			ErrInvalidResponse, expectedFC, respPDU[0])
 	}
 
 	duration := time.Since(start)
@@ -284,10 +363,10 @@ func (c *Client) doSend(ctx context.Context, unitID UnitID, pdu []byte) ([]byte,
 	c.metrics.Latency.Observe(duration)
 
 	c.logger.Debug("received response",
-		slog.Uint64("tx_id", uint64(txID)),
+		slog.String("func", expectedFC.String()),
 		slog.Duration("duration", duration))
 
-	return respFrame.PDU, nil
+	return respPDU, nil
 }
 
 func (c *Client) handleDisconnect(err error) {
@@ -476,6 +555,84 @@ func (c *Client) WriteMultipleRegisters(ctx context.Context, addr uint16, values
 	return ParseWriteMultipleResponse(resp, addr, uint16(len(values)))
 }
 
+// ReadFileRecord reads groups of registers from file records (FC20).
+// It returns one record per sub-request, in request order.
+func (c *Client) ReadFileRecord(ctx context.Context, reqs []FileSubRequest) ([]FileRecord, error) {
+	pdu, err := BuildReadFileRecordPDU(reqs)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.send(ctx, pdu)
+	if err != nil {
+		return nil, err
+	}
+	return ParseReadFileRecordResponse(resp, reqs)
+}
+
+// WriteFileRecord writes groups of registers to file records (FC21).
+func (c *Client) WriteFileRecord(ctx context.Context, records []FileRecord) error {
+	pdu, err := BuildWriteFileRecordPDU(records)
+	if err != nil {
+		return err
+	}
+	resp, err := c.send(ctx, pdu)
+	if err != nil {
+		return err
+	}
+	return ParseWriteFileRecordResponse(resp, records)
+}
+
+// MaskWriteRegister modifies bits of a holding register (FC22). The server
+// sets the register to (current AND andMask) OR (orMask AND NOT andMask).
+func (c *Client) MaskWriteRegister(ctx context.Context, addr, andMask, orMask uint16) error {
+	pdu := BuildMaskWriteRegisterPDU(addr, andMask, orMask)
+	resp, err := c.send(ctx, pdu)
+	if err != nil {
+		return err
+	}
+	return ParseMaskWriteRegisterResponse(resp, addr, andMask, orMask)
+}
+
+// ReadWriteMultipleRegisters writes values starting at writeAddr and then
+// reads readQty registers starting at readAddr in a single transaction (FC23).
+func (c *Client) ReadWriteMultipleRegisters(ctx context.Context, readAddr, readQty, writeAddr uint16, values []uint16) ([]uint16, error) {
+	if len(values) == 0 {
+		return nil, ErrInvalidQuantity
+	}
+	pdu, err := BuildReadWriteMultipleRegistersPDU(readAddr, readQty, writeAddr, values)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.send(ctx, pdu)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRegistersResponse(resp, readQty)
+}
+
+// ReadFIFOQueue reads the contents of the FIFO queue at addr (FC24).
+// The server returns up to 31 queued registers without removing them.
+func (c *Client) ReadFIFOQueue(ctx context.Context, addr uint16) ([]uint16, error) {
+	pdu := BuildReadFIFOQueuePDU(addr)
+	resp, err := c.send(ctx, pdu)
+	if err != nil {
+		return nil, err
+	}
+	return ParseReadFIFOQueueResponse(resp)
+}
+
+// SendPDU sends a raw request PDU, function code included, to unitID and
+// returns the response PDU. It can be used for user-defined function codes.
+// Exception responses are returned as a *ModbusError.
+func (c *Client) SendPDU(ctx context.Context, unitID UnitID, pdu []byte) ([]byte, error) {
+	return c.sendWithUnit(ctx, unitID, pdu)
+}
+
+// Do encodes req, sends it and decodes the response into resp.
+func (c *Client) Do(ctx context.Context, req Request, resp Response) error {
+	return c.DoWithUnit(ctx, c.UnitID(), req, resp)
+}
+
 // ReadExceptionStatus reads the exception status (FC07).
 func (c *Client) ReadExceptionStatus(ctx context.Context) (uint8, error) {
 	pdu := BuildReadExceptionStatusPDU()
@@ -497,6 +654,14 @@ func (c *Client) Diagnostics(ctx context.Context, subFunc uint16, data []byte) (
 	return respData, err
 }
 
+// ChangeASCIIInputDelimiter changes the end-of-frame character expected by
+// the server (FC08, sub-function 03). ASCII clients switch to the new
+// delimiter once the server has acknowledged the change.
+func (c *Client) ChangeASCIIInputDelimiter(ctx context.Context, delimiter byte) error {
+	_, err := c.Diagnostics(ctx, DiagChangeASCIIInputDelimiter, []byte{delimiter, 0x00})
+	return err
+}
+
 // GetCommEventCounter gets the communication event counter (FC11).
 func (c *Client) GetCommEventCounter(ctx context.Context) (status, eventCount uint16, err error) {
 	pdu := BuildGetCommEventCounterPDU()
@@ -507,6 +672,16 @@ func (c *Client) GetCommEventCounter(ctx context.Context) (status, eventCount ui
 	return ParseGetCommEventCounterResponse(resp)
 }
 
+// GetCommEventLog gets the communication event log (FC12).
+func (c *Client) GetCommEventLog(ctx context.Context) (*CommEventLog, error) {
+	pdu := BuildGetCommEventLogPDU()
+	resp, err := c.send(ctx, pdu)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetCommEventLogResponse(resp)
+}
+
 // ReportServerID requests the server ID (FC17).
 func (c *Client) ReportServerID(ctx context.Context) ([]byte, error) {
 	pdu := BuildReportServerIDPDU()
@@ -517,6 +692,15 @@ func (c *Client) ReportServerID(ctx context.Context) ([]byte, error) {
 	return ParseReportServerIDResponse(resp)
 }
 
+// ReadDeviceIdentification reads device identification objects (FC43/14).
+// For stream access (ReadDeviceIDBasic, ReadDeviceIDRegular and
+// ReadDeviceIDExtended) it starts at objectID and follows the "more follows"
+// pages until the server has sent every object of the category. For
+// ReadDeviceIDIndividual it reads the single object objectID.
+func (c *Client) ReadDeviceIdentification(ctx context.Context, readCode, objectID uint8) (*DeviceIdentification, error) {
+	return c.ReadDeviceIdentificationWithUnit(ctx, c.UnitID(), readCode, objectID)
+}
+
 // ReadCoilsWithUnit reads coils using a specific unit ID.
 func (c *Client) ReadCoilsWithUnit(ctx context.Context, unitID UnitID, addr, qty uint16) ([]bool, error) {
 	pdu, err := BuildReadCoilsPDU(addr, qty)
@@ -624,3 +808,131 @@ func (c *Client) WriteMultipleRegistersWithUnit(ctx context.Context, unitID Unit
 	}
 	return ParseWriteMultipleResponse(resp, addr, uint16(len(values)))
 }
+
+// ReadFileRecordWithUnit reads file records using a specific unit ID.
+func (c *Client) ReadFileRecordWithUnit(ctx context.Context, unitID UnitID, reqs []FileSubRequest) ([]FileRecord, error) {
+	pdu, err := BuildReadFileRecordPDU(reqs)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.sendWithUnit(ctx, unitID, pdu)
+	if err != nil {
+		return nil, err
+	}
+	return ParseReadFileRecordResponse(resp, reqs)
+}
+
+// WriteFileRecordWithUnit writes file records using a specific unit ID.
+func (c *Client) WriteFileRecordWithUnit(ctx context.Context, unitID UnitID, records []FileRecord) error {
+	pdu, err := BuildWriteFileRecordPDU(records)
+	if err != nil {
+		return err
+	}
+	resp, err := c.sendWithUnit(ctx, unitID, pdu)
+	if err != nil {
+		return err
+	}
+	return ParseWriteFileRecordResponse(resp, records)
+}
+
+// MaskWriteRegisterWithUnit modifies bits of a holding register using a specific unit ID.
+func (c *Client) MaskWriteRegisterWithUnit(ctx context.Context, unitID UnitID, addr, andMask, orMask uint16) error {
+	pdu := BuildMaskWriteRegisterPDU(addr, andMask, orMask)
+	resp, err := c.sendWithUnit(ctx, unitID, pdu)
+	if err != nil {
+		return err
+	}
+	return ParseMaskWriteRegisterResponse(resp, addr, andMask, orMask)
+}
+
+// ReadWriteMultipleRegistersWithUnit writes and reads multiple registers using a specific unit ID.
+func (c *Client) ReadWriteMultipleRegistersWithUnit(ctx context.Context, unitID UnitID, readAddr, readQty, writeAddr uint16, values []uint16) ([]uint16, error) {
+	if len(values) == 0 {
+		return nil, ErrInvalidQuantity
+	}
+	pdu, err := BuildReadWriteMultipleRegistersPDU(readAddr, readQty, writeAddr, values)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.sendWithUnit(ctx, unitID, pdu)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRegistersResponse(resp, readQty)
+}
+
+// GetCommEventLogWithUnit gets the communication event log using a specific unit ID.
+func (c *Client) GetCommEventLogWithUnit(ctx context.Context, unitID UnitID) (*CommEventLog, error) {
+	pdu := BuildGetCommEventLogPDU()
+	resp, err := c.sendWithUnit(ctx, unitID, pdu)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetCommEventLogResponse(resp)
+}
+
+// ReadFIFOQueueWithUnit reads a FIFO queue using a specific unit ID.
+func (c *Client) ReadFIFOQueueWithUnit(ctx context.Context, unitID UnitID, addr uint16) ([]uint16, error) {
+	pdu := BuildReadFIFOQueuePDU(addr)
+	resp, err := c.sendWithUnit(ctx, unitID, pdu)
+	if err != nil {
+		return nil, err
+	}
+	return ParseReadFIFOQueueResponse(resp)
+}
+
+// DoWithUnit encodes req, sends it using a specific unit ID and decodes the
+// response into resp.
+func (c *Client) DoWithUnit(ctx context.Context, unitID UnitID, req Request, resp Response) error {
+	fc := req.FunctionCode()
+	if resp.FunctionCode() != fc {
+		return fmt.Errorf("modbus: response for function code %02X used with a request for %02X",
+			byte(resp.FunctionCode()), byte(fc))
+	}
+	pdu, err := req.Encode()
+	if err != nil {
+		return err
+	}
+	if len(pdu) == 0 || FunctionCode(pdu[0]) != fc {
+		return fmt.Errorf("modbus: request for function code %02X encoded a different function code", byte(fc))
+	}
+	respPDU, err := c.sendWithUnit(ctx, unitID, pdu)
+	if err != nil {
+		return err
+	}
+	return resp.Decode(respPDU)
+}
+
+// ReadDeviceIdentificationWithUnit reads device identification objects using a specific unit ID.
+func (c *Client) ReadDeviceIdentificationWithUnit(ctx context.Context, unitID UnitID, readCode, objectID uint8) (*DeviceIdentification, error) {
+	info := &DeviceIdentification{Objects: make(map[uint8][]byte)}
+
+	for {
+		resp, err := c.sendWithUnit(ctx, unitID, BuildReadDeviceIdentificationPDU(readCode, objectID))
+		if err != nil {
+			return nil, err
+		}
+		page, err := ParseReadDeviceIdentificationResponse(resp)
+		if err != nil {
+			return nil, err
+		}
+
+		info.ConformityLevel = page.ConformityLevel
+		added := 0
+		for _, obj := range page.Objects {
+			if _, ok := info.Objects[obj.ID]; !ok {
+				added++
+			}
+			info.Objects[obj.ID] = obj.Value
+		}
+
+		if !page.MoreFollows || readCode == ReadDeviceIDIndividual {
+			return info, nil
+		}
+		// Every page must bring new objects, or a faulty server could keep us paging forever
+		if added == 0 {
+			return nil, fmt.Errorf("%w: device identification paging did not advance", ErrInvalidResponse)
+		}
+		objectID = page.NextObjectID
+	}
+}
@@ -15,8 +15,14 @@
 package modbus
 
 import (
+	"bytes"
 	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
 	"net"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -115,6 +121,9 @@ func TestClientIntegration(t *testing.T) {
 	handler.SetCoil(1, 0, true)
 
 	server := NewServer(handler)
+	server.HandleFunc(0x41, func(unitID UnitID, pdu []byte) ([]byte, error) {
+		return append([]byte{0x41}, pdu[1:]...), nil
+	})
 
 	listener, err := net.Listen("tcp", "127.0.0.1:0")
 	if err != nil {
@@ -237,6 +246,74 @@ func TestClientIntegration(t *testing.T) {
 		}
 	})
 
+	// Test MaskWriteRegister
+	t.Run("MaskWriteRegister", func(t *testing.T) {
+		if err := client.WriteSingleRegister(ctx, 20, 0x00F0); err != nil {
+			t.Fatalf("WriteSingleRegister failed: %v", err)
+		}
+		// Clear bit 4 and set bit 0
+		if err := client.MaskWriteRegister(ctx, 20, 0xFFEE, 0x0001); err != nil {
+			t.Fatalf("MaskWriteRegister failed: %v", err)
+		}
+		regs, err := client.ReadHoldingRegisters(ctx, 20, 1)
+		if err != nil {
+			t.Fatalf("ReadHoldingRegisters failed: %v", err)
+		}
+		if regs[0] != 0x00E1 {
+			t.Errorf("Register[20]: expected 0x00E1, got 0x%04X", regs[0])
+		}
+	})
+
+	// Test WriteFileRecord and ReadFileRecord
+	t.Run("FileRecord", func(t *testing.T) {
+		err := client.WriteFileRecord(ctx, []FileRecord{
+			{File: 1, Record: 100, Data: []uint16{10, 11, 12}},
+			{File: 2, Record: 0, Data: []uint16{20}},
+		})
+		if err != nil {
+			t.Fatalf("WriteFileRecord failed: %v", err)
+		}
+
+		records, err := client.ReadFileRecord(ctx, []FileSubRequest{
+			{File: 2, Record: 0, Length: 1},
+			{File: 1, Record: 101, Length: 2},
+		})
+		if err != nil {
+			t.Fatalf("ReadFileRecord failed: %v", err)
+		}
+		if len(records) != 2 || records[0].Data[0] != 20 || records[1].Data[0] != 11 || records[1].Data[1] != 12 {
+			t.Errorf("Unexpected records: %+v", records)
+		}
+	})
+
+	// Test ReadFIFOQueue
+	t.Run("ReadFIFOQueue", func(t *testing.T) {
+		handler.PushFIFOQueue(1, 50, 0x0101)
+		handler.PushFIFOQueue(1, 50, 0x0202)
+
+		values, err := client.ReadFIFOQueue(ctx, 50)
+		if err != nil {
+			t.Fatalf("ReadFIFOQueue failed: %v", err)
+		}
+		if len(values) != 2 || values[0] != 0x0101 || values[1] != 0x0202 {
+			t.Errorf("Unexpected FIFO values: %v", values)
+		}
+	})
+
+	// Test ReadWriteMultipleRegisters
+	t.Run("ReadWriteMultipleRegisters", func(t *testing.T) {
+		regs, err := client.ReadWriteMultipleRegisters(ctx, 299, 3, 300, []uint16{30, 31})
+		if err != nil {
+			t.Fatalf("ReadWriteMultipleRegisters failed: %v", err)
+		}
+		expected := []uint16{0, 30, 31}
+		for i, v := range expected {
+			if regs[i] != v {
+				t.Errorf("Register[%d]: expected %d, got %d", 299+i, v, regs[i])
+			}
+		}
+	})
+
 	// Test Diagnostics
 	t.Run("Diagnostics", func(t *testing.T) {
 		data := []byte{0x12, 0x34}
@@ -249,6 +326,49 @@ func TestClientIntegration(t *testing.T) {
 		}
 	})
 
+	// Test GetCommEventLog
+	t.Run("GetCommEventLog", func(t *testing.T) {
+		log, err := client.GetCommEventLog(ctx)
+		if err != nil {
+			t.Fatalf("GetCommEventLog failed: %v", err)
+		}
+		if log.MessageCount == 0 || len(log.Events) == 0 || log.Events[0] != EventReceive {
+			t.Errorf("Expected logged messages ending with a receive event, got %+v", log)
+		}
+	})
+
+	// Test SendPDU
+	t.Run("SendPDU", func(t *testing.T) {
+		resp, err := client.SendPDU(ctx, 1, []byte{0x41, 0x01, 0x02})
+		if err != nil {
+			t.Fatalf("SendPDU failed: %v", err)
+		}
+		if !bytes.Equal(resp, []byte{0x41, 0x01, 0x02}) {
+			t.Errorf("Expected echo, got % X", resp)
+		}
+
+		_, err = client.SendPDU(ctx, 1, []byte{0x42})
+		if !IsIllegalFunction(err) {
+			t.Errorf("Expected illegal function, got %v", err)
+		}
+	})
+
+	// Test Do
+	t.Run("Do", func(t *testing.T) {
+		resp := &ReadHoldingRegistersResponse{}
+		if err := client.Do(ctx, &ReadHoldingRegistersRequest{Address: 0, Quantity: 2}, resp); err != nil {
+			t.Fatalf("Do failed: %v", err)
+		}
+		if len(resp.Values) != 2 || resp.Values[0] != 1234 {
+			t.Errorf("Unexpected values %v", resp.Values)
+		}
+
+		err := client.Do(ctx, &ReadCoilsRequest{Address: 0, Quantity: 1}, resp)
+		if err == nil {
+			t.Error("Expected error for mismatched response type")
+		}
+	})
+
 	// Test ReportServerID
 	t.Run("ReportServerID", func(t *testing.T) {
 		id, err := client.ReportServerID(ctx)
@@ -259,4 +379,355 @@ func TestClientIntegration(t *testing.T) {
 			t.Error("Server ID should not be empty")
 		}
 	})
+
+	// Test ReadDeviceIdentification
+	t.Run("ReadDeviceIdentification", func(t *testing.T) {
+		// Large extended objects force the server to split the response into pages
+		for id := uint8(0x80); id < 0x84; id++ {
+			handler.SetDeviceIdentification(id, bytes.Repeat([]byte{id}, 100))
+		}
+
+		info, err := client.ReadDeviceIdentification(ctx, ReadDeviceIDExtended, 0)
+		if err != nil {
+			t.Fatalf("ReadDeviceIdentification failed: %v", err)
+		}
+		if info.VendorName() != "Edgeo SCADA" {
+			t.Errorf("VendorName: expected Edgeo SCADA, got %q", info.VendorName())
+		}
+		if info.MajorMinorRevision() != Version {
+			t.Errorf("MajorMinorRevision: expected %s, got %q", Version, info.MajorMinorRevision())
+		}
+		if info.ConformityLevel != 0x83 {
+			t.Errorf("ConformityLevel: expected 0x83, got 0x%02X", info.ConformityLevel)
+		}
+		if len(info.Objects) != 7 {
+			t.Errorf("Expected 7 objects, got %d", len(info.Objects))
+		}
+
+		info, err = client.ReadDeviceIdentification(ctx, ReadDeviceIDIndividual, DeviceIDProductCode)
+		if err != nil {
+			t.Fatalf("ReadDeviceIdentification individual failed: %v", err)
+		}
+		if len(info.Objects) != 1 || info.ProductCode() != "MemoryHandler" {
+			t.Errorf("Expected only ProductCode, got %v", info.Objects)
+		}
+	})
+}
+
+// loopbackTransport dispatches requests directly to a server without a
+// network connection.
+type loopbackTransport struct {
+	server   *Server
+	connects int
+	failNext error
+	closed   bool
+}
+
+func (t *loopbackTransport) Connect(ctx context.Context) error {
+	t.connects++
+	return nil
+}
+
+func (t *loopbackTransport) Send(ctx context.Context, unitID UnitID, pdu []byte) ([]byte, error) {
+	if err := t.failNext; err != nil {
+		t.failNext = nil
+		return nil, err
+	}
+	resp := t.server.processRequest(context.Background(), &Frame{
+		Header: MBAPHeader{UnitID: unitID},
+		PDU:    pdu,
+	}, nil)
+	return resp.PDU, nil
+}
+
+func (t *loopbackTransport) Close() error {
+	t.closed = true
+	return nil
+}
+
+func TestClientWithTransport(t *testing.T) {
+	if _, err := NewClientWithTransport(nil); err == nil {
+		t.Error("Expected error for nil transport")
+	}
+
+	handler := NewMemoryHandler(65536, 65536)
+	handler.SetHoldingRegister(2, 0, 4321)
+	loopback := &loopbackTransport{server: NewServer(handler)}
+
+	client, err := NewClientWithTransport(loopback, WithUnitID(2),
+		WithAutoReconnect(true), WithReconnectBackoff(time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewClientWithTransport failed: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	if loopback.connects != 1 {
+		t.Errorf("Expected Connect to be called once, got %d", loopback.connects)
+	}
+
+	regs, err := client.ReadHoldingRegisters(ctx, 0, 1)
+	if err != nil {
+		t.Fatalf("ReadHoldingRegisters failed: %v", err)
+	}
+	if regs[0] != 4321 {
+		t.Errorf("Expected 4321, got %d", regs[0])
+	}
+
+	// A transport error is retried through the regular reconnect logic
+	loopback.failNext = errors.New("link down")
+	if _, err := client.ReadHoldingRegisters(ctx, 0, 1); err != nil {
+		t.Fatalf("ReadHoldingRegisters after failure failed: %v", err)
+	}
+	if loopback.connects != 2 {
+		t.Errorf("Expected a reconnect, got %d connects", loopback.connects)
+	}
+
+	collected := client.Metrics().Collect()
+	if collected["requests_total"] != int64(3) || collected["requests_errors"] != int64(1) {
+		t.Errorf("Unexpected metrics: total=%v errors=%v",
+			collected["requests_total"], collected["requests_errors"])
+	}
+
+	client.Close()
+	if !loopback.closed {
+		t.Error("Expected transport to be closed")
+	}
+}
+
+func TestNewClientWithTransportOption(t *testing.T) {
+	loopback := &loopbackTransport{server: NewServer(NewMemoryHandler(0, 0))}
+
+	client, err := NewClient("loopback", WithTransport(loopback))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Close()
+
+	if client.transport != loopback {
+		t.Errorf("Expected custom transport, got %T", client.transport)
+	}
+}
+
+func TestUDPClientRetransmit(t *testing.T) {
+	handler := NewMemoryHandler(65536, 65536)
+	handler.SetHoldingRegister(1, 0, 2222)
+	server := NewServer(handler)
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket failed: %v", err)
+	}
+	defer conn.Close()
+
+	// Drop the first datagram, then send a stale response before the real one
+	var received atomic.Int32
+	go func() {
+		buf := make([]byte, 260)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if received.Add(1) == 1 {
+				continue
+			}
+
+			var req Frame
+			if err := req.Decode(buf[:n]); err != nil {
+				continue
+			}
+			resp := server.processRequest(context.Background(), &req, nil)
+
+			stale := *resp
+			stale.Header.TransactionID--
+			conn.WriteTo(stale.Encode(), addr)
+			conn.WriteTo(resp.Encode(), addr)
+		}
+	}()
+
+	client, err := NewUDPClient(conn.LocalAddr().String(),
+		WithTimeout(600*time.Millisecond), WithUDPRetransmits(2))
+	if err != nil {
+		t.Fatalf("NewUDPClient failed: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	regs, err := client.ReadHoldingRegisters(ctx, 0, 1)
+	if err != nil {
+		t.Fatalf("ReadHoldingRegisters failed: %v", err)
+	}
+	if regs[0] != 2222 {
+		t.Errorf("Expected 2222, got %d", regs[0])
+	}
+
+	if count := received.Load(); count != 2 {
+		t.Errorf("Expected 2 datagrams (one retransmission), got %d", count)
+	}
+}
+
+func TestClientPipelining(t *testing.T) {
+	const window = 4
+
+	// Collect a full window of requests, check that no further request is
+	// sent, then answer them in reverse order with the address as value.
+	var overflow atomic.Int32
+	addr := listenStream(t, func(conn net.Conn) {
+		for {
+			var batch []*Frame
+			for len(batch) < window {
+				frame, err := ReadFrame(conn)
+				if err != nil {
+					return
+				}
+				batch = append(batch, frame)
+			}
+
+			conn.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+			if _, err := conn.Read(make([]byte, 1)); err == nil {
+				overflow.Add(1)
+			}
+			conn.SetReadDeadline(time.Time{})
+
+			for i := len(batch) - 1; i >= 0; i-- {
+				req := batch[i]
+				addr := binary.BigEndian.Uint16(req.PDU[1:3])
+				resp := Frame{Header: req.Header, PDU: []byte{0x03, 0x02, byte(addr >> 8), byte(addr)}}
+				conn.Write(resp.Encode())
+			}
+		}
+	})
+
+	client, err := NewClient(addr, WithPipelining(window), WithTimeout(2*time.Second))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*window)
+	for i := 0; i < 2*window; i++ {
+		wg.Add(1)
+		go func(addr uint16) {
+			defer wg.Done()
+			regs, err := client.ReadHoldingRegisters(ctx, addr, 1)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if regs[0] != addr {
+				errs <- fmt.Errorf("address %d: got response for %d", addr, regs[0])
+			}
+		}(uint16(100 + i))
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+	if n := overflow.Load(); n != 0 {
+		t.Errorf("More than %d requests in flight (%d overflows)", window, n)
+	}
+}
+
+func TestClientPipeliningLateResponse(t *testing.T) {
+	handler := NewMemoryHandler(65536, 65536)
+	handler.SetHoldingRegister(1, 0, 7)
+	server := NewServer(handler)
+
+	// The first response arrives after the client gave up on it
+	addr := listenStream(t, func(conn net.Conn) {
+		for n := 0; ; n++ {
+			frame, err := ReadFrame(conn)
+			if err != nil {
+				return
+			}
+			if n == 0 {
+				time.Sleep(150 * time.Millisecond)
+			}
+			conn.Write(server.processRequest(context.Background(), frame, nil).Encode())
+		}
+	})
+
+	client, err := NewClient(addr, WithPipelining(2), WithTimeout(2*time.Second))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	shortCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	_, err = client.ReadHoldingRegisters(shortCtx, 0, 1)
+	cancel()
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Fatalf("Expected timeout, got %v", err)
+	}
+
+	// The orphaned response is dropped and the connection stays usable
+	regs, err := client.ReadHoldingRegisters(ctx, 0, 2)
+	if err != nil {
+		t.Fatalf("ReadHoldingRegisters failed: %v", err)
+	}
+	if len(regs) != 2 || regs[0] != 7 {
+		t.Errorf("Expected [7 0], got %v", regs)
+	}
+}
+
+func TestClientPipeliningClose(t *testing.T) {
+	// The server reads requests but never answers
+	received := make(chan struct{}, 1)
+	addr := listenStream(t, func(conn net.Conn) {
+		for {
+			if _, err := ReadFrame(conn); err != nil {
+				return
+			}
+			received <- struct{}{}
+		}
+	})
+
+	client, err := NewClient(addr, WithPipelining(2), WithTimeout(5*time.Second), WithMaxRetries(0))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.ReadHoldingRegisters(ctx, 0, 1)
+		done <- err
+	}()
+	<-received
+
+	// Closing fails the request in flight at once
+	client.Close()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("Expected an error for a request outstanding at Close")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Request not failed by Close")
+	}
 }
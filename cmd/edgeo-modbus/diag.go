@@ -22,18 +22,20 @@ import (
 	"strings"
 	"text/tabwriter"
 
+	"github.com/edgeo-scada/modbus"
 	"github.com/spf13/cobra"
 )
 
 var (
-	diagSubFunc uint16
-	diagData    string
+	diagSubFunc  uint16
+	diagData     string
+	diagClearLog bool
 )
 
 var diagCmd = &cobra.Command{
 	Use:   "diag",
 	Short: "Diagnostic functions",
-	Long:  `Execute Modbus diagnostic functions (FC07, FC08, FC11, FC17).`,
+	Long:  `Execute Modbus diagnostic functions (FC07, FC08, FC11, FC12, FC17).`,
 }
 
 var diagExceptionStatusCmd = &cobra.Command{
@@ -51,13 +53,26 @@ var diagDiagnosticsCmd = &cobra.Command{
 
 Sub-functions:
   0  - Return Query Data (echo test)
-  1  - Restart Communications
-  10 - Clear Counters
+  1  - Restart Communications (--clear-log also clears the event log)
+  2  - Return Diagnostic Register
+  3  - Change ASCII Input Delimiter
+  4  - Force Listen Only Mode (no response)
+  10 - Clear Counters and Diagnostic Register
   11 - Return Bus Message Count
   12 - Return Bus Communication Error Count
-  13 - Return Bus Exception Error Count`,
+  13 - Return Bus Exception Error Count
+  14 - Return Server Message Count
+  15 - Return Server No Response Count
+  16 - Return Server NAK Count
+  17 - Return Server Busy Count
+  18 - Return Bus Character Overrun Count
+  20 - Clear Overrun Counter and Flag
+
+Sub-functions other than 0 send a 0x0000 data field unless --data is given.`,
 	Example: `  modbuscli diag diagnostics -s 0 -d "Hello"
-  modbuscli diag diagnostics -s 11`,
+  modbuscli diag diagnostics -s 11
+  modbuscli diag diagnostics -s 4
+  modbuscli diag diagnostics -s 1 --clear-log`,
 	RunE: runDiagnostics,
 }
 
@@ -68,6 +83,13 @@ var diagCommEventCounterCmd = &cobra.Command{
 	RunE:    runCommEventCounter,
 }
 
+var diagCommEventLogCmd = &cobra.Command{
+	Use:     "comm-event-log",
+	Aliases: []string{"cel", "log"},
+	Short:   "Get communication event log (FC12)",
+	RunE:    runCommEventLog,
+}
+
 var diagServerIDCmd = &cobra.Command{
 	Use:     "server-id",
 	Aliases: []string{"id"},
@@ -79,10 +101,12 @@ func init() {
 	diagCmd.AddCommand(diagExceptionStatusCmd)
 	diagCmd.AddCommand(diagDiagnosticsCmd)
 	diagCmd.AddCommand(diagCommEventCounterCmd)
+	diagCmd.AddCommand(diagCommEventLogCmd)
 	diagCmd.AddCommand(diagServerIDCmd)
 
 	diagDiagnosticsCmd.Flags().Uint16VarP(&diagSubFunc, "subfunc", "s", 0, "Sub-function code")
 	diagDiagnosticsCmd.Flags().StringVarP(&diagData, "data", "d", "", "Data to send (for echo test)")
+	diagDiagnosticsCmd.Flags().BoolVar(&diagClearLog, "clear-log", false, "Clear the comm event log on restart (sub-function 1)")
 }
 
 func runExceptionStatus(cmd *cobra.Command, args []string) error {
@@ -149,8 +173,26 @@ func runDiagnostics(cmd *cobra.Command, args []string) error {
 	}
 
 	data := []byte(diagData)
+	if len(data) == 0 && diagSubFunc != modbus.DiagReturnQueryData {
+		data = []byte{0x00, 0x00}
+	}
+	if diagSubFunc == modbus.DiagRestartCommunications && diagClearLog {
+		data = []byte{0xFF, 0x00}
+	}
+
 	resp, err := client.Diagnostics(ctx, diagSubFunc, data)
 	if err != nil {
+		// Devices in listen only mode do not respond
+		if ctx.Err() == context.DeadlineExceeded {
+			switch diagSubFunc {
+			case modbus.DiagForceListenOnlyMode:
+				outputSuccess("No response: device is in listen only mode until restarted (sub-function 1)")
+				return nil
+			case modbus.DiagRestartCommunications:
+				outputWarning("No response: the device was in listen only mode or is unreachable")
+				return nil
+			}
+		}
 		return fmt.Errorf("diagnostics failed: %w", err)
 	}
 
@@ -198,6 +240,10 @@ func runDiagnostics(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if diagSubFunc == modbus.DiagReturnDiagnosticRegister && len(resp) >= 2 {
+		fmt.Printf("Register:     0x%02X%02X\n", resp[0], resp[1])
+	}
+
 	if diagSubFunc >= 11 && diagSubFunc <= 18 && len(resp) >= 2 {
 		value := uint16(resp[0])<<8 | uint16(resp[1])
 		fmt.Printf("Counter:      %d\n", value)
@@ -251,6 +297,120 @@ func runCommEventCounter(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func runCommEventLog(cmd *cobra.Command, args []string) error {
+	client, err := createClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := client.Connect(ctx); err != nil {
+		return fmt.Errorf("connection failed: %w", err)
+	}
+
+	log, err := client.GetCommEventLog(ctx)
+	if err != nil {
+		return fmt.Errorf("get comm event log failed: %w", err)
+	}
+
+	if outputFmt == "json" {
+		events := make([]map[string]interface{}, len(log.Events))
+		for i, e := range log.Events {
+			events[i] = map[string]interface{}{
+				"raw":         e,
+				"description": describeCommEvent(e),
+			}
+		}
+		data := map[string]interface{}{
+			"status":        log.Status,
+			"event_count":   log.EventCount,
+			"message_count": log.MessageCount,
+			"busy":          log.Status == 0xFFFF,
+			"events":        events,
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(data)
+	}
+
+	fmt.Println()
+	fmt.Println(color(colorBold, "Communication Event Log (FC12)"))
+	fmt.Println(strings.Repeat("-", 40))
+
+	statusStr := color(colorGreen, "Ready")
+	if log.Status == 0xFFFF {
+		statusStr = color(colorYellow, "Busy")
+	}
+	fmt.Printf("Status:         %s (0x%04X)\n", statusStr, log.Status)
+	fmt.Printf("Event Count:    %d\n", log.EventCount)
+	fmt.Printf("Message Count:  %d\n", log.MessageCount)
+
+	if len(log.Events) > 0 {
+		fmt.Println()
+		fmt.Println("Events (most recent first):")
+		for i, e := range log.Events {
+			fmt.Printf("  %2d  0x%02X  %s\n", i, e, describeCommEvent(e))
+		}
+	}
+	fmt.Println()
+	return nil
+}
+
+// commEventFlag names a flag bit of a communication event byte.
+type commEventFlag struct {
+	bit  byte
+	name string
+}
+
+var receiveEventFlags = []commEventFlag{
+	{modbus.EventReceiveCommError, "communication error"},
+	{modbus.EventReceiveOverrun, "character overrun"},
+	{modbus.EventReceiveListenOnly, "listen only"},
+	{modbus.EventReceiveBroadcast, "broadcast"},
+}
+
+var sendEventFlags = []commEventFlag{
+	{modbus.EventSendReadException, "read exception"},
+	{modbus.EventSendAbortException, "abort exception"},
+	{modbus.EventSendBusyException, "busy exception"},
+	{modbus.EventSendNAKException, "NAK exception"},
+	{modbus.EventSendWriteTimeout, "write timeout"},
+	{modbus.EventSendListenOnly, "listen only"},
+}
+
+// describeCommEvent decodes a communication event log byte (FC12).
+func describeCommEvent(e byte) string {
+	var desc string
+	var flags []commEventFlag
+
+	switch {
+	case e&modbus.EventReceive != 0:
+		desc, flags = "Receive", receiveEventFlags
+	case e&modbus.EventSend != 0:
+		desc, flags = "Send", sendEventFlags
+	case e == modbus.EventEnteredListenOnly:
+		return "Entered listen only mode"
+	case e == modbus.EventCommRestart:
+		return "Communication restart"
+	default:
+		return "Unknown"
+	}
+
+	var set []string
+	for _, f := range flags {
+		if e&f.bit != 0 {
+			set = append(set, f.name)
+		}
+	}
+	if len(set) > 0 {
+		desc += " (" + strings.Join(set, ", ") + ")"
+	}
+	return desc
+}
+
 func runServerID(cmd *cobra.Command, args []string) error {
 	client, err := createClient()
 	if err != nil {
@@ -320,7 +480,9 @@ func getDiagSubFuncName(sf uint16) string {
 		0:  "Return Query Data",
 		1:  "Restart Communications",
 		2:  "Return Diagnostic Register",
-		10: "Clear Counters",
+		3:  "Change ASCII Input Delimiter",
+		4:  "Force Listen Only Mode",
+		10: "Clear Counters and Diagnostic Register",
 		11: "Return Bus Message Count",
 		12: "Return Bus Comm Error Count",
 		13: "Return Bus Exception Error Count",
@@ -329,6 +491,7 @@ func getDiagSubFuncName(sf uint16) string {
 		16: "Return Server NAK Count",
 		17: "Return Server Busy Count",
 		18: "Return Bus Character Overrun Count",
+		20: "Clear Overrun Counter and Flag",
 	}
 	if name, ok := names[sf]; ok {
 		return name
@@ -18,6 +18,7 @@ import (
 	"context"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -44,7 +45,8 @@ Supports exporting to various formats including hexdump, CSV, and JSON.
 Automatically handles large ranges by reading in batches.`,
 	Example: `  modbuscli dump hr -a 0 -e 999 -H 192.168.1.100
   modbuscli dump ir -a 0 -e 100 -f registers.csv
-  modbuscli dump coils -a 0 -e 100`,
+  modbuscli dump coils -a 0 -e 100
+  modbuscli dump point inverter -P inverter.yaml -o csv -f inverter.csv`,
 }
 
 var dumpHoldingCmd = &cobra.Command{
@@ -75,11 +77,24 @@ var dumpDiscreteCmd = &cobra.Command{
 	RunE:    runDumpDiscreteInputs,
 }
 
+var dumpPointCmd = &cobra.Command{
+	Use:     "point NAME...",
+	Aliases: []string{"points", "p"},
+	Short:   "Dump points of a device profile",
+	Long: `Dump named points of the device profiles loaded with --profile.
+
+Points are named profile.point; the name of a profile alone dumps all of its
+readable points.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runDumpPoint,
+}
+
 func init() {
 	dumpCmd.AddCommand(dumpHoldingCmd)
 	dumpCmd.AddCommand(dumpInputCmd)
 	dumpCmd.AddCommand(dumpCoilsCmd)
 	dumpCmd.AddCommand(dumpDiscreteCmd)
+	dumpCmd.AddCommand(dumpPointCmd)
 
 	for _, cmd := range []*cobra.Command{dumpHoldingCmd, dumpInputCmd} {
 		cmd.Flags().Uint16VarP(&dumpStartAddr, "start", "a", 0, "Start address")
@@ -96,30 +111,80 @@ func init() {
 		cmd.Flags().StringVarP(&dumpOutFile, "file", "f", "", "Output file (default: stdout)")
 		cmd.Flags().BoolVar(&dumpShowEmpty, "show-empty", false, "Show addresses that return errors")
 	}
+
+	dumpPointCmd.Flags().StringVarP(&dumpOutFile, "file", "f", "", "Output file (default: stdout)")
+	dumpPointCmd.Flags().BoolVar(&dumpShowEmpty, "show-empty", false, "Show points that return errors")
 }
 
 func runDumpHoldingRegisters(cmd *cobra.Command, args []string) error {
-	return dumpRegisters(func(ctx context.Context, client *modbus.Client, addr, qty uint16) ([]uint16, error) {
-		return client.ReadHoldingRegisters(ctx, addr, qty)
-	}, "Holding Registers")
+	return dumpRegisters((*modbus.Client).ReadHoldingRange, "Holding Registers")
 }
 
 func runDumpInputRegisters(cmd *cobra.Command, args []string) error {
-	return dumpRegisters(func(ctx context.Context, client *modbus.Client, addr, qty uint16) ([]uint16, error) {
-		return client.ReadInputRegisters(ctx, addr, qty)
-	}, "Input Registers")
+	return dumpRegisters((*modbus.Client).ReadInputRange, "Input Registers")
 }
 
 func runDumpCoils(cmd *cobra.Command, args []string) error {
-	return dumpBools(func(ctx context.Context, client *modbus.Client, addr, qty uint16) ([]bool, error) {
-		return client.ReadCoils(ctx, addr, qty)
-	}, "Coils")
+	return dumpBools((*modbus.Client).ReadCoilRange, "Coils")
 }
 
 func runDumpDiscreteInputs(cmd *cobra.Command, args []string) error {
-	return dumpBools(func(ctx context.Context, client *modbus.Client, addr, qty uint16) ([]bool, error) {
-		return client.ReadDiscreteInputs(ctx, addr, qty)
-	}, "Discrete Inputs")
+	return dumpBools((*modbus.Client).ReadDiscreteInputRange, "Discrete Inputs")
+}
+
+func runDumpPoint(cmd *cobra.Command, args []string) error {
+	refs, err := resolvePoints(args)
+	if err != nil {
+		return err
+	}
+
+	client, err := createClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := client.Connect(ctx); err != nil {
+		return fmt.Errorf("connection failed: %w", err)
+	}
+
+	outputInfo("Dumping %d points...", len(refs))
+	startTime := time.Now()
+
+	values, err := readPoints(ctx, client, refs)
+	if values == nil {
+		return fmt.Errorf("read points failed: %w", err)
+	}
+	results := make([]PointResult, 0, len(refs))
+	for _, r := range pointResults(refs, values, err) {
+		if r.Error == "" || dumpShowEmpty {
+			results = append(results, r)
+		}
+	}
+
+	duration := time.Since(startTime)
+	outputInfo("Read %d points in %s", len(values), duration.Round(time.Millisecond))
+
+	var out *os.File = os.Stdout
+	if dumpOutFile != "" {
+		f, err := os.Create(dumpOutFile)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+	if err := outputPointValues(out, "Points Dump", results); err != nil {
+		return err
+	}
+
+	if dumpOutFile != "" {
+		outputSuccess("Output written to %s", dumpOutFile)
+	}
+	return nil
 }
 
 type DumpRegister struct {
@@ -135,10 +200,15 @@ type DumpBool struct {
 	Error   string `json:"error,omitempty"`
 }
 
-func dumpRegisters(readFunc func(context.Context, *modbus.Client, uint16, uint16) ([]uint16, error), title string) error {
+// rangeReader reads a range of items, such as modbus.Client.ReadHoldingRange.
+type rangeReader[T any] func(*modbus.Client, context.Context, uint16, int, ...modbus.RangeOption) ([]T, error)
+
+// readDumpRange reads the dump range in batches and returns the values with
+// the error of each address that could not be read.
+func readDumpRange[T any](readFunc rangeReader[T], maxBatch uint16) ([]T, map[uint16]error, error) {
 	client, err := createClient()
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 	defer client.Close()
 
@@ -146,58 +216,63 @@ func dumpRegisters(readFunc func(context.Context, *modbus.Client, uint16, uint16
 	defer cancel()
 
 	if err := client.Connect(ctx); err != nil {
-		return fmt.Errorf("connection failed: %w", err)
+		return nil, nil, fmt.Errorf("connection failed: %w", err)
+	}
+
+	batchSize := dumpBatchSize
+	if batchSize == 0 || batchSize > maxBatch {
+		batchSize = maxBatch
+	}
+
+	count := int(dumpEndAddr) - int(dumpStartAddr) + 1
+	values, err := readFunc(client, ctx, dumpStartAddr, count, modbus.WithChunkSize(int(batchSize)))
+
+	failed := make(map[uint16]error)
+	var rangeErr *modbus.RangeError
+	switch {
+	case errors.As(err, &rangeErr):
+		for _, chunk := range rangeErr.Chunks {
+			for i := 0; i < int(chunk.Quantity); i++ {
+				failed[chunk.Address+uint16(i)] = chunk.Err
+			}
+		}
+	case err != nil:
+		return nil, nil, err
 	}
+	return values, failed, nil
+}
 
+func dumpRegisters(readFunc rangeReader[uint16], title string) error {
 	if dumpEndAddr < dumpStartAddr {
 		dumpStartAddr, dumpEndAddr = dumpEndAddr, dumpStartAddr
 	}
 
-	totalCount := int(dumpEndAddr - dumpStartAddr + 1)
+	totalCount := int(dumpEndAddr) - int(dumpStartAddr) + 1
 	results := make([]DumpRegister, 0, totalCount)
 
 	outputInfo("Dumping %s from %d to %d (%d registers)...", title, dumpStartAddr, dumpEndAddr, totalCount)
 	startTime := time.Now()
 
-	for addr := dumpStartAddr; addr <= dumpEndAddr; {
-		batchSize := dumpBatchSize
-		if addr+batchSize > dumpEndAddr+1 {
-			batchSize = dumpEndAddr - addr + 1
-		}
-
-		readCtx, readCancel := context.WithTimeout(ctx, timeout)
-		values, err := readFunc(readCtx, client, addr, batchSize)
-		readCancel()
-
-		if err != nil {
+	values, failed, err := readDumpRange(readFunc, modbus.MaxQuantityRegisters)
+	if err != nil {
+		return err
+	}
+	for i, v := range values {
+		addr := dumpStartAddr + uint16(i)
+		if err, ok := failed[addr]; ok {
 			if dumpShowEmpty {
-				for i := uint16(0); i < batchSize; i++ {
-					results = append(results, DumpRegister{
-						Address: addr + i,
-						Error:   err.Error(),
-					})
-				}
-			}
-		} else {
-			for i, v := range values {
 				results = append(results, DumpRegister{
-					Address: addr + uint16(i),
-					Value:   v,
-					Hex:     fmt.Sprintf("0x%04X", v),
+					Address: addr,
+					Error:   err.Error(),
 				})
 			}
+			continue
 		}
-
-		addr += batchSize
-
-		if verbose {
-			progress := float64(addr-dumpStartAddr) / float64(totalCount) * 100
-			fmt.Fprintf(os.Stderr, "\rProgress: %.1f%%", progress)
-		}
-	}
-
-	if verbose {
-		fmt.Fprintln(os.Stderr)
+		results = append(results, DumpRegister{
+			Address: addr,
+			Value:   v,
+			Hex:     fmt.Sprintf("0x%04X", v),
+		})
 	}
 
 	duration := time.Since(startTime)
@@ -206,64 +281,36 @@ func dumpRegisters(readFunc func(context.Context, *modbus.Client, uint16, uint16
 	return outputDumpRegisters(title, results)
 }
 
-func dumpBools(readFunc func(context.Context, *modbus.Client, uint16, uint16) ([]bool, error), title string) error {
-	client, err := createClient()
-	if err != nil {
-		return err
-	}
-	defer client.Close()
-
-	ctx, cancel := context.WithTimeout(context.Background(), timeout*10)
-	defer cancel()
-
-	if err := client.Connect(ctx); err != nil {
-		return fmt.Errorf("connection failed: %w", err)
-	}
-
+func dumpBools(readFunc rangeReader[bool], title string) error {
 	if dumpEndAddr < dumpStartAddr {
 		dumpStartAddr, dumpEndAddr = dumpEndAddr, dumpStartAddr
 	}
 
-	totalCount := int(dumpEndAddr - dumpStartAddr + 1)
+	totalCount := int(dumpEndAddr) - int(dumpStartAddr) + 1
 	results := make([]DumpBool, 0, totalCount)
 
 	outputInfo("Dumping %s from %d to %d (%d items)...", title, dumpStartAddr, dumpEndAddr, totalCount)
 	startTime := time.Now()
 
-	batchSize := dumpBatchSize
-	if batchSize > 2000 {
-		batchSize = 2000
+	values, failed, err := readDumpRange(readFunc, modbus.MaxQuantityCoils)
+	if err != nil {
+		return err
 	}
-
-	for addr := dumpStartAddr; addr <= dumpEndAddr; {
-		bs := batchSize
-		if addr+bs > dumpEndAddr+1 {
-			bs = dumpEndAddr - addr + 1
-		}
-
-		readCtx, readCancel := context.WithTimeout(ctx, timeout)
-		values, err := readFunc(readCtx, client, addr, bs)
-		readCancel()
-
-		if err != nil {
+	for i, v := range values {
+		addr := dumpStartAddr + uint16(i)
+		if err, ok := failed[addr]; ok {
 			if dumpShowEmpty {
-				for i := uint16(0); i < bs; i++ {
-					results = append(results, DumpBool{
-						Address: addr + i,
-						Error:   err.Error(),
-					})
-				}
-			}
-		} else {
-			for i, v := range values {
 				results = append(results, DumpBool{
-					Address: addr + uint16(i),
-					Value:   v,
+					Address: addr,
+					Error:   err.Error(),
 				})
 			}
+			continue
 		}
-
-		addr += bs
+		results = append(results, DumpBool{
+			Address: addr,
+			Value:   v,
+		})
 	}
 
 	duration := time.Since(startTime)
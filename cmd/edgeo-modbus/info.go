@@ -35,6 +35,7 @@ var infoCmd = &cobra.Command{
 
 This command attempts to:
   - Test connectivity
+  - Read device identification (FC43/14)
   - Read server identification (FC17)
   - Read exception status (FC07)
   - Measure response latency`,
@@ -44,17 +45,23 @@ This command attempts to:
 }
 
 type DeviceInfo struct {
-	Address      string        `json:"address"`
-	UnitID       uint8         `json:"unit_id"`
-	Reachable    bool          `json:"reachable"`
-	Connected    bool          `json:"connected"`
-	Latency      time.Duration `json:"latency_ms"`
-	ServerID     string        `json:"server_id,omitempty"`
-	ServerIDHex  string        `json:"server_id_hex,omitempty"`
-	RunStatus    string        `json:"run_status,omitempty"`
-	Exception    uint8         `json:"exception_status,omitempty"`
-	Capabilities []string      `json:"capabilities,omitempty"`
-	Error        string        `json:"error,omitempty"`
+	Address             string        `json:"address"`
+	UnitID              uint8         `json:"unit_id"`
+	Reachable           bool          `json:"reachable"`
+	Connected           bool          `json:"connected"`
+	Latency             time.Duration `json:"latency_ms"`
+	VendorName          string        `json:"vendor_name,omitempty"`
+	ProductCode         string        `json:"product_code,omitempty"`
+	Revision            string        `json:"revision,omitempty"`
+	VendorURL           string        `json:"vendor_url,omitempty"`
+	ProductName         string        `json:"product_name,omitempty"`
+	ModelName           string        `json:"model_name,omitempty"`
+	UserApplicationName string        `json:"user_application_name,omitempty"`
+	ServerID            string        `json:"server_id,omitempty"`
+	ServerIDHex         string        `json:"server_id_hex,omitempty"`
+	Exception           uint8         `json:"exception_status,omitempty"`
+	Capabilities        []string      `json:"capabilities,omitempty"`
+	Error               string        `json:"error,omitempty"`
 }
 
 func runInfo(cmd *cobra.Command, args []string) error {
@@ -100,19 +107,22 @@ func runInfo(cmd *cobra.Command, args []string) error {
 		info.Error = err.Error()
 	}
 
+	if ident, err := readDeviceIdentification(ctx, client); err == nil {
+		info.VendorName = ident.VendorName()
+		info.ProductCode = ident.ProductCode()
+		info.Revision = ident.MajorMinorRevision()
+		info.VendorURL = ident.VendorURL()
+		info.ProductName = ident.ProductName()
+		info.ModelName = ident.ModelName()
+		info.UserApplicationName = ident.UserApplicationName()
+	}
+
 	serverID, err := client.ReportServerID(ctx)
 	if err == nil && len(serverID) > 0 {
 		info.ServerIDHex = fmt.Sprintf("% X", serverID)
 		if isPrintable(serverID) {
 			info.ServerID = strings.TrimRight(string(serverID), "\x00")
 		}
-		if len(serverID) >= 2 {
-			if serverID[1] == 0xFF {
-				info.RunStatus = "Running"
-			} else {
-				info.RunStatus = "Stopped"
-			}
-		}
 	}
 
 	exStatus, err := client.ReadExceptionStatus(ctx)
@@ -125,6 +135,16 @@ func runInfo(cmd *cobra.Command, args []string) error {
 	return outputDeviceInfo(&info)
 }
 
+// readDeviceIdentification reads the regular identification objects,
+// falling back to the basic ones for devices that only implement those.
+func readDeviceIdentification(ctx context.Context, client *modbus.Client) (*modbus.DeviceIdentification, error) {
+	ident, err := client.ReadDeviceIdentification(ctx, modbus.ReadDeviceIDRegular, modbus.DeviceIDVendorName)
+	if err != nil && !modbus.IsIllegalFunction(err) {
+		ident, err = client.ReadDeviceIdentification(ctx, modbus.ReadDeviceIDBasic, modbus.DeviceIDVendorName)
+	}
+	return ident, err
+}
+
 func testCapabilities(ctx context.Context, client *modbus.Client) []string {
 	var caps []string
 
@@ -200,20 +220,30 @@ func outputDeviceInfo(info *DeviceInfo) error {
 
 	fmt.Printf("Latency:      %dms\n", info.Latency.Milliseconds())
 
+	identification := []struct {
+		label string
+		value string
+	}{
+		{"Vendor:", info.VendorName},
+		{"Product Code:", info.ProductCode},
+		{"Revision:", info.Revision},
+		{"Vendor URL:", info.VendorURL},
+		{"Product Name:", info.ProductName},
+		{"Model Name:", info.ModelName},
+		{"Application:", info.UserApplicationName},
+	}
+	for _, obj := range identification {
+		if obj.value != "" {
+			fmt.Printf("%-14s%s\n", obj.label, obj.value)
+		}
+	}
+
 	if info.ServerID != "" {
 		fmt.Printf("Server ID:    %s\n", info.ServerID)
 	} else if info.ServerIDHex != "" {
 		fmt.Printf("Server ID:    %s (hex)\n", info.ServerIDHex)
 	}
 
-	if info.RunStatus != "" {
-		statusColor := colorGreen
-		if info.RunStatus == "Stopped" {
-			statusColor = colorYellow
-		}
-		fmt.Printf("Run Status:   %s\n", color(statusColor, info.RunStatus))
-	}
-
 	if info.Exception != 0 {
 		fmt.Printf("Exception:    0x%02X (%08b)\n", info.Exception, info.Exception)
 	}
@@ -54,6 +54,7 @@ Available commands:
 
   output <format>               - Set output format (table/json/csv/hex)
   format <type>                 - Set register format (uint16/int16/etc)
+  order <order>                 - Set byte order (ABCD/CDAB/BADC/DCBA/...)
 
   help                          - Show help
   quit                          - Exit`,
@@ -191,6 +192,18 @@ func (s *InteractiveSession) execute(line string) error {
 		s.regFormat = args[0]
 		fmt.Printf("Register format set to %s\n", s.regFormat)
 		return nil
+	case "order":
+		if len(args) < 1 {
+			fmt.Printf("Current byte order: %s\n", order)
+			return nil
+		}
+		o, err := modbus.ParseByteOrder(args[0])
+		if err != nil {
+			return err
+		}
+		order = o
+		fmt.Printf("Byte order set to %s\n", order)
+		return nil
 	case "rc", "readcoils":
 		return s.readCoils(args)
 	case "rdi", "readdiscrete":
@@ -269,6 +282,7 @@ func (s *InteractiveSession) showStatus() {
 	fmt.Printf("Unit ID:       %d\n", s.currentUnit)
 	fmt.Printf("Output:        %s\n", outputFmt)
 	fmt.Printf("Reg Format:    %s\n", s.regFormat)
+	fmt.Printf("Byte Order:    %s\n", order)
 	fmt.Printf("Timeout:       %s\n", timeout)
 	fmt.Println()
 }
@@ -300,7 +314,8 @@ Commands:
 
   Settings:
     output <format>        Set output format (table/json/csv/hex/raw)
-    format <type>          Set register format (uint16/int16/uint32/int32/float32)
+    format <type>          Set register format (uint16/int16/uint32/int32/float32/...)
+    order <order>          Set byte order of multi-register values (ABCD/CDAB/BADC/DCBA/...)
 
   General:
     help                   Show this help
@@ -483,7 +498,15 @@ func (s *InteractiveSession) writeMultipleRegisters(args []string) error {
 		return fmt.Errorf("usage: wrs <address> <v1,v2,...>")
 	}
 	addr, _ := strconv.Atoi(args[0])
-	values, err := parseUint16Values(args[1:])
+	// Formats without a numeric encoding write raw registers
+	var values []uint16
+	var err error
+	switch s.regFormat {
+	case "int16", "uint32", "int32", "float32", "uint64", "int64", "float64":
+		values, err = parseRegisterValues(args[1:], s.regFormat)
+	default:
+		values, err = parseUint16Values(args[1:])
+	}
 	if err != nil {
 		return err
 	}
@@ -15,7 +15,6 @@
 package main
 
 import (
-	"encoding/binary"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
@@ -211,67 +210,34 @@ func outputRegisterTable(title string, startAddr uint16, values []uint16, format
 	case "uint16", "":
 		fmt.Fprintln(w, "ADDRESS\tDECIMAL\tHEX\tBINARY")
 		fmt.Fprintln(w, "-------\t-------\t---\t------")
-		for i, v := range values {
+		for i := range values {
 			addr := startAddr + uint16(i)
+			v := order.Uint16(values[i:])
 			fmt.Fprintf(w, "%d\t%d\t0x%04X\t%016b\n", addr, v, v, v)
 		}
 
 	case "int16":
 		fmt.Fprintln(w, "ADDRESS\tDECIMAL\tHEX")
 		fmt.Fprintln(w, "-------\t-------\t---")
-		for i, v := range values {
-			addr := startAddr + uint16(i)
-			signed := int16(v)
-			fmt.Fprintf(w, "%d\t%d\t0x%04X\n", addr, signed, v)
-		}
-
-	case "uint32":
-		fmt.Fprintln(w, "ADDRESS\tVALUE\tHEX")
-		fmt.Fprintln(w, "-------\t-----\t---")
-		for i := 0; i < len(values)-1; i += 2 {
-			addr := startAddr + uint16(i)
-			val := combineRegisters(values[i], values[i+1])
-			fmt.Fprintf(w, "%d-%d\t%d\t0x%08X\n", addr, addr+1, val, val)
-		}
-
-	case "int32":
-		fmt.Fprintln(w, "ADDRESS\tVALUE\tHEX")
-		fmt.Fprintln(w, "-------\t-----\t---")
-		for i := 0; i < len(values)-1; i += 2 {
-			addr := startAddr + uint16(i)
-			val := int32(combineRegisters(values[i], values[i+1]))
-			fmt.Fprintf(w, "%d-%d\t%d\t0x%08X\n", addr, addr+1, val, uint32(val))
-		}
-
-	case "float32":
-		fmt.Fprintln(w, "ADDRESS\tVALUE\tHEX")
-		fmt.Fprintln(w, "-------\t-----\t---")
-		for i := 0; i < len(values)-1; i += 2 {
+		for i := range values {
 			addr := startAddr + uint16(i)
-			bits := combineRegisters(values[i], values[i+1])
-			val := math.Float32frombits(bits)
-			fmt.Fprintf(w, "%d-%d\t%g\t0x%08X\n", addr, addr+1, val, bits)
+			v := order.Uint16(values[i:])
+			fmt.Fprintf(w, "%d\t%d\t0x%04X\n", addr, int16(v), v)
 		}
 
-	case "float64":
+	case "uint32", "int32", "float32", "uint64", "int64", "float64":
+		n := registerWidth(format)
 		fmt.Fprintln(w, "ADDRESS\tVALUE\tHEX")
 		fmt.Fprintln(w, "-------\t-----\t---")
-		for i := 0; i < len(values)-3; i += 4 {
+		for i := 0; i+n <= len(values); i += n {
 			addr := startAddr + uint16(i)
-			bits := combineRegisters64(values[i], values[i+1], values[i+2], values[i+3])
-			val := math.Float64frombits(bits)
-			fmt.Fprintf(w, "%d-%d\t%g\t0x%016X\n", addr, addr+3, val, bits)
+			value, raw := decodeRegisters(values[i:i+n], format)
+			fmt.Fprintf(w, "%d-%d\t%v\t0x%0*X\n", addr, addr+uint16(n-1), value, 4*n, raw)
 		}
 
 	case "string":
 		fmt.Fprintln(w, "STRING VALUE:")
-		var sb strings.Builder
-		for _, v := range values {
-			// Big-endian byte order for ASCII
-			sb.WriteByte(byte(v >> 8))
-			sb.WriteByte(byte(v & 0xFF))
-		}
-		fmt.Fprintln(w, strings.TrimRight(sb.String(), "\x00"))
+		fmt.Fprintln(w, registerString(values))
 	}
 
 	w.Flush()
@@ -283,47 +249,15 @@ func outputRegisterJSON(startAddr uint16, values []uint16, format string) error
 	results := make([]RegisterResult, 0)
 
 	switch format {
-	case "uint32":
-		for i := 0; i < len(values)-1; i += 2 {
-			val := combineRegisters(values[i], values[i+1])
-			results = append(results, RegisterResult{
-				Address: startAddr + uint16(i),
-				Raw:     values[i],
-				Hex:     fmt.Sprintf("0x%08X", val),
-				Value:   val,
-				Format:  format,
-			})
-		}
-	case "int32":
-		for i := 0; i < len(values)-1; i += 2 {
-			val := int32(combineRegisters(values[i], values[i+1]))
+	case "int16", "uint32", "int32", "float32", "uint64", "int64", "float64":
+		n := registerWidth(format)
+		for i := 0; i+n <= len(values); i += n {
+			value, raw := decodeRegisters(values[i:i+n], format)
 			results = append(results, RegisterResult{
 				Address: startAddr + uint16(i),
 				Raw:     values[i],
-				Hex:     fmt.Sprintf("0x%08X", uint32(val)),
-				Value:   val,
-				Format:  format,
-			})
-		}
-	case "float32":
-		for i := 0; i < len(values)-1; i += 2 {
-			bits := combineRegisters(values[i], values[i+1])
-			val := math.Float32frombits(bits)
-			results = append(results, RegisterResult{
-				Address: startAddr + uint16(i),
-				Raw:     values[i],
-				Hex:     fmt.Sprintf("0x%08X", bits),
-				Value:   val,
-				Format:  format,
-			})
-		}
-	case "int16":
-		for i, v := range values {
-			results = append(results, RegisterResult{
-				Address: startAddr + uint16(i),
-				Raw:     v,
-				Hex:     fmt.Sprintf("0x%04X", v),
-				Value:   int16(v),
+				Hex:     fmt.Sprintf("0x%0*X", 4*n, raw),
+				Value:   value,
 				Format:  format,
 			})
 		}
@@ -333,7 +267,7 @@ func outputRegisterJSON(startAddr uint16, values []uint16, format string) error
 				Address: startAddr + uint16(i),
 				Raw:     v,
 				Hex:     fmt.Sprintf("0x%04X", v),
-				Value:   v,
+				Value:   order.Uint16(values[i:]),
 				Format:  "uint16",
 			})
 		}
@@ -348,31 +282,11 @@ func outputRegisterCSV(startAddr uint16, values []uint16, format string) error {
 	w := csv.NewWriter(os.Stdout)
 	w.Write([]string{"address", "raw", "hex", "value"})
 
-	switch format {
-	case "uint32":
-		for i := 0; i < len(values)-1; i += 2 {
-			addr := strconv.Itoa(int(startAddr) + i)
-			val := combineRegisters(values[i], values[i+1])
-			w.Write([]string{addr, strconv.Itoa(int(values[i])), fmt.Sprintf("0x%08X", val), strconv.FormatUint(uint64(val), 10)})
-		}
-	case "int32":
-		for i := 0; i < len(values)-1; i += 2 {
-			addr := strconv.Itoa(int(startAddr) + i)
-			val := int32(combineRegisters(values[i], values[i+1]))
-			w.Write([]string{addr, strconv.Itoa(int(values[i])), fmt.Sprintf("0x%08X", uint32(val)), strconv.Itoa(int(val))})
-		}
-	case "float32":
-		for i := 0; i < len(values)-1; i += 2 {
-			addr := strconv.Itoa(int(startAddr) + i)
-			bits := combineRegisters(values[i], values[i+1])
-			val := math.Float32frombits(bits)
-			w.Write([]string{addr, strconv.Itoa(int(values[i])), fmt.Sprintf("0x%08X", bits), fmt.Sprintf("%g", val)})
-		}
-	default:
-		for i, v := range values {
-			addr := strconv.Itoa(int(startAddr) + i)
-			w.Write([]string{addr, strconv.Itoa(int(v)), fmt.Sprintf("0x%04X", v), strconv.Itoa(int(v))})
-		}
+	n := registerWidth(format)
+	for i := 0; i+n <= len(values); i += n {
+		addr := strconv.Itoa(int(startAddr) + i)
+		value, raw := decodeRegisters(values[i:i+n], format)
+		w.Write([]string{addr, strconv.Itoa(int(values[i])), fmt.Sprintf("0x%0*X", 4*n, raw), fmt.Sprint(value)})
 	}
 
 	w.Flush()
@@ -397,32 +311,55 @@ func outputRegisterHex(values []uint16) error {
 	return nil
 }
 
-func combineRegisters(high, low uint16) uint32 {
-	if wordOrder == "little" {
-		return uint32(low)<<16 | uint32(high)
+// registerWidth returns the number of registers of a value of the format.
+func registerWidth(format string) int {
+	switch format {
+	case "uint32", "int32", "float32":
+		return 2
+	case "uint64", "int64", "float64":
+		return 4
+	default:
+		return 1
 	}
-	return uint32(high)<<16 | uint32(low)
 }
 
-func combineRegisters64(r0, r1, r2, r3 uint16) uint64 {
-	if wordOrder == "little" {
-		return uint64(r3)<<48 | uint64(r2)<<32 | uint64(r1)<<16 | uint64(r0)
+// decodeRegisters returns the value of the format held by regs in the
+// selected byte order, and its raw bits.
+func decodeRegisters(regs []uint16, format string) (value any, raw uint64) {
+	switch format {
+	case "int16":
+		v := order.Uint16(regs)
+		return int16(v), uint64(v)
+	case "uint32":
+		v := order.Uint32(regs)
+		return v, uint64(v)
+	case "int32":
+		v := order.Uint32(regs)
+		return int32(v), uint64(v)
+	case "float32":
+		v := order.Uint32(regs)
+		return math.Float32frombits(v), uint64(v)
+	case "uint64":
+		v := order.Uint64(regs)
+		return v, v
+	case "int64":
+		v := order.Uint64(regs)
+		return int64(v), v
+	case "float64":
+		v := order.Uint64(regs)
+		return math.Float64frombits(v), v
+	default:
+		v := order.Uint16(regs)
+		return v, uint64(v)
 	}
-	return uint64(r0)<<48 | uint64(r1)<<32 | uint64(r2)<<16 | uint64(r3)
-}
-
-func swapBytes(v uint16) uint16 {
-	return (v >> 8) | (v << 8)
 }
 
-func uint16ToBytes(values []uint16) []byte {
-	result := make([]byte, len(values)*2)
-	for i, v := range values {
-		if byteOrder == "little" {
-			binary.LittleEndian.PutUint16(result[i*2:], v)
-		} else {
-			binary.BigEndian.PutUint16(result[i*2:], v)
-		}
+// registerString returns the ASCII string held by values, two characters
+// per register, following the byte swap of the selected byte order.
+func registerString(values []uint16) string {
+	var sb strings.Builder
+	for i := range values {
+		sb.Write(order.Bytes(values[i : i+1]))
 	}
-	return result
+	return strings.TrimRight(sb.String(), "\x00")
 }
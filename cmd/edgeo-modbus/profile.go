@@ -0,0 +1,258 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/edgeo-scada/modbus"
+	"github.com/edgeo-scada/modbus/profile"
+	"github.com/spf13/viper"
+)
+
+// profileFiles are the device profiles given with --profile.
+var profileFiles []string
+
+// pointRef is a point of a loaded profile.
+type pointRef struct {
+	profile *profile.Profile
+	point   *profile.Point
+}
+
+// name returns the "profile.point" name of the point.
+func (r pointRef) name() string {
+	return r.profile.Name + "." + r.point.Name
+}
+
+// PointResult is the output of a point value.
+type PointResult struct {
+	Name    string `json:"name"`
+	Table   string `json:"table"`
+	Address uint16 `json:"address"`
+	Type    string `json:"type"`
+	Value   any    `json:"value"`
+	Unit    string `json:"unit,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// loadProfiles loads the device profiles given with --profile or in the
+// configuration file. An explicit --unit overrides the unit of the
+// profiles.
+func loadProfiles() (profile.Profiles, error) {
+	files := viper.GetStringSlice("profile")
+	if len(files) == 0 {
+		return nil, errors.New("no device profile, use --profile to load one")
+	}
+
+	var profiles profile.Profiles
+	for _, file := range files {
+		p, err := profile.Load(file)
+		if err != nil {
+			return nil, err
+		}
+		if rootCmd.PersistentFlags().Changed("unit") {
+			p.UnitID = modbus.UnitID(unitID)
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles, nil
+}
+
+// lookupPoint returns the point of a "profile.point" name.
+func lookupPoint(name string) (pointRef, error) {
+	profiles, err := loadProfiles()
+	if err != nil {
+		return pointRef{}, err
+	}
+	p, pt, err := profiles.Lookup(name)
+	if err != nil {
+		return pointRef{}, err
+	}
+	return pointRef{p, pt}, nil
+}
+
+// resolvePoints returns the points of "profile.point" names; the name of
+// a profile selects all of its readable points.
+func resolvePoints(names []string) ([]pointRef, error) {
+	profiles, err := loadProfiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []pointRef
+	for _, name := range names {
+		if p := profiles.Profile(name); p != nil {
+			for i := range p.Points {
+				if p.Points[i].Readable() {
+					refs = append(refs, pointRef{p, &p.Points[i]})
+				}
+			}
+			continue
+		}
+		p, pt, err := profiles.Lookup(name)
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, pointRef{p, pt})
+	}
+	return refs, nil
+}
+
+// readPoints reads the values of points, merging the reads of the points
+// of each profile. Values that could not be read are nil and the first
+// error is returned; the values are nil if no point could be read.
+func readPoints(ctx context.Context, client *modbus.Client, refs []pointRef) ([]any, error) {
+	groups := make(map[*profile.Profile][]int)
+	var profiles []*profile.Profile
+	for i, r := range refs {
+		if _, ok := groups[r.profile]; !ok {
+			profiles = append(profiles, r.profile)
+		}
+		groups[r.profile] = append(groups[r.profile], i)
+	}
+
+	values := make([]any, len(refs))
+	var read bool
+	var firstErr error
+	for _, p := range profiles {
+		indexes := groups[p]
+		points := make([]*profile.Point, len(indexes))
+		for j, i := range indexes {
+			points[j] = refs[i].point
+		}
+		pv, err := p.Read(ctx, client, points)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		for j, i := range indexes {
+			if pv != nil && pv[j] != nil {
+				values[i] = pv[j]
+				read = true
+			}
+		}
+	}
+	if !read && firstErr != nil {
+		return nil, firstErr
+	}
+	return values, firstErr
+}
+
+// pointResults returns the output of point values read with readPoints.
+func pointResults(refs []pointRef, values []any, err error) []PointResult {
+	results := make([]PointResult, len(refs))
+	for i, r := range refs {
+		results[i] = PointResult{
+			Name:    r.name(),
+			Table:   r.point.Table.String(),
+			Address: r.point.Address,
+			Type:    r.point.Type.String(),
+			Value:   values[i],
+			Unit:    r.point.Unit,
+		}
+		if values[i] == nil {
+			results[i].Error = "read failed"
+			if err != nil {
+				results[i].Error = err.Error()
+			}
+		}
+	}
+	return results
+}
+
+// outputPointValues writes point values in the output format.
+func outputPointValues(out io.Writer, title string, results []PointResult) error {
+	switch outputFmt {
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+
+	case "csv":
+		w := csv.NewWriter(out)
+		w.Write([]string{"name", "table", "address", "type", "value", "unit", "error"})
+		for _, r := range results {
+			value := ""
+			if r.Value != nil {
+				value = fmt.Sprint(r.Value)
+			}
+			w.Write([]string{r.Name, r.Table, strconv.Itoa(int(r.Address)), r.Type, value, r.Unit, r.Error})
+		}
+		w.Flush()
+		return w.Error()
+
+	case "raw":
+		for _, r := range results {
+			fmt.Fprintln(out, r.Value)
+		}
+		return nil
+
+	default:
+		if out == os.Stdout {
+			title = color(colorBold, title)
+		}
+		fmt.Fprintf(out, "\n%s (%d points)\n", title, len(results))
+		fmt.Fprintln(out, strings.Repeat("-", 60))
+
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tTABLE\tADDRESS\tTYPE\tVALUE\tUNIT")
+		fmt.Fprintln(w, "----\t-----\t-------\t----\t-----\t----")
+		for _, r := range results {
+			value := fmt.Sprint(r.Value)
+			if r.Error != "" {
+				value = "ERROR: " + r.Error
+			}
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\n", r.Name, r.Table, r.Address, r.Type, value, r.Unit)
+		}
+		w.Flush()
+		fmt.Fprintln(out)
+		return nil
+	}
+}
+
+// parsePointValue parses a value to write to a point: a boolean for bool
+// points, the text for strings, a number for scaled and float points and
+// an integer otherwise.
+func parsePointValue(pt *profile.Point, s string) (any, error) {
+	scaled := (pt.Scale != 0 && pt.Scale != 1) || pt.Offset != 0
+	switch {
+	case pt.Type == modbus.TypeBool:
+		return parseBoolValue(s)
+	case pt.Type == modbus.TypeString:
+		return s, nil
+	case scaled || pt.Type == modbus.TypeFloat32 || pt.Type == modbus.TypeFloat64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number: %s", s)
+		}
+		return f, nil
+	}
+	if n, err := strconv.ParseInt(s, 0, 64); err == nil {
+		return n, nil
+	}
+	n, err := strconv.ParseUint(s, 0, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid integer: %s", s)
+	}
+	return n, nil
+}
@@ -17,6 +17,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/edgeo-scada/modbus"
 	"github.com/spf13/cobra"
@@ -26,6 +27,7 @@ var (
 	readAddr   uint16
 	readCount  uint16
 	readFormat string
+	readFile   uint16
 )
 
 var readCmd = &cobra.Command{
@@ -70,10 +72,16 @@ Supported formats for -f/--format flag:
   uint32  - Unsigned 32-bit integer (2 registers)
   int32   - Signed 32-bit integer (2 registers)
   float32 - 32-bit floating point (2 registers)
+  uint64  - Unsigned 64-bit integer (4 registers)
+  int64   - Signed 64-bit integer (4 registers)
   float64 - 64-bit floating point (4 registers)
-  string  - ASCII string`,
+  string  - ASCII string
+
+Multi-register values follow --order (ABCD, CDAB, BADC, DCBA or one of the
+eight 64-bit orders such as GHEFCDAB), or --byte-order and --word-order.`,
 	Example: `  modbuscli read holding-registers -a 0 -c 10 -H 192.168.1.100
   modbuscli r hr -a 100 -c 4 -f float32
+  modbuscli r hr -a 100 -c 4 -f float32 --order CDAB
   modbuscli r hr -a 0 -c 20 -f string`,
 	RunE: runReadHoldingRegisters,
 }
@@ -91,29 +99,71 @@ Supported formats for -f/--format flag:
   uint32  - Unsigned 32-bit integer (2 registers)
   int32   - Signed 32-bit integer (2 registers)
   float32 - 32-bit floating point (2 registers)
+  uint64  - Unsigned 64-bit integer (4 registers)
+  int64   - Signed 64-bit integer (4 registers)
   float64 - 64-bit floating point (4 registers)
-  string  - ASCII string`,
+  string  - ASCII string
+
+Multi-register values follow --order (ABCD, CDAB, BADC, DCBA or one of the
+eight 64-bit orders such as GHEFCDAB), or --byte-order and --word-order.`,
 	Example: `  modbuscli read input-registers -a 0 -c 10 -H 192.168.1.100
   modbuscli r ir -a 100 -c 4 -f int32`,
 	RunE: runReadInputRegisters,
 }
 
+// Read file record (FC20)
+var readFileCmd = &cobra.Command{
+	Use:     "file",
+	Aliases: []string{"file-record"},
+	Short:   "Read file record (FC20)",
+	Long: `Read registers from a file record using function code 20.
+
+--file selects the file number; -a is the first record number (0-9999)
+and -c the number of registers to read.`,
+	Example: `  modbuscli read file --file 4 -a 1 -c 2 -H 192.168.1.100
+  modbuscli r file --file 1 -a 0 -c 48 -f uint32`,
+	RunE: runReadFile,
+}
+
+// Read named points of device profiles
+var readPointCmd = &cobra.Command{
+	Use:     "point NAME...",
+	Aliases: []string{"points", "p"},
+	Short:   "Read points of a device profile",
+	Long: `Read named points of the device profiles loaded with --profile.
+
+Points are named profile.point; the name of a profile alone reads all of its
+readable points. The reads of the points of a profile are merged into as
+few requests as possible, and values are decoded and scaled as described
+in the profile.`,
+	Example: `  modbuscli read point inverter.ac_power -P inverter.yaml -H 192.168.1.100
+  modbuscli r p inverter.ac_power inverter.dc_voltage -P inverter.yaml
+  modbuscli r p inverter meter -P inverter.yaml -P meter.csv -o json`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runReadPoint,
+}
+
 func init() {
 	// Add subcommands
 	readCmd.AddCommand(readCoilsCmd)
 	readCmd.AddCommand(readDiscreteInputsCmd)
 	readCmd.AddCommand(readHoldingRegistersCmd)
 	readCmd.AddCommand(readInputRegistersCmd)
+	readCmd.AddCommand(readFileCmd)
+	readCmd.AddCommand(readPointCmd)
 
 	// Common flags for all read commands
-	for _, cmd := range []*cobra.Command{readCoilsCmd, readDiscreteInputsCmd, readHoldingRegistersCmd, readInputRegistersCmd} {
+	for _, cmd := range []*cobra.Command{readCoilsCmd, readDiscreteInputsCmd, readHoldingRegistersCmd, readInputRegistersCmd, readFileCmd} {
 		cmd.Flags().Uint16VarP(&readAddr, "address", "a", 0, "Starting address")
 		cmd.Flags().Uint16VarP(&readCount, "count", "c", 1, "Number of items to read")
 	}
 
 	// Format flag only for register commands
-	readHoldingRegistersCmd.Flags().StringVarP(&readFormat, "format", "f", "uint16", "Data format: uint16, int16, uint32, int32, float32, float64, string")
-	readInputRegistersCmd.Flags().StringVarP(&readFormat, "format", "f", "uint16", "Data format: uint16, int16, uint32, int32, float32, float64, string")
+	readHoldingRegistersCmd.Flags().StringVarP(&readFormat, "format", "f", "uint16", "Data format: uint16, int16, uint32, int32, float32, uint64, int64, float64, string")
+	readInputRegistersCmd.Flags().StringVarP(&readFormat, "format", "f", "uint16", "Data format: uint16, int16, uint32, int32, float32, uint64, int64, float64, string")
+	readFileCmd.Flags().StringVarP(&readFormat, "format", "f", "uint16", "Data format: uint16, int16, uint32, int32, float32, uint64, int64, float64, string")
+
+	readFileCmd.Flags().Uint16Var(&readFile, "file", 1, "File number")
 }
 
 func runReadCoils(cmd *cobra.Command, args []string) error {
@@ -204,6 +254,60 @@ func runReadInputRegisters(cmd *cobra.Command, args []string) error {
 	return outputRegisterValues("Input Registers", readAddr, values, readFormat)
 }
 
+func runReadFile(cmd *cobra.Command, args []string) error {
+	client, err := createClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := client.Connect(ctx); err != nil {
+		return fmt.Errorf("connection failed: %w", err)
+	}
+
+	records, err := client.ReadFileRecord(ctx, []modbus.FileSubRequest{
+		{File: readFile, Record: readAddr, Length: readCount},
+	})
+	if err != nil {
+		return fmt.Errorf("read file record failed: %w", err)
+	}
+
+	return outputRegisterValues(fmt.Sprintf("File %d Records", readFile), readAddr, records[0].Data, readFormat)
+}
+
+func runReadPoint(cmd *cobra.Command, args []string) error {
+	refs, err := resolvePoints(args)
+	if err != nil {
+		return err
+	}
+
+	client, err := createClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := client.Connect(ctx); err != nil {
+		return fmt.Errorf("connection failed: %w", err)
+	}
+
+	values, err := readPoints(ctx, client, refs)
+	if err != nil {
+		if values == nil {
+			return fmt.Errorf("read points failed: %w", err)
+		}
+		outputWarning("Some points could not be read: %v", err)
+	}
+
+	return outputPointValues(os.Stdout, "Points", pointResults(refs, values, err))
+}
+
 func createClient() (*modbus.Client, error) {
 	client, err := modbus.NewClient(
 		getAddress(),
@@ -20,6 +20,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/edgeo-scada/modbus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -38,6 +39,11 @@ var (
 	noColor    bool
 	byteOrder  string
 	wordOrder  string
+	orderName  string
+
+	// order is the byte order of multi-register values, resolved from
+	// --order, --byte-order and --word-order.
+	order modbus.ByteOrder
 
 	logger *slog.Logger
 )
@@ -71,7 +77,7 @@ Examples:
   # Watch registers continuously
   edgeo-modbus watch hr -a 0 -c 5 -i 1s -H 192.168.1.100`,
 	Version: version,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		// Setup logger
 		level := slog.LevelInfo
 		if verbose {
@@ -80,6 +86,10 @@ Examples:
 		logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
 			Level: level,
 		}))
+
+		var err error
+		order, err = registerOrder()
+		return err
 	},
 }
 
@@ -104,6 +114,8 @@ func init() {
 	// Data format flags
 	rootCmd.PersistentFlags().StringVar(&byteOrder, "byte-order", "big", "Byte order: big, little")
 	rootCmd.PersistentFlags().StringVar(&wordOrder, "word-order", "big", "Word order for 32-bit values: big, little")
+	rootCmd.PersistentFlags().StringSliceVarP(&profileFiles, "profile", "P", nil, "Device profile (YAML or CSV) naming points as profile.point; repeatable")
+	rootCmd.PersistentFlags().StringVar(&orderName, "order", "", "Byte order of multi-register values: ABCD, CDAB, BADC, DCBA or a 64-bit order such as GHEFCDAB (overrides --byte-order and --word-order)")
 
 	// Bind to viper
 	viper.BindPFlag("host", rootCmd.PersistentFlags().Lookup("host"))
@@ -111,6 +123,7 @@ func init() {
 	viper.BindPFlag("unit", rootCmd.PersistentFlags().Lookup("unit"))
 	viper.BindPFlag("timeout", rootCmd.PersistentFlags().Lookup("timeout"))
 	viper.BindPFlag("output", rootCmd.PersistentFlags().Lookup("output"))
+	viper.BindPFlag("profile", rootCmd.PersistentFlags().Lookup("profile"))
 
 	// Add commands
 	rootCmd.AddCommand(readCmd)
@@ -121,6 +134,7 @@ func init() {
 	rootCmd.AddCommand(diagCmd)
 	rootCmd.AddCommand(infoCmd)
 	rootCmd.AddCommand(dumpCmd)
+	rootCmd.AddCommand(sunspecCmd)
 }
 
 func initConfig() {
@@ -151,3 +165,43 @@ func initConfig() {
 func getAddress() string {
 	return fmt.Sprintf("%s:%d", viper.GetString("host"), viper.GetInt("port"))
 }
+
+// registerOrder returns the byte order selected by --order or, without it,
+// by --byte-order and --word-order.
+func registerOrder() (modbus.ByteOrder, error) {
+	if orderName != "" {
+		return modbus.ParseByteOrder(orderName)
+	}
+
+	swapBytes, err := isLittle("byte-order", byteOrder)
+	if err != nil {
+		return 0, err
+	}
+	swapWords, err := isLittle("word-order", wordOrder)
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case swapBytes && swapWords:
+		return modbus.OrderDCBA, nil
+	case swapBytes:
+		return modbus.OrderBADC, nil
+	case swapWords:
+		return modbus.OrderCDAB, nil
+	default:
+		return modbus.OrderABCD, nil
+	}
+}
+
+// isLittle parses a big or little order flag.
+func isLittle(flag, value string) (bool, error) {
+	switch value {
+	case "big", "":
+		return false, nil
+	case "little":
+		return true, nil
+	default:
+		return false, fmt.Errorf("invalid --%s: %s (expected big or little)", flag, value)
+	}
+}
@@ -87,6 +87,9 @@ type ScanResult struct {
 	Responsive  bool          `json:"responsive"`
 	Error       string        `json:"error,omitempty"`
 	ServerID    string        `json:"server_id,omitempty"`
+	VendorName  string        `json:"vendor_name,omitempty"`
+	ProductCode string        `json:"product_code,omitempty"`
+	Revision    string        `json:"revision,omitempty"`
 	Latency     time.Duration `json:"latency_ms,omitempty"`
 	RegisterQty int           `json:"register_qty,omitempty"`
 }
@@ -182,8 +185,15 @@ func probeUnit(addr string, unitID uint8) ScanResult {
 		}
 	}
 
-	// Try to get server ID if responsive
+	// Try to identify the device if responsive
 	if result.Responsive {
+		ident, err := client.ReadDeviceIdentification(ctx, modbus.ReadDeviceIDBasic, modbus.DeviceIDVendorName)
+		if err == nil {
+			result.VendorName = ident.VendorName()
+			result.ProductCode = ident.ProductCode()
+			result.Revision = ident.MajorMinorRevision()
+		}
+
 		serverID, err := client.ReportServerID(ctx)
 		if err == nil && len(serverID) > 0 {
 			result.ServerID = strings.TrimRight(string(serverID), "\x00")
@@ -395,20 +405,17 @@ func outputScanResults(title string, results []ScanResult) error {
 	}
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "ADDRESS\tUNIT ID\tLATENCY\tSERVER ID\tSTATUS")
-	fmt.Fprintln(w, "-------\t-------\t-------\t---------\t------")
+	fmt.Fprintln(w, "ADDRESS\tUNIT ID\tLATENCY\tVENDOR\tPRODUCT\tREVISION\tSERVER ID\tSTATUS")
+	fmt.Fprintln(w, "-------\t-------\t-------\t------\t-------\t--------\t---------\t------")
 
 	for _, r := range results {
 		latency := "-"
 		if r.Latency > 0 {
 			latency = fmt.Sprintf("%dms", r.Latency.Milliseconds())
 		}
-		serverID := r.ServerID
-		if serverID == "" {
-			serverID = "-"
-		}
 		status := color(colorGreen, "ONLINE")
-		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n", r.Address, r.UnitID, latency, serverID, status)
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\n", r.Address, r.UnitID, latency,
+			orDash(r.VendorName), orDash(r.ProductCode), orDash(r.Revision), orDash(r.ServerID), status)
 	}
 	w.Flush()
 
@@ -416,6 +423,14 @@ func outputScanResults(title string, results []ScanResult) error {
 	return nil
 }
 
+// orDash returns s, or "-" if s is empty.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
 func outputRegisterScanResults(results []ScanResult) error {
 	if outputFmt == "json" {
 		enc := json.NewEncoder(os.Stdout)
@@ -0,0 +1,187 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/edgeo-scada/modbus/sunspec"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sunspecBase uint16
+	sunspecList bool
+)
+
+var sunspecCmd = &cobra.Command{
+	Use:   "sunspec",
+	Short: "Discover and decode SunSpec models",
+	Long: `Discover the SunSpec models of a device and print their values.
+
+The "SunS" marker is searched at holding registers 40000, 0 and 50000 unless
+--base is given, then the chain of models that follows it is read. Common
+(1), inverter (101-103), multiple MPPT (160) and meter (201-204) models are
+decoded with their scale factors; other models are listed only.`,
+	Example: `  edgeo-modbus sunspec -H 192.168.1.100
+  edgeo-modbus sunspec -H 192.168.1.100 --list
+  edgeo-modbus sunspec -H 192.168.1.100 --base 50000 -o json`,
+	RunE: runSunSpec,
+}
+
+func init() {
+	sunspecCmd.Flags().Uint16Var(&sunspecBase, "base", 0, "Base address of the SunS marker (default: search 40000, 0, 50000)")
+	sunspecCmd.Flags().BoolVarP(&sunspecList, "list", "l", false, "List the models without decoding them")
+}
+
+// SunSpecModel is the JSON output of a model.
+type SunSpecModel struct {
+	ID      uint16           `json:"id"`
+	Name    string           `json:"name,omitempty"`
+	Address uint16           `json:"address"`
+	Length  uint16           `json:"length"`
+	Points  []SunSpecPoint   `json:"points,omitempty"`
+	Blocks  [][]SunSpecPoint `json:"blocks,omitempty"`
+}
+
+// SunSpecPoint is the JSON output of a point.
+type SunSpecPoint struct {
+	Name  string `json:"name"`
+	Value any    `json:"value"`
+	Units string `json:"units,omitempty"`
+}
+
+func runSunSpec(cmd *cobra.Command, args []string) error {
+	client, err := createClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := client.Connect(ctx); err != nil {
+		return fmt.Errorf("connection failed: %w", err)
+	}
+
+	var opts []sunspec.Option
+	if cmd.Flags().Changed("base") {
+		opts = append(opts, sunspec.WithBaseAddresses(sunspecBase))
+	}
+	dev, err := sunspec.Discover(ctx, client, opts...)
+	if dev == nil {
+		return fmt.Errorf("SunSpec discovery failed: %w", err)
+	}
+	if err != nil {
+		outputWarning("Model chain incomplete: %v", err)
+	}
+
+	models := make([]SunSpecModel, len(dev.Models))
+	for i := range dev.Models {
+		m := &dev.Models[i]
+		models[i] = SunSpecModel{ID: m.ID, Address: m.Address, Length: m.Length}
+		if def, ok := sunspec.Lookup(m.ID); ok {
+			models[i].Name = def.Name
+		}
+		if sunspecList {
+			continue
+		}
+		d, err := m.Decode()
+		if err != nil {
+			if models[i].Name != "" {
+				outputWarning("Model %d: %v", m.ID, err)
+			}
+			continue
+		}
+		models[i].Points = sunspecPoints(d.Points)
+		for _, block := range d.Blocks {
+			models[i].Blocks = append(models[i].Blocks, sunspecPoints(block))
+		}
+	}
+
+	if outputFmt == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			Base   uint16         `json:"base"`
+			Models []SunSpecModel `json:"models"`
+		}{dev.Base, models})
+	}
+	return outputSunSpecTable(dev.Base, models)
+}
+
+// sunspecPoints converts decoded values for output, showing bitfields in
+// hexadecimal.
+func sunspecPoints(values []sunspec.Value) []SunSpecPoint {
+	points := make([]SunSpecPoint, len(values))
+	for i, v := range values {
+		points[i] = SunSpecPoint{Name: v.Name, Value: v.Value, Units: v.Units}
+		if v.Value != nil && (v.Type == sunspec.TypeBitfield16 || v.Type == sunspec.TypeBitfield32) {
+			points[i].Value = fmt.Sprintf("0x%X", v.Value)
+		}
+	}
+	return points
+}
+
+func outputSunSpecTable(base uint16, models []SunSpecModel) error {
+	fmt.Printf("\n%s (Base Address %d, %d models)\n", color(colorBold, "SunSpec Device"), base, len(models))
+	fmt.Println(strings.Repeat("-", 60))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MODEL\tNAME\tADDRESS\tLENGTH")
+	fmt.Fprintln(w, "-----\t----\t-------\t------")
+	for _, m := range models {
+		name := m.Name
+		if name == "" {
+			name = "-"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%d\t%d\n", m.ID, name, m.Address, m.Length)
+	}
+	w.Flush()
+
+	for _, m := range models {
+		if m.Points == nil {
+			continue
+		}
+		fmt.Printf("\n%s\n", color(colorBold, fmt.Sprintf("Model %d: %s", m.ID, m.Name)))
+		outputSunSpecPoints(m.Points)
+		for i, block := range m.Blocks {
+			fmt.Printf("\n  Block %d\n", i+1)
+			outputSunSpecPoints(block)
+		}
+	}
+	fmt.Println()
+	return nil
+}
+
+func outputSunSpecPoints(points []SunSpecPoint) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, p := range points {
+		if p.Value == nil {
+			if verbose {
+				fmt.Fprintf(w, "  %s\t%s\t\n", p.Name, color(colorYellow, "n/a"))
+			}
+			continue
+		}
+		fmt.Fprintf(w, "  %s\t%v\t%s\n", p.Name, p.Value, p.Units)
+	}
+	w.Flush()
+}
@@ -16,10 +16,12 @@ package main
 
 import (
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"text/tabwriter"
@@ -36,6 +38,8 @@ var (
 	watchClearTerm   bool
 	watchTimestamp   bool
 	watchLogFile     string
+	watchDeadband    float64
+	watchDeadbandPct float64
 	watchAlertHigh   float64
 	watchAlertLow    float64
 	watchAlertEnable bool
@@ -55,7 +59,7 @@ Supports:
 Features:
   - Change detection and highlighting
   - Alert thresholds
-  - Logging to file
+  - Logging to file by exception, with quality and deadband
   - Timestamp display`,
 	Example: `  # Watch 5 holding registers every second
   modbuscli watch hr -a 0 -c 5 -i 1s -H 192.168.1.100
@@ -66,8 +70,17 @@ Features:
   # Watch and log to file
   modbuscli watch hr -a 0 -c 10 -i 2s --log data.csv
 
+  # Log only changes of more than 0.5 or 2% of the last logged value
+  modbuscli watch point inverter -P inverter.yaml --log data.csv --deadband 0.5 --deadband-percent 2
+
+  # Watch two float32 values stored low word first
+  modbuscli watch hr -a 100 -c 4 -f float32 --order CDAB
+
   # Watch coils with change highlighting
-  modbuscli watch c -a 0 -c 8 -i 1s --diff`,
+  modbuscli watch c -a 0 -c 8 -i 1s --diff
+
+  # Watch named points of a device profile
+  modbuscli watch point inverter.ac_power inverter.dc_voltage -P inverter.yaml`,
 }
 
 var watchHoldingRegistersCmd = &cobra.Command{
@@ -98,25 +111,46 @@ var watchDiscreteInputsCmd = &cobra.Command{
 	RunE:    runWatchDiscreteInputs,
 }
 
+var watchPointCmd = &cobra.Command{
+	Use:     "point NAME...",
+	Aliases: []string{"points", "p"},
+	Short:   "Watch points of a device profile",
+	Long: `Watch named points of the device profiles loaded with --profile.
+
+Points are named profile.point; the name of a profile alone watches all of
+its readable points.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runWatchPoint,
+}
+
 func init() {
 	watchCmd.AddCommand(watchHoldingRegistersCmd)
 	watchCmd.AddCommand(watchInputRegistersCmd)
 	watchCmd.AddCommand(watchCoilsCmd)
 	watchCmd.AddCommand(watchDiscreteInputsCmd)
+	watchCmd.AddCommand(watchPointCmd)
 
 	for _, cmd := range []*cobra.Command{watchHoldingRegistersCmd, watchInputRegistersCmd, watchCoilsCmd, watchDiscreteInputsCmd} {
 		cmd.Flags().Uint16VarP(&readAddr, "address", "a", 0, "Starting address")
 		cmd.Flags().Uint16VarP(&readCount, "count", "c", 1, "Number of items to read")
+	}
+
+	for _, cmd := range []*cobra.Command{watchHoldingRegistersCmd, watchInputRegistersCmd, watchCoilsCmd, watchDiscreteInputsCmd, watchPointCmd} {
 		cmd.Flags().DurationVarP(&watchInterval, "interval", "i", 1*time.Second, "Poll interval")
 		cmd.Flags().IntVarP(&watchCount, "iterations", "n", 0, "Number of iterations (0 = infinite)")
 		cmd.Flags().BoolVar(&watchShowDiff, "diff", false, "Highlight changed values")
 		cmd.Flags().BoolVar(&watchClearTerm, "clear", true, "Clear terminal between updates")
 		cmd.Flags().BoolVar(&watchTimestamp, "timestamp", true, "Show timestamps")
-		cmd.Flags().StringVar(&watchLogFile, "log", "", "Log values to file (CSV format)")
+		cmd.Flags().StringVar(&watchLogFile, "log", "", "Log changes of values and quality to file (CSV format)")
+		cmd.Flags().Float64Var(&watchDeadband, "deadband", 0, "Absolute change below which values are not logged")
+		cmd.Flags().Float64Var(&watchDeadbandPct, "deadband-percent", 0, "Change in percent of the last logged value below which values are not logged")
 	}
 
 	for _, cmd := range []*cobra.Command{watchHoldingRegistersCmd, watchInputRegistersCmd} {
-		cmd.Flags().StringVarP(&readFormat, "format", "f", "uint16", "Data format")
+		cmd.Flags().StringVarP(&readFormat, "format", "f", "uint16", "Data format: uint16, int16, uint32, int32, float32, uint64, int64, float64")
+	}
+
+	for _, cmd := range []*cobra.Command{watchHoldingRegistersCmd, watchInputRegistersCmd, watchPointCmd} {
 		cmd.Flags().Float64Var(&watchAlertHigh, "alert-high", 0, "Alert when value exceeds this threshold")
 		cmd.Flags().Float64Var(&watchAlertLow, "alert-low", 0, "Alert when value falls below this threshold")
 		cmd.Flags().BoolVar(&watchAlertEnable, "alert", false, "Enable threshold alerts")
@@ -129,8 +163,10 @@ type WatchState struct {
 	cancel       context.CancelFunc
 	prevRegs     []uint16
 	prevCoils    []bool
+	prevPoints   []any
 	iteration    int
 	logFile      *os.File
+	logged       map[string]modbus.DataValue
 	startTime    time.Time
 	errorCount   int
 	successCount int
@@ -160,6 +196,14 @@ func runWatchDiscreteInputs(cmd *cobra.Command, args []string) error {
 	}, "Discrete Inputs")
 }
 
+func runWatchPoint(cmd *cobra.Command, args []string) error {
+	refs, err := resolvePoints(args)
+	if err != nil {
+		return err
+	}
+	return watchPoints(refs)
+}
+
 func watchRegisters(readFunc func(context.Context, *modbus.Client) ([]uint16, error), title string) error {
 	state, err := initWatchState()
 	if err != nil {
@@ -237,6 +281,46 @@ func watchBools(readFunc func(context.Context, *modbus.Client) ([]bool, error),
 	}
 }
 
+func watchPoints(refs []pointRef) error {
+	state, err := initWatchState()
+	if err != nil {
+		return err
+	}
+	defer state.cleanup()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	if err := state.readAndDisplayPoints(refs); err != nil {
+		outputWarning("Initial read failed: %v", err)
+	}
+
+	for {
+		select {
+		case <-sigCh:
+			fmt.Println("\n\nStopping watch...")
+			state.printSummary()
+			return nil
+		case <-ticker.C:
+			if err := state.readAndDisplayPoints(refs); err != nil {
+				state.errorCount++
+				if verbose {
+					outputWarning("Read failed: %v", err)
+				}
+			}
+			if watchCount > 0 && state.iteration >= watchCount {
+				state.printSummary()
+				return nil
+			}
+		case <-state.ctx.Done():
+			return state.ctx.Err()
+		}
+	}
+}
+
 func initWatchState() (*WatchState, error) {
 	client, err := createClient()
 	if err != nil {
@@ -288,6 +372,7 @@ func (s *WatchState) readAndDisplayRegisters(readFunc func(context.Context, *mod
 
 	values, err := readFunc(readCtx, s.client)
 	if err != nil {
+		s.logValues(time.Now(), registerLogNames(), nil, err)
 		return err
 	}
 
@@ -296,6 +381,10 @@ func (s *WatchState) readAndDisplayRegisters(readFunc func(context.Context, *mod
 
 	now := time.Now()
 
+	if s.logFile != nil {
+		s.logRegisters(now, values)
+	}
+
 	if outputFmt == "json" {
 		return s.outputWatchJSON(values, now)
 	}
@@ -323,21 +412,24 @@ func (s *WatchState) readAndDisplayRegisters(readFunc func(context.Context, *mod
 	fmt.Fprintln(w, "ADDR\tVALUE\tHEX\tCHANGE")
 	fmt.Fprintln(w, "----\t-----\t---\t------")
 
-	for i, v := range values {
+	n := registerWidth(readFormat)
+	for i := 0; i+n <= len(values); i += n {
 		addr := readAddr + uint16(i)
+		value, raw := decodeRegisters(values[i:i+n], readFormat)
+		fv := numericValue(value)
 		change := ""
 
-		if watchShowDiff && s.prevRegs != nil && i < len(s.prevRegs) {
-			diff := int(v) - int(s.prevRegs[i])
-			if diff > 0 {
-				change = color(colorGreen, fmt.Sprintf("+%d", diff))
-			} else if diff < 0 {
-				change = color(colorRed, fmt.Sprintf("%d", diff))
+		if watchShowDiff && s.prevRegs != nil && i+n <= len(s.prevRegs) {
+			prev, _ := decodeRegisters(s.prevRegs[i:i+n], readFormat)
+			diff := strconv.FormatFloat(fv-numericValue(prev), 'f', -1, 64)
+			if fv > numericValue(prev) {
+				change = color(colorGreen, "+"+diff)
+			} else if fv < numericValue(prev) {
+				change = color(colorRed, diff)
 			}
 		}
 
 		if watchAlertEnable {
-			fv := float64(v)
 			if watchAlertHigh != 0 && fv > watchAlertHigh {
 				change += " " + color(colorRed+colorBold, "HIGH!")
 			}
@@ -346,14 +438,14 @@ func (s *WatchState) readAndDisplayRegisters(readFunc func(context.Context, *mod
 			}
 		}
 
-		fmt.Fprintf(w, "%d\t%d\t0x%04X\t%s\n", addr, v, v, change)
+		addrStr := strconv.Itoa(int(addr))
+		if n > 1 {
+			addrStr = fmt.Sprintf("%d-%d", addr, addr+uint16(n-1))
+		}
+		fmt.Fprintf(w, "%s\t%v\t0x%0*X\t%s\n", addrStr, value, 4*n, raw, change)
 	}
 	w.Flush()
 
-	if s.logFile != nil {
-		s.logToFile(now, values)
-	}
-
 	s.prevRegs = values
 	return nil
 }
@@ -364,6 +456,7 @@ func (s *WatchState) readAndDisplayBools(readFunc func(context.Context, *modbus.
 
 	values, err := readFunc(readCtx, s.client)
 	if err != nil {
+		s.logValues(time.Now(), boolLogNames(), nil, err)
 		return err
 	}
 
@@ -372,6 +465,14 @@ func (s *WatchState) readAndDisplayBools(readFunc func(context.Context, *modbus.
 
 	now := time.Now()
 
+	if s.logFile != nil {
+		logged := make([]any, len(values))
+		for i, v := range values {
+			logged[i] = v
+		}
+		s.logValues(now, boolLogNames(), logged, nil)
+	}
+
 	if outputFmt == "json" {
 		return s.outputWatchBoolJSON(values, now)
 	}
@@ -427,22 +528,133 @@ func (s *WatchState) readAndDisplayBools(readFunc func(context.Context, *modbus.
 	return nil
 }
 
+func (s *WatchState) readAndDisplayPoints(refs []pointRef) error {
+	readCtx, cancel := context.WithTimeout(s.ctx, timeout)
+	defer cancel()
+
+	values, err := readPoints(readCtx, s.client, refs)
+	now := time.Now()
+	names := make([]string, len(refs))
+	for i, r := range refs {
+		names[i] = r.name()
+	}
+	s.logValues(now, names, values, err)
+	if values == nil {
+		return err
+	}
+
+	s.iteration++
+	if err == nil {
+		s.successCount++
+	} else {
+		s.errorCount++
+	}
+
+	if outputFmt == "json" {
+		return s.outputWatchPointsJSON(pointResults(refs, values, err), now)
+	}
+
+	if watchClearTerm && s.iteration > 1 {
+		fmt.Print("\033[H\033[2J")
+	}
+
+	fmt.Printf("%s - Watching %d points\n", color(colorBold, "MODBUS WATCH"), len(refs))
+	fmt.Printf("Host: %s | Interval: %s\n", getAddress(), watchInterval)
+	if watchTimestamp {
+		fmt.Printf("Time: %s | Iteration: %d", now.Format("15:04:05.000"), s.iteration)
+		if watchCount > 0 {
+			fmt.Printf("/%d", watchCount)
+		}
+		fmt.Println()
+	}
+	fmt.Println(strings.Repeat("-", 60))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tVALUE\tUNIT\tCHANGE")
+	fmt.Fprintln(w, "----\t-----\t----\t------")
+
+	for i, r := range refs {
+		v := values[i]
+		if v == nil {
+			fmt.Fprintf(w, "%s\t%s\t%s\t\n", r.name(), color(colorRed, "ERROR"), r.point.Unit)
+			continue
+		}
+
+		change := ""
+		if prev := s.prevPoint(i); watchShowDiff && prev != nil && prev != v {
+			switch v.(type) {
+			case bool, string:
+				change = color(colorYellow, fmt.Sprintf("%v->%v", prev, v))
+			default:
+				fv, pv := numericValue(v), numericValue(prev)
+				diff := strconv.FormatFloat(fv-pv, 'f', -1, 64)
+				if fv > pv {
+					change = color(colorGreen, "+"+diff)
+				} else if fv < pv {
+					change = color(colorRed, diff)
+				}
+			}
+		}
+
+		if watchAlertEnable {
+			fv := numericValue(v)
+			if watchAlertHigh != 0 && fv > watchAlertHigh {
+				change += " " + color(colorRed+colorBold, "HIGH!")
+			}
+			if watchAlertLow != 0 && fv < watchAlertLow {
+				change += " " + color(colorYellow+colorBold, "LOW!")
+			}
+		}
+
+		fmt.Fprintf(w, "%s\t%v\t%s\t%s\n", r.name(), v, r.point.Unit, change)
+	}
+	w.Flush()
+
+	s.prevPoints = values
+	return nil
+}
+
+// prevPoint returns the previous value of point i, or nil.
+func (s *WatchState) prevPoint(i int) any {
+	if i < len(s.prevPoints) {
+		return s.prevPoints[i]
+	}
+	return nil
+}
+
+func (s *WatchState) outputWatchPointsJSON(results []PointResult, ts time.Time) error {
+	data := struct {
+		Timestamp string        `json:"timestamp"`
+		Iteration int           `json:"iteration"`
+		Points    []PointResult `json:"points"`
+	}{
+		Timestamp: ts.Format(time.RFC3339Nano),
+		Iteration: s.iteration,
+		Points:    results,
+	}
+	enc := json.NewEncoder(os.Stdout)
+	return enc.Encode(data)
+}
+
 func (s *WatchState) outputWatchJSON(values []uint16, ts time.Time) error {
 	data := struct {
 		Timestamp string   `json:"timestamp"`
 		Iteration int      `json:"iteration"`
 		Address   uint16   `json:"start_address"`
-		Values    []uint16 `json:"values"`
+		Format    string   `json:"format"`
+		Values    []any    `json:"values"`
 		ValuesHex []string `json:"values_hex"`
 	}{
 		Timestamp: ts.Format(time.RFC3339Nano),
 		Iteration: s.iteration,
 		Address:   readAddr,
-		Values:    values,
-		ValuesHex: make([]string, len(values)),
+		Format:    readFormat,
 	}
-	for i, v := range values {
-		data.ValuesHex[i] = fmt.Sprintf("0x%04X", v)
+	n := registerWidth(readFormat)
+	for i := 0; i+n <= len(values); i += n {
+		value, raw := decodeRegisters(values[i:i+n], readFormat)
+		data.Values = append(data.Values, value)
+		data.ValuesHex = append(data.ValuesHex, fmt.Sprintf("0x%0*X", 4*n, raw))
 	}
 	enc := json.NewEncoder(os.Stdout)
 	return enc.Encode(data)
@@ -464,20 +676,74 @@ func (s *WatchState) outputWatchBoolJSON(values []bool, ts time.Time) error {
 	return enc.Encode(data)
 }
 
-func (s *WatchState) logToFile(ts time.Time, values []uint16) {
-	if s.iteration == 1 {
-		header := "timestamp"
-		for i := uint16(0); i < readCount; i++ {
-			header += fmt.Sprintf(",addr_%d", readAddr+i)
-		}
-		fmt.Fprintln(s.logFile, header)
+// registerLogNames returns the log names of the values of the watched
+// registers: their first address.
+func registerLogNames() []string {
+	var names []string
+	n := registerWidth(readFormat)
+	for i := 0; i+n <= int(readCount); i += n {
+		names = append(names, strconv.Itoa(int(readAddr)+i))
+	}
+	return names
+}
+
+// boolLogNames returns the log names of the watched coils or discrete
+// inputs: their address.
+func boolLogNames() []string {
+	names := make([]string, readCount)
+	for i := range names {
+		names[i] = strconv.Itoa(int(readAddr) + i)
+	}
+	return names
+}
+
+// logRegisters logs the values of the watched registers.
+func (s *WatchState) logRegisters(ts time.Time, regs []uint16) {
+	var values []any
+	n := registerWidth(readFormat)
+	for i := 0; i+n <= len(regs); i += n {
+		value, _ := decodeRegisters(regs[i:i+n], readFormat)
+		values = append(values, value)
 	}
+	s.logValues(ts, registerLogNames(), values, nil)
+}
+
+// logValues logs the values of a read by exception: a value is logged when
+// its quality changes or when it changes beyond the deadband. Values that
+// could not be read are nil; they are logged with the last logged value and
+// the quality of err.
+func (s *WatchState) logValues(ts time.Time, names []string, values []any, err error) {
+	if s.logFile == nil {
+		return
+	}
+
+	w := csv.NewWriter(s.logFile)
+	if s.logged == nil {
+		s.logged = make(map[string]modbus.DataValue, len(names))
+		w.Write([]string{"timestamp", "point", "value", "quality", "reason"})
+	}
+
+	deadband := modbus.Deadband{Absolute: watchDeadband, Percent: watchDeadbandPct}
+	for i, name := range names {
+		prev := s.logged[name]
+		var dv modbus.DataValue
+		if i < len(values) && values[i] != nil {
+			dv = modbus.NewDataValue(values[i], nil, ts)
+		} else {
+			dv = modbus.NewDataValue(prev.Value, err, ts)
+		}
+		if !deadband.Report(prev, dv) {
+			continue
+		}
+		s.logged[name] = dv
 
-	line := ts.Format(time.RFC3339)
-	for _, v := range values {
-		line += fmt.Sprintf(",%d", v)
+		value := ""
+		if dv.Value != nil {
+			value = fmt.Sprint(dv.Value)
+		}
+		w.Write([]string{dv.Timestamp.Format(time.RFC3339Nano), name, value, dv.Quality.String(), dv.Reason.String()})
 	}
-	fmt.Fprintln(s.logFile, line)
+	w.Flush()
 }
 
 func (s *WatchState) printSummary() {
@@ -493,3 +759,26 @@ func (s *WatchState) printSummary() {
 		fmt.Printf("Avg Rate:    %.2f reads/sec\n", float64(s.iteration)/duration.Seconds())
 	}
 }
+
+// numericValue converts a value returned by decodeRegisters to float64.
+func numericValue(value any) float64 {
+	switch v := value.(type) {
+	case uint16:
+		return float64(v)
+	case int16:
+		return float64(v)
+	case uint32:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return 0
+}
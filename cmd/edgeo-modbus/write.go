@@ -20,12 +20,19 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/edgeo-scada/modbus"
 	"github.com/spf13/cobra"
 )
 
 var (
 	writeAddr   uint16
 	writeValues []string
+
+	writeAndMask string
+	writeOrMask  string
+	writeBit     uint
+	writeFile    uint16
+	writeFormat  string
 )
 
 var writeCmd = &cobra.Command{
@@ -84,26 +91,108 @@ var writeRegistersCmd = &cobra.Command{
 	Long: `Write multiple holding registers to the Modbus device using function code 16.
 
 Values can be comma-separated or space-separated.
-Each value can be decimal, hexadecimal (0x prefix), or binary (0b prefix).`,
+Each value can be decimal, hexadecimal (0x prefix), or binary (0b prefix).
+
+With -f/--format, values are 16, 32 or 64-bit integers or floats (uint16,
+int16, uint32, int32, float32, uint64, int64, float64) written over 1, 2 or 4
+registers in the byte order selected by --order, or --byte-order and
+--word-order.`,
 	Example: `  modbuscli write registers -a 0 -V 100,200,300 -H 192.168.1.100
   modbuscli w rs -a 100 -V "0x1234 0x5678"
-  modbuscli w rs -a 50 -V 1000,2000,3000,4000`,
+  modbuscli w rs -a 50 -V 1000,2000,3000,4000
+  modbuscli w rs -a 10 -f float32 --order CDAB -V 1480.5,-2.25`,
 	RunE: runWriteRegisters,
 }
 
+// Write file record (FC21)
+var writeFileCmd = &cobra.Command{
+	Use:     "file",
+	Aliases: []string{"file-record"},
+	Short:   "Write file record (FC21)",
+	Long: `Write registers to a file record using function code 21.
+
+--file selects the file number and -a the first record number (0-9999).
+Each value can be decimal, hexadecimal (0x prefix), or binary (0b prefix).`,
+	Example: `  modbuscli write file --file 4 -a 7 -V 0x06AF,0x04BE,0x100D -H 192.168.1.100
+  modbuscli w file --file 1 -a 0 -V 100,200`,
+	RunE: runWriteFile,
+}
+
+// Mask write register (FC22)
+var writeMaskCmd = &cobra.Command{
+	Use:     "mask",
+	Aliases: []string{"m"},
+	Short:   "Mask write register (FC22)",
+	Long: `Modify bits of a holding register using function code 22.
+
+The register is set to (current AND and-mask) OR (or-mask AND NOT and-mask).
+The device applies the change atomically, so other masters writing the same
+register are not overwritten.`,
+	Example: `  modbuscli write mask -a 10 --and 0xFFF0 --or 0x0005 -H 192.168.1.100
+  modbuscli w m -a 10 --and 0x00F2 --or 0x0025`,
+	RunE: runWriteMask,
+}
+
+// Set or clear a single register bit (FC22)
+var writeBitCmd = &cobra.Command{
+	Use:     "bit",
+	Aliases: []string{"b"},
+	Short:   "Set or clear a register bit (FC22)",
+	Long: `Set or clear a single bit of a holding register using function code 22.
+
+Bits are numbered 0 (least significant) to 15.
+Value can be: 1, 0, true, false, on, off`,
+	Example: `  modbuscli write bit -a 10 -b 3 -V 1 -H 192.168.1.100
+  modbuscli w b -a 10 -b 15 -V off`,
+	RunE: runWriteBit,
+}
+
+// Write a named point of a device profile
+var writePointCmd = &cobra.Command{
+	Use:     "point NAME VALUE",
+	Aliases: []string{"p"},
+	Short:   "Write a point of a device profile",
+	Long: `Write a named point of the device profiles loaded with --profile.
+
+The point is named profile.point and must be writable. The value is encoded
+as described in the profile: true/false for bits and coils, text for
+strings, and a number in engineering units for scaled points.`,
+	Example: `  modbuscli write point inverter.power_limit 80 -P inverter.yaml -H 192.168.1.100
+  modbuscli w p pump.enable on -P pump.csv`,
+	Args: cobra.ExactArgs(2),
+	RunE: runWritePoint,
+}
+
 func init() {
 	// Add subcommands
 	writeCmd.AddCommand(writeCoilCmd)
 	writeCmd.AddCommand(writeCoilsCmd)
 	writeCmd.AddCommand(writeRegisterCmd)
 	writeCmd.AddCommand(writeRegistersCmd)
+	writeCmd.AddCommand(writeFileCmd)
+	writeCmd.AddCommand(writeMaskCmd)
+	writeCmd.AddCommand(writeBitCmd)
+	writeCmd.AddCommand(writePointCmd)
 
 	// Common flags
-	for _, cmd := range []*cobra.Command{writeCoilCmd, writeCoilsCmd, writeRegisterCmd, writeRegistersCmd} {
+	for _, cmd := range []*cobra.Command{writeCoilCmd, writeCoilsCmd, writeRegisterCmd, writeRegistersCmd, writeFileCmd, writeBitCmd} {
 		cmd.Flags().Uint16VarP(&writeAddr, "address", "a", 0, "Starting address")
 		cmd.Flags().StringSliceVarP(&writeValues, "values", "V", nil, "Values to write")
 		cmd.MarkFlagRequired("values")
 	}
+
+	writeFileCmd.Flags().Uint16Var(&writeFile, "file", 1, "File number")
+
+	for _, cmd := range []*cobra.Command{writeRegistersCmd, writeFileCmd} {
+		cmd.Flags().StringVarP(&writeFormat, "format", "f", "uint16", "Value format: uint16, int16, uint32, int32, float32, uint64, int64, float64")
+	}
+
+	writeMaskCmd.Flags().Uint16VarP(&writeAddr, "address", "a", 0, "Register address")
+	writeMaskCmd.Flags().StringVar(&writeAndMask, "and", "0xFFFF", "AND mask")
+	writeMaskCmd.Flags().StringVar(&writeOrMask, "or", "0x0000", "OR mask")
+
+	writeBitCmd.Flags().UintVarP(&writeBit, "bit", "b", 0, "Bit number (0-15)")
+	writeBitCmd.MarkFlagRequired("bit")
 }
 
 func runWriteCoil(cmd *cobra.Command, args []string) error {
@@ -200,7 +289,7 @@ func runWriteRegister(cmd *cobra.Command, args []string) error {
 }
 
 func runWriteRegisters(cmd *cobra.Command, args []string) error {
-	values, err := parseUint16Values(writeValues)
+	values, err := parseRegisterValues(writeValues, writeFormat)
 	if err != nil {
 		return fmt.Errorf("invalid register values: %w", err)
 	}
@@ -230,6 +319,128 @@ func runWriteRegisters(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func runWriteFile(cmd *cobra.Command, args []string) error {
+	values, err := parseRegisterValues(writeValues, writeFormat)
+	if err != nil {
+		return fmt.Errorf("invalid register values: %w", err)
+	}
+
+	if len(values) == 0 {
+		return fmt.Errorf("at least one value required")
+	}
+
+	client, err := createClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := client.Connect(ctx); err != nil {
+		return fmt.Errorf("connection failed: %w", err)
+	}
+
+	records := []modbus.FileRecord{{File: writeFile, Record: writeAddr, Data: values}}
+	if err := client.WriteFileRecord(ctx, records); err != nil {
+		return fmt.Errorf("write file record failed: %w", err)
+	}
+
+	outputSuccess("Wrote %d registers to file %d starting at record %d", len(values), writeFile, writeAddr)
+	return nil
+}
+
+func runWriteMask(cmd *cobra.Command, args []string) error {
+	andMask, err := parseUint16Value(writeAndMask)
+	if err != nil {
+		return fmt.Errorf("invalid AND mask: %w", err)
+	}
+	orMask, err := parseUint16Value(writeOrMask)
+	if err != nil {
+		return fmt.Errorf("invalid OR mask: %w", err)
+	}
+
+	return maskWriteRegister(writeAddr, andMask, orMask, fmt.Sprintf(
+		"Mask wrote register %d (AND 0x%04X, OR 0x%04X)", writeAddr, andMask, orMask))
+}
+
+func runWriteBit(cmd *cobra.Command, args []string) error {
+	if len(writeValues) == 0 {
+		return fmt.Errorf("value required")
+	}
+	if writeBit > 15 {
+		return fmt.Errorf("bit must be 0-15, got %d", writeBit)
+	}
+
+	value, err := parseBoolValue(writeValues[0])
+	if err != nil {
+		return fmt.Errorf("invalid bit value: %w", err)
+	}
+
+	bit := uint16(1) << writeBit
+	var orMask uint16
+	if value {
+		orMask = bit
+	}
+
+	return maskWriteRegister(writeAddr, ^bit, orMask, fmt.Sprintf(
+		"Wrote register %d bit %d = %v", writeAddr, writeBit, value))
+}
+
+func runWritePoint(cmd *cobra.Command, args []string) error {
+	ref, err := lookupPoint(args[0])
+	if err != nil {
+		return err
+	}
+	value, err := parsePointValue(ref.point, args[1])
+	if err != nil {
+		return err
+	}
+
+	client, err := createClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := client.Connect(ctx); err != nil {
+		return fmt.Errorf("connection failed: %w", err)
+	}
+
+	if err := ref.profile.Write(ctx, client, ref.point, value); err != nil {
+		return fmt.Errorf("write point failed: %w", err)
+	}
+
+	outputSuccess("Wrote %s = %s", ref.name(), strings.TrimSpace(fmt.Sprintf("%v %s", value, ref.point.Unit)))
+	return nil
+}
+
+func maskWriteRegister(addr, andMask, orMask uint16, success string) error {
+	client, err := createClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := client.Connect(ctx); err != nil {
+		return fmt.Errorf("connection failed: %w", err)
+	}
+
+	if err := client.MaskWriteRegister(ctx, addr, andMask, orMask); err != nil {
+		return fmt.Errorf("mask write failed: %w", err)
+	}
+
+	outputSuccess("%s", success)
+	return nil
+}
+
 func parseBoolValue(s string) (bool, error) {
 	s = strings.ToLower(strings.TrimSpace(s))
 	switch s {
@@ -306,3 +517,82 @@ func parseUint16Values(values []string) ([]uint16, error) {
 	}
 	return result, nil
 }
+
+// parseRegisterValues parses values of the given format to registers,
+// laying out multi-register values in the selected byte order.
+func parseRegisterValues(values []string, format string) ([]uint16, error) {
+	var result []uint16
+	for _, v := range values {
+		// Split on comma and space
+		parts := strings.FieldsFunc(v, func(r rune) bool {
+			return r == ',' || r == ' '
+		})
+		for _, p := range parts {
+			if p == "" {
+				continue
+			}
+			regs := make([]uint16, registerWidth(format))
+			if err := encodeRegisters(regs, p, format); err != nil {
+				return nil, err
+			}
+			result = append(result, regs...)
+		}
+	}
+	return result, nil
+}
+
+// encodeRegisters stores the value s of the given format in regs.
+func encodeRegisters(regs []uint16, s, format string) error {
+	s = strings.TrimSpace(s)
+
+	var err error
+	switch format {
+	case "uint16", "":
+		var v uint16
+		if v, err = parseUint16Value(s); err == nil {
+			order.PutUint16(regs, v)
+		}
+		return err
+	case "int16":
+		var v int64
+		if v, err = strconv.ParseInt(s, 0, 16); err == nil {
+			order.PutUint16(regs, uint16(v))
+		}
+	case "uint32":
+		var v uint64
+		if v, err = strconv.ParseUint(s, 0, 32); err == nil {
+			order.PutUint32(regs, uint32(v))
+		}
+	case "int32":
+		var v int64
+		if v, err = strconv.ParseInt(s, 0, 32); err == nil {
+			order.PutUint32(regs, uint32(v))
+		}
+	case "float32":
+		var v float64
+		if v, err = strconv.ParseFloat(s, 32); err == nil {
+			order.PutFloat32(regs, float32(v))
+		}
+	case "uint64":
+		var v uint64
+		if v, err = strconv.ParseUint(s, 0, 64); err == nil {
+			order.PutUint64(regs, v)
+		}
+	case "int64":
+		var v int64
+		if v, err = strconv.ParseInt(s, 0, 64); err == nil {
+			order.PutUint64(regs, uint64(v))
+		}
+	case "float64":
+		var v float64
+		if v, err = strconv.ParseFloat(s, 64); err == nil {
+			order.PutFloat64(regs, v)
+		}
+	default:
+		return fmt.Errorf("unsupported format: %s", format)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %s value: %s", format, s)
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modbus
+
+import "sync"
+
+// Communication event log bytes (FC12).
+const (
+	// EventReceive marks a remote device receive event, stored when a
+	// request is received and before it is processed.
+	EventReceive byte = 0x80
+
+	// Receive event flags.
+	EventReceiveCommError  byte = 0x02
+	EventReceiveOverrun    byte = 0x10
+	EventReceiveListenOnly byte = 0x20
+	EventReceiveBroadcast  byte = 0x40
+
+	// EventSend marks a remote device send event, stored when the
+	// processing of a request has finished.
+	EventSend byte = 0x40
+
+	// Send event flags.
+	EventSendReadException  byte = 0x01 // exception codes 1-3
+	EventSendAbortException byte = 0x02 // exception code 4
+	EventSendBusyException  byte = 0x04 // exception codes 5-6
+	EventSendNAKException   byte = 0x08 // exception code 7
+	EventSendWriteTimeout   byte = 0x10
+	EventSendListenOnly     byte = 0x20
+
+	// EventEnteredListenOnly is stored when the device enters listen only mode.
+	EventEnteredListenOnly byte = 0x04
+
+	// EventCommRestart is stored when communications are restarted.
+	EventCommRestart byte = 0x00
+)
+
+// MaxCommEvents is the number of events kept in the communication event log.
+const MaxCommEvents = 64
+
+// commUnit holds the communication state of one unit.
+type commUnit struct {
+	eventCount   uint16
+	messageCount uint16
+	events       []byte // most recent first
+	listenOnly   bool
+
+	// Diagnostic counters (FC08)
+	exceptionCount     uint16
+	serverMessageCount uint16
+	noResponseCount    uint16
+	nakCount           uint16
+	busyCount          uint16
+	diagRegister       uint16
+}
+
+// clearCounters resets the event counter and the diagnostic counters.
+func (u *commUnit) clearCounters() {
+	u.eventCount = 0
+	u.messageCount = 0
+	u.exceptionCount = 0
+	u.serverMessageCount = 0
+	u.noResponseCount = 0
+	u.nakCount = 0
+	u.busyCount = 0
+}
+
+// logEvent stores an event at the head of the log.
+func (u *commUnit) logEvent(event byte) {
+	if len(u.events) < MaxCommEvents {
+		u.events = append(u.events, 0)
+	}
+	copy(u.events[1:], u.events)
+	u.events[0] = event
+}
+
+// commTracker maintains the communication event counter and event log
+// of each unit served by a Server.
+type commTracker struct {
+	mu    sync.Mutex
+	units map[UnitID]*commUnit
+}
+
+// unitLocked returns the state of a unit, creating it if needed.
+// Must be called with the lock held.
+func (t *commTracker) unitLocked(unitID UnitID) *commUnit {
+	if t.units == nil {
+		t.units = make(map[UnitID]*commUnit)
+	}
+	u, ok := t.units[unitID]
+	if !ok {
+		u = &commUnit{}
+		t.units[unitID] = u
+	}
+	return u
+}
+
+// received records the reception of a request and reports whether the
+// unit is in listen only mode.
+func (t *commTracker) received(unitID UnitID) (listenOnly bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	u := t.unitLocked(unitID)
+	u.messageCount++
+
+	event := EventReceive
+	if u.listenOnly {
+		event |= EventReceiveListenOnly
+	}
+	u.logEvent(event)
+	return u.listenOnly
+}
+
+// ignored records a request dropped without processing because the unit
+// is in listen only mode.
+func (t *commTracker) ignored(unitID UnitID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.unitLocked(unitID).noResponseCount++
+}
+
+// sent records the completion of a request with the given response PDU,
+// nil when no response is returned. Successful responses increment the
+// event counter, except those of the event counter and event log requests
+// themselves.
+func (t *commTracker) sent(unitID UnitID, fc FunctionCode, resp []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	u := t.unitLocked(unitID)
+	u.serverMessageCount++
+
+	event := EventSend
+	if u.listenOnly {
+		event |= EventSendListenOnly
+	}
+	switch {
+	case resp == nil:
+		u.noResponseCount++
+	case IsExceptionResponse(resp) && len(resp) >= 2:
+		u.exceptionCount++
+		switch ExceptionCode(resp[1]) {
+		case ExceptionIllegalFunction, ExceptionIllegalDataAddress, ExceptionIllegalDataValue:
+			event |= EventSendReadException
+		case ExceptionServerDeviceFailure:
+			event |= EventSendAbortException
+		case ExceptionAcknowledge:
+			event |= EventSendBusyException
+		case ExceptionServerDeviceBusy:
+			event |= EventSendBusyException
+			u.busyCount++
+		case ExceptionNegativeAcknowledge:
+			event |= EventSendNAKException
+			u.nakCount++
+		}
+	case fc != FuncGetCommEventCounter && fc != FuncGetCommEventLog:
+		u.eventCount++
+	}
+	u.logEvent(event)
+}
+
+// restart restarts the communications of a unit (FC08, sub-function 01):
+// counters are cleared, listen only mode is left and, if clearLog is set,
+// the event log is emptied. It reports whether the unit was in listen only
+// mode, in which case no response is returned.
+func (t *commTracker) restart(unitID UnitID, clearLog bool) (wasListenOnly bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	u := t.unitLocked(unitID)
+	wasListenOnly = u.listenOnly
+	u.listenOnly = false
+	u.clearCounters()
+	if clearLog {
+		u.events = nil
+	}
+	u.logEvent(EventCommRestart)
+	return wasListenOnly
+}
+
+// forceListenOnly puts a unit in listen only mode (FC08, sub-function 04).
+func (t *commTracker) forceListenOnly(unitID UnitID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	u := t.unitLocked(unitID)
+	if !u.listenOnly {
+		u.listenOnly = true
+		u.logEvent(EventEnteredListenOnly)
+	}
+}
+
+// clearCounters clears the counters and the diagnostic register of a unit
+// (FC08, sub-function 0A).
+func (t *commTracker) clearCounters(unitID UnitID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	u := t.unitLocked(unitID)
+	u.clearCounters()
+	u.diagRegister = 0
+}
+
+// setDiagnosticRegister sets the diagnostic register of a unit.
+func (t *commTracker) setDiagnosticRegister(unitID UnitID, value uint16) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.unitLocked(unitID).diagRegister = value
+}
+
+// diagnosticValue returns the diagnostic register or counter read by an
+// FC08 sub-function. Network transports have no CRC or character overrun
+// errors, so those counters are always zero.
+func (t *commTracker) diagnosticValue(unitID UnitID, subFunc uint16) uint16 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	u := t.unitLocked(unitID)
+	switch subFunc {
+	case DiagReturnDiagnosticRegister:
+		return u.diagRegister
+	case DiagReturnBusMessageCount:
+		return u.messageCount
+	case DiagReturnBusExceptionErrorCount:
+		return u.exceptionCount
+	case DiagReturnServerMessageCount:
+		return u.serverMessageCount
+	case DiagReturnServerNoResponseCount:
+		return u.noResponseCount
+	case DiagReturnServerNAKCount:
+		return u.nakCount
+	case DiagReturnServerBusyCount:
+		return u.busyCount
+	}
+	return 0
+}
+
+// eventCounter returns the event counter of a unit (FC11).
+func (t *commTracker) eventCounter(unitID UnitID) uint16 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.unitLocked(unitID).eventCount
+}
+
+// eventLog returns the event counter, message counter and a copy of the
+// event log of a unit (FC12).
+func (t *commTracker) eventLog(unitID UnitID) (eventCount, messageCount uint16, events []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	u := t.unitLocked(unitID)
+	return u.eventCount, u.messageCount, append([]byte(nil), u.events...)
+}
@@ -0,0 +1,211 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modbus
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"os"
+	"reflect"
+	"time"
+)
+
+// Quality tells whether a value can be trusted.
+type Quality uint8
+
+const (
+	// QualityGood is a value read by the last request.
+	QualityGood Quality = iota
+	// QualityUncertain is a value that may no longer be accurate, such as
+	// a value not refreshed in time or the last value of a busy device.
+	QualityUncertain
+	// QualityBad is the last known value of a read that failed.
+	QualityBad
+)
+
+// String returns a string representation of Quality.
+func (q Quality) String() string {
+	switch q {
+	case QualityGood:
+		return "good"
+	case QualityUncertain:
+		return "uncertain"
+	case QualityBad:
+		return "bad"
+	default:
+		return "unknown"
+	}
+}
+
+// Reason is the cause of a quality other than good.
+type Reason uint8
+
+const (
+	ReasonNone            Reason = iota
+	ReasonStale                  // not refreshed in time
+	ReasonDeviceBusy             // acknowledge or server device busy exception
+	ReasonTimeout                // no response
+	ReasonCommFailure            // connection or transport error
+	ReasonInvalidResponse        // malformed response or checksum error
+	ReasonConfigError            // illegal function, data address or data value exception
+	ReasonDeviceFailure          // server device failure or memory parity exception
+	ReasonGatewayFailure         // gateway path or gateway target exception
+	ReasonException              // other exception
+)
+
+// String returns a string representation of Reason.
+func (r Reason) String() string {
+	switch r {
+	case ReasonNone:
+		return ""
+	case ReasonStale:
+		return "stale"
+	case ReasonDeviceBusy:
+		return "device-busy"
+	case ReasonTimeout:
+		return "timeout"
+	case ReasonCommFailure:
+		return "comm-failure"
+	case ReasonInvalidResponse:
+		return "invalid-response"
+	case ReasonConfigError:
+		return "config-error"
+	case ReasonDeviceFailure:
+		return "device-failure"
+	case ReasonGatewayFailure:
+		return "gateway-failure"
+	case ReasonException:
+		return "exception"
+	default:
+		return "unknown"
+	}
+}
+
+// Quality returns the quality of a value with the reason.
+func (r Reason) Quality() Quality {
+	switch r {
+	case ReasonNone:
+		return QualityGood
+	case ReasonStale, ReasonDeviceBusy:
+		return QualityUncertain
+	default:
+		return QualityBad
+	}
+}
+
+// ReasonOf returns the reason of a read that failed with err: the class of
+// the exception code of a ModbusError, or the kind of transport error.
+func ReasonOf(err error) Reason {
+	if err == nil {
+		return ReasonNone
+	}
+
+	var modbusErr *ModbusError
+	if errors.As(err, &modbusErr) {
+		switch modbusErr.ExceptionCode {
+		case ExceptionIllegalFunction, ExceptionIllegalDataAddress, ExceptionIllegalDataValue:
+			return ReasonConfigError
+		case ExceptionServerDeviceFailure, ExceptionMemoryParityError:
+			return ReasonDeviceFailure
+		case ExceptionAcknowledge, ExceptionServerDeviceBusy:
+			return ReasonDeviceBusy
+		case ExceptionGatewayPathUnavailable, ExceptionGatewayTargetDeviceFailedToRespond:
+			return ReasonGatewayFailure
+		default:
+			return ReasonException
+		}
+	}
+
+	var netErr net.Error
+	switch {
+	case errors.Is(err, ErrTimeout), errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return ReasonTimeout
+	case errors.Is(err, ErrInvalidResponse), errors.Is(err, ErrInvalidCRC), errors.Is(err, ErrInvalidLRC), errors.Is(err, ErrInvalidFrame):
+		return ReasonInvalidResponse
+	default:
+		return ReasonCommFailure
+	}
+}
+
+// DataValue is a value with its source timestamp and quality.
+type DataValue struct {
+	Value any // last value read, nil if never read
+
+	// Timestamp is the time the value was read, or the time its quality
+	// changed.
+	Timestamp time.Time
+
+	Quality Quality
+	Reason  Reason
+	Err     error // error of the failed read, if any
+}
+
+// NewDataValue returns the data value of a read at ts. Without error the
+// value is good; otherwise value is the last known value and the quality
+// follows ReasonOf(err).
+func NewDataValue(value any, err error, ts time.Time) DataValue {
+	reason := ReasonOf(err)
+	return DataValue{
+		Value:     value,
+		Timestamp: ts,
+		Quality:   reason.Quality(),
+		Reason:    reason,
+		Err:       err,
+	}
+}
+
+// Deadband filters the changes of values reported by exception. A change
+// of a numeric value is reported when it exceeds every limit that is set;
+// the zero Deadband reports every change.
+type Deadband struct {
+	// Absolute is the change from the last reported value, in engineering
+	// units, below which a value is not reported.
+	Absolute float64
+
+	// Percent is the change, in percent of the last reported value, below
+	// which a value is not reported. Any change from zero is reported.
+	Percent float64
+}
+
+// Exceeded reports whether value differs from the reported one by more
+// than the deadband. Values that are not numbers only need to differ.
+func (d Deadband) Exceeded(reported, value any) bool {
+	a, okA := floatValue(reported)
+	b, okB := floatValue(value)
+	if !okA || !okB {
+		return !reflect.DeepEqual(reported, value)
+	}
+	change := math.Abs(b - a)
+	if change == 0 || change <= d.Absolute {
+		return false
+	}
+	return d.Percent <= 0 || change > math.Abs(a)*d.Percent/100
+}
+
+// Report reports whether next must be reported after prev, the last value
+// reported: the first value, a change of quality or reason, or a change of
+// value beyond the deadband.
+func (d Deadband) Report(prev, next DataValue) bool {
+	if prev.Timestamp.IsZero() {
+		return true
+	}
+	if next.Quality != prev.Quality || next.Reason != prev.Reason {
+		return true
+	}
+	return d.Exceeded(prev.Value, next.Value)
+}
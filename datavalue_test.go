@@ -0,0 +1,103 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestReasonOf(t *testing.T) {
+	tests := []struct {
+		err     error
+		reason  Reason
+		quality Quality
+	}{
+		{nil, ReasonNone, QualityGood},
+		{NewModbusError(FuncReadHoldingRegisters, ExceptionIllegalDataAddress), ReasonConfigError, QualityBad},
+		{fmt.Errorf("read: %w", NewModbusError(FuncReadCoils, ExceptionServerDeviceBusy)), ReasonDeviceBusy, QualityUncertain},
+		{NewModbusError(FuncReadCoils, ExceptionServerDeviceFailure), ReasonDeviceFailure, QualityBad},
+		{NewModbusError(FuncReadCoils, ExceptionGatewayTargetDeviceFailedToRespond), ReasonGatewayFailure, QualityBad},
+		{NewModbusError(FuncReadCoils, ExceptionNegativeAcknowledge), ReasonException, QualityBad},
+		{&RangeError{Chunks: []*ChunkError{{Err: NewModbusError(FuncReadCoils, ExceptionIllegalFunction)}}, Total: 2}, ReasonConfigError, QualityBad},
+		{context.DeadlineExceeded, ReasonTimeout, QualityBad},
+		{ErrTimeout, ReasonTimeout, QualityBad},
+		{ErrInvalidCRC, ReasonInvalidResponse, QualityBad},
+		{io.EOF, ReasonCommFailure, QualityBad},
+	}
+	for _, tt := range tests {
+		if r := ReasonOf(tt.err); r != tt.reason || r.Quality() != tt.quality {
+			t.Errorf("%v: expected %s %s, got %s %s", tt.err, tt.quality, tt.reason, r.Quality(), r)
+		}
+	}
+
+	now := time.Now()
+	if dv := NewDataValue(uint16(7), ErrConnectionClosed, now); dv.Quality != QualityBad || dv.Reason != ReasonCommFailure || dv.Value != uint16(7) {
+		t.Errorf("Unexpected data value %+v", dv)
+	}
+}
+
+func TestDeadband(t *testing.T) {
+	tests := []struct {
+		deadband Deadband
+		reported any
+		value    any
+		want     bool
+	}{
+		{Deadband{}, uint16(10), uint16(10), false},
+		{Deadband{}, uint16(10), uint16(11), true},
+		{Deadband{Absolute: 2}, uint16(10), uint16(12), false},
+		{Deadband{Absolute: 2}, int16(10), int16(7), true},
+		{Deadband{Percent: 10}, 200.0, 219.0, false},
+		{Deadband{Percent: 10}, 200.0, 179.0, true},
+		{Deadband{Percent: 10}, 0.0, 0.1, true},
+		{Deadband{Absolute: 5, Percent: 1}, 1000.0, 1008.0, false},
+		{Deadband{Absolute: 5, Percent: 1}, 1000.0, 1011.0, true},
+		{Deadband{Absolute: 5}, true, false, true},
+		{Deadband{Absolute: 5}, "on", "on", false},
+		{Deadband{}, []uint16{1, 2}, []uint16{1, 2}, false},
+		{Deadband{}, []uint16{1, 2}, []uint16{1, 3}, true},
+		{Deadband{}, []byte("ab"), "ab", true},
+	}
+	for _, tt := range tests {
+		if got := tt.deadband.Exceeded(tt.reported, tt.value); got != tt.want {
+			t.Errorf("%+v: %v -> %v: expected %v", tt.deadband, tt.reported, tt.value, tt.want)
+		}
+	}
+
+	d := Deadband{Absolute: 1}
+	now := time.Now()
+	good := NewDataValue(10.0, nil, now)
+	if !d.Report(DataValue{}, good) {
+		t.Error("Expected the first value to be reported")
+	}
+	if d.Report(good, NewDataValue(10.5, nil, now)) {
+		t.Error("Expected a change within the deadband to be filtered")
+	}
+	bad := NewDataValue(10.0, errors.New("broken pipe"), now)
+	if !d.Report(good, bad) {
+		t.Error("Expected a quality change to be reported")
+	}
+	if d.Report(bad, NewDataValue(10.0, io.EOF, now)) {
+		t.Error("Expected the same failure not to be reported again")
+	}
+	if !d.Report(bad, NewDataValue(10.0, ErrTimeout, now)) {
+		t.Error("Expected a reason change to be reported")
+	}
+}
@@ -30,6 +30,7 @@ const (
 	ExceptionServerDeviceFailure                ExceptionCode = 0x04
 	ExceptionAcknowledge                        ExceptionCode = 0x05
 	ExceptionServerDeviceBusy                   ExceptionCode = 0x06
+	ExceptionNegativeAcknowledge                ExceptionCode = 0x07
 	ExceptionMemoryParityError                  ExceptionCode = 0x08
 	ExceptionGatewayPathUnavailable             ExceptionCode = 0x0A
 	ExceptionGatewayTargetDeviceFailedToRespond ExceptionCode = 0x0B
@@ -50,6 +51,8 @@ func (e ExceptionCode) String() string {
 		return "acknowledge"
 	case ExceptionServerDeviceBusy:
 		return "server device busy"
+	case ExceptionNegativeAcknowledge:
+		return "negative acknowledge"
 	case ExceptionMemoryParityError:
 		return "memory parity error"
 	case ExceptionGatewayPathUnavailable:
@@ -89,6 +92,9 @@ var (
 	// ErrInvalidCRC indicates a CRC validation failure (RTU mode).
 	ErrInvalidCRC = errors.New("modbus: invalid CRC")
 
+	// ErrInvalidLRC indicates an LRC validation failure (ASCII mode).
+	ErrInvalidLRC = errors.New("modbus: invalid LRC")
+
 	// ErrInvalidFrame indicates a malformed frame.
 	ErrInvalidFrame = errors.New("modbus: invalid frame")
 
@@ -115,8 +121,57 @@ var (
 
 	// ErrMaxRetriesExceeded indicates the maximum number of retries was exceeded.
 	ErrMaxRetriesExceeded = errors.New("modbus: max retries exceeded")
+
+	// ErrInvalidTag indicates a tag definition that cannot be read or written.
+	ErrInvalidTag = errors.New("modbus: invalid tag")
+
+	// ErrInvalidValue indicates a value that cannot be encoded or decoded.
+	ErrInvalidValue = errors.New("modbus: invalid value")
 )
 
+// ChunkError reports the failure of one request of a range operation.
+type ChunkError struct {
+	Address  uint16
+	Quantity uint16
+	Err      error
+}
+
+// Error implements the error interface.
+func (e *ChunkError) Error() string {
+	return fmt.Sprintf("chunk %d-%d: %v", e.Address, int(e.Address)+int(e.Quantity)-1, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ChunkError) Unwrap() error {
+	return e.Err
+}
+
+// RangeError reports the failed requests of a range operation or a read
+// plan. The values of the other requests are still returned.
+type RangeError struct {
+	Chunks []*ChunkError
+	Total  int // number of requests of the operation
+}
+
+// Error implements the error interface.
+func (e *RangeError) Error() string {
+	msg := fmt.Sprintf("modbus: %d of %d chunks failed", len(e.Chunks), e.Total)
+	if len(e.Chunks) > 0 {
+		msg += ": " + e.Chunks[0].Error()
+	}
+	return msg
+}
+
+// Unwrap returns the errors of the failed chunks, so that errors.Is and
+// errors.As match any of them.
+func (e *RangeError) Unwrap() []error {
+	errs := make([]error, len(e.Chunks))
+	for i, c := range e.Chunks {
+		errs[i] = c
+	}
+	return errs
+}
+
 // NewModbusError creates a new Modbus exception error.
 func NewModbusError(fc FunctionCode, ec ExceptionCode) *ModbusError {
 	return &ModbusError{
@@ -30,6 +30,7 @@ func TestExceptionCode_String(t *testing.T) {
 		{ExceptionServerDeviceFailure, "server device failure"},
 		{ExceptionAcknowledge, "acknowledge"},
 		{ExceptionServerDeviceBusy, "server device busy"},
+		{ExceptionNegativeAcknowledge, "negative acknowledge"},
 		{ExceptionMemoryParityError, "memory parity error"},
 		{ExceptionGatewayPathUnavailable, "gateway path unavailable"},
 		{ExceptionGatewayTargetDeviceFailedToRespond, "gateway target device failed to respond"},
@@ -17,7 +17,6 @@ package modbus
 import (
 	"encoding/binary"
 	"fmt"
-	"math"
 )
 
 // ReadCoilsRequest represents a request to read coils (FC01).
@@ -249,6 +248,43 @@ func (r *GetCommEventCounterResponse) Decode(data []byte) error {
 	return nil
 }
 
+// GetCommEventLogRequest represents a get comm event log request (FC12).
+type GetCommEventLogRequest struct{}
+
+func (r *GetCommEventLogRequest) FunctionCode() FunctionCode {
+	return FuncGetCommEventLog
+}
+
+func (r *GetCommEventLogRequest) Encode() ([]byte, error) {
+	return BuildGetCommEventLogPDU(), nil
+}
+
+// CommEventLog holds the communication event log of a device (FC12).
+type CommEventLog struct {
+	Status       uint16
+	EventCount   uint16
+	MessageCount uint16
+	Events       []byte // Most recent first
+}
+
+// GetCommEventLogResponse represents a get comm event log response.
+type GetCommEventLogResponse struct {
+	CommEventLog
+}
+
+func (r *GetCommEventLogResponse) FunctionCode() FunctionCode {
+	return FuncGetCommEventLog
+}
+
+func (r *GetCommEventLogResponse) Decode(data []byte) error {
+	log, err := ParseGetCommEventLogResponse(data)
+	if err != nil {
+		return err
+	}
+	r.CommEventLog = *log
+	return nil
+}
+
 // WriteMultipleCoilsRequest represents a request to write multiple coils (FC15).
 type WriteMultipleCoilsRequest struct {
 	Address uint16
@@ -277,6 +313,123 @@ func (r *WriteMultipleRegistersRequest) Encode() ([]byte, error) {
 	return BuildWriteMultipleRegistersPDU(r.Address, r.Values)
 }
 
+// FileSubRequest identifies registers of a file record to read (FC20).
+type FileSubRequest struct {
+	File   uint16
+	Record uint16
+	Length uint16
+}
+
+// FileRecord holds registers of a file record (FC20/FC21).
+type FileRecord struct {
+	File   uint16
+	Record uint16
+	Data   []uint16
+}
+
+// ReadFileRecordRequest represents a request to read file records (FC20).
+type ReadFileRecordRequest struct {
+	SubRequests []FileSubRequest
+}
+
+func (r *ReadFileRecordRequest) FunctionCode() FunctionCode {
+	return FuncReadFileRecord
+}
+
+func (r *ReadFileRecordRequest) Encode() ([]byte, error) {
+	return BuildReadFileRecordPDU(r.SubRequests)
+}
+
+// ReadFileRecordResponse represents a response to read file records.
+type ReadFileRecordResponse struct {
+	SubRequests []FileSubRequest // Must be set before decoding
+	Records     []FileRecord
+}
+
+func (r *ReadFileRecordResponse) FunctionCode() FunctionCode {
+	return FuncReadFileRecord
+}
+
+func (r *ReadFileRecordResponse) Decode(data []byte) error {
+	records, err := ParseReadFileRecordResponse(data, r.SubRequests)
+	if err != nil {
+		return err
+	}
+	r.Records = records
+	return nil
+}
+
+// WriteFileRecordRequest represents a request to write file records (FC21).
+type WriteFileRecordRequest struct {
+	Records []FileRecord
+}
+
+func (r *WriteFileRecordRequest) FunctionCode() FunctionCode {
+	return FuncWriteFileRecord
+}
+
+func (r *WriteFileRecordRequest) Encode() ([]byte, error) {
+	return BuildWriteFileRecordPDU(r.Records)
+}
+
+// MaskWriteRegisterRequest represents a request to modify bits of a
+// holding register (FC22).
+type MaskWriteRegisterRequest struct {
+	Address uint16
+	AndMask uint16
+	OrMask  uint16
+}
+
+func (r *MaskWriteRegisterRequest) FunctionCode() FunctionCode {
+	return FuncMaskWriteRegister
+}
+
+func (r *MaskWriteRegisterRequest) Encode() ([]byte, error) {
+	return BuildMaskWriteRegisterPDU(r.Address, r.AndMask, r.OrMask), nil
+}
+
+// ReadWriteMultipleRegistersRequest represents a request to write and then
+// read multiple registers in one transaction (FC23).
+type ReadWriteMultipleRegistersRequest struct {
+	ReadAddress  uint16
+	ReadQuantity uint16
+	WriteAddress uint16
+	Values       []uint16
+}
+
+func (r *ReadWriteMultipleRegistersRequest) FunctionCode() FunctionCode {
+	return FuncReadWriteMultipleRegisters
+}
+
+func (r *ReadWriteMultipleRegistersRequest) Encode() ([]byte, error) {
+	return BuildReadWriteMultipleRegistersPDU(r.ReadAddress, r.ReadQuantity, r.WriteAddress, r.Values)
+}
+
+// ReadWriteMultipleRegistersResponse represents a response to read/write multiple registers.
+type ReadWriteMultipleRegistersResponse struct {
+	Values []uint16
+}
+
+func (r *ReadWriteMultipleRegistersResponse) FunctionCode() FunctionCode {
+	return FuncReadWriteMultipleRegisters
+}
+
+func (r *ReadWriteMultipleRegistersResponse) Decode(data []byte) error {
+	if len(data) < 2 {
+		return fmt.Errorf("%w: response too short", ErrInvalidResponse)
+	}
+	byteCount := int(data[1])
+	if byteCount%2 != 0 || len(data) < 2+byteCount {
+		return fmt.Errorf("%w: invalid byte count", ErrInvalidResponse)
+	}
+	qty := byteCount / 2
+	r.Values = make([]uint16, qty)
+	for i := 0; i < qty; i++ {
+		r.Values[i] = binary.BigEndian.Uint16(data[2+i*2:])
+	}
+	return nil
+}
+
 // ReportServerIDRequest represents a report server ID request (FC17).
 type ReportServerIDRequest struct{}
 
@@ -306,6 +459,129 @@ func (r *ReportServerIDResponse) Decode(data []byte) error {
 	return nil
 }
 
+// ReadFIFOQueueRequest represents a request to read a FIFO queue (FC24).
+type ReadFIFOQueueRequest struct {
+	Address uint16
+}
+
+func (r *ReadFIFOQueueRequest) FunctionCode() FunctionCode {
+	return FuncReadFIFOQueue
+}
+
+func (r *ReadFIFOQueueRequest) Encode() ([]byte, error) {
+	return BuildReadFIFOQueuePDU(r.Address), nil
+}
+
+// ReadFIFOQueueResponse represents a response to read a FIFO queue.
+type ReadFIFOQueueResponse struct {
+	Values []uint16
+}
+
+func (r *ReadFIFOQueueResponse) FunctionCode() FunctionCode {
+	return FuncReadFIFOQueue
+}
+
+func (r *ReadFIFOQueueResponse) Decode(data []byte) error {
+	values, err := ParseReadFIFOQueueResponse(data)
+	if err != nil {
+		return err
+	}
+	r.Values = values
+	return nil
+}
+
+// ReadDeviceIdentificationRequest represents a read device identification request (FC43/14).
+type ReadDeviceIdentificationRequest struct {
+	ReadCode uint8
+	ObjectID uint8
+}
+
+func (r *ReadDeviceIdentificationRequest) FunctionCode() FunctionCode {
+	return FuncEncapsulatedInterfaceTransport
+}
+
+func (r *ReadDeviceIdentificationRequest) Encode() ([]byte, error) {
+	return BuildReadDeviceIdentificationPDU(r.ReadCode, r.ObjectID), nil
+}
+
+// DeviceObject is a device identification object.
+type DeviceObject struct {
+	ID    uint8
+	Value []byte
+}
+
+// ReadDeviceIdentificationResponse represents one page of a read device identification response.
+type ReadDeviceIdentificationResponse struct {
+	ReadCode        uint8
+	ConformityLevel uint8
+	MoreFollows     bool
+	NextObjectID    uint8
+	Objects         []DeviceObject
+}
+
+func (r *ReadDeviceIdentificationResponse) FunctionCode() FunctionCode {
+	return FuncEncapsulatedInterfaceTransport
+}
+
+func (r *ReadDeviceIdentificationResponse) Decode(data []byte) error {
+	resp, err := ParseReadDeviceIdentificationResponse(data)
+	if err != nil {
+		return err
+	}
+	*r = *resp
+	return nil
+}
+
+// DeviceIdentification holds the identification objects of a device,
+// collected from all pages of a read device identification (FC43/14).
+type DeviceIdentification struct {
+	// ConformityLevel is the identification level supported by the device.
+	ConformityLevel uint8
+
+	// Objects holds the object values keyed by object ID.
+	Objects map[uint8][]byte
+}
+
+// Object returns the value of an object as a string, or "" if it was not received.
+func (d *DeviceIdentification) Object(id uint8) string {
+	return string(d.Objects[id])
+}
+
+// VendorName returns the VendorName object.
+func (d *DeviceIdentification) VendorName() string {
+	return d.Object(DeviceIDVendorName)
+}
+
+// ProductCode returns the ProductCode object.
+func (d *DeviceIdentification) ProductCode() string {
+	return d.Object(DeviceIDProductCode)
+}
+
+// MajorMinorRevision returns the MajorMinorRevision object.
+func (d *DeviceIdentification) MajorMinorRevision() string {
+	return d.Object(DeviceIDMajorMinorRevision)
+}
+
+// VendorURL returns the VendorUrl object.
+func (d *DeviceIdentification) VendorURL() string {
+	return d.Object(DeviceIDVendorURL)
+}
+
+// ProductName returns the ProductName object.
+func (d *DeviceIdentification) ProductName() string {
+	return d.Object(DeviceIDProductName)
+}
+
+// ModelName returns the ModelName object.
+func (d *DeviceIdentification) ModelName() string {
+	return d.Object(DeviceIDModelName)
+}
+
+// UserApplicationName returns the UserApplicationName object.
+func (d *DeviceIdentification) UserApplicationName() string {
+	return d.Object(DeviceIDUserApplicationName)
+}
+
 // Helper functions for data conversion
 
 // BoolsToBytes converts a slice of bools to a byte slice (packed).
@@ -349,42 +625,39 @@ func BytesToUint16s(data []byte) []uint16 {
 }
 
 // Float32ToRegisters converts a float32 to two uint16 registers (big endian).
+// Use ByteOrder.PutFloat32 for other byte orders.
 func Float32ToRegisters(f float32) [2]uint16 {
-	bits := math.Float32bits(f)
-	return [2]uint16{
-		uint16(bits >> 16),
-		uint16(bits & 0xFFFF),
-	}
+	var regs [2]uint16
+	OrderABCD.PutFloat32(regs[:], f)
+	return regs
 }
 
 // RegistersToFloat32 converts two uint16 registers to a float32 (big endian).
+// Use ByteOrder.Float32 for other byte orders.
 func RegistersToFloat32(regs [2]uint16) float32 {
-	bits := uint32(regs[0])<<16 | uint32(regs[1])
-	return math.Float32frombits(bits)
+	return OrderABCD.Float32(regs[:])
 }
 
 // Int32ToRegisters converts an int32 to two uint16 registers (big endian).
 func Int32ToRegisters(i int32) [2]uint16 {
-	return [2]uint16{
-		uint16(i >> 16),
-		uint16(i & 0xFFFF),
-	}
+	return Uint32ToRegisters(uint32(i))
 }
 
 // RegistersToInt32 converts two uint16 registers to an int32 (big endian).
 func RegistersToInt32(regs [2]uint16) int32 {
-	return int32(regs[0])<<16 | int32(regs[1])
+	return int32(RegistersToUint32(regs))
 }
 
 // Uint32ToRegisters converts a uint32 to two uint16 registers (big endian).
+// Use ByteOrder.PutUint32 for other byte orders.
 func Uint32ToRegisters(u uint32) [2]uint16 {
-	return [2]uint16{
-		uint16(u >> 16),
-		uint16(u & 0xFFFF),
-	}
+	var regs [2]uint16
+	OrderABCD.PutUint32(regs[:], u)
+	return regs
 }
 
 // RegistersToUint32 converts two uint16 registers to a uint32 (big endian).
+// Use ByteOrder.Uint32 for other byte orders.
 func RegistersToUint32(regs [2]uint16) uint32 {
-	return uint32(regs[0])<<16 | uint32(regs[1])
+	return OrderABCD.Uint32(regs[:])
 }
@@ -0,0 +1,207 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// pipelineResult is the outcome of a pipelined transaction.
+type pipelineResult struct {
+	frame []byte
+	err   error
+}
+
+// Pipeline carries several MBAP transactions at once over a TCP
+// connection. Responses are read by a single reader goroutine and
+// delivered to the waiting request by transaction ID.
+type Pipeline struct {
+	tcp    *TCPTransport
+	window chan struct{}
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	conn    net.Conn
+	pending map[uint16]chan pipelineResult
+}
+
+// NewPipeline creates a pipeline over tcp allowing at most maxInFlight
+// outstanding transactions.
+func NewPipeline(tcp *TCPTransport, maxInFlight int) *Pipeline {
+	if maxInFlight < 1 {
+		maxInFlight = 1
+	}
+	return &Pipeline{
+		tcp:     tcp,
+		window:  make(chan struct{}, maxInFlight),
+		pending: make(map[uint16]chan pipelineResult),
+	}
+}
+
+// Connect establishes the connection and starts the response reader.
+func (p *Pipeline) Connect(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn != nil {
+		return nil // Already connected
+	}
+
+	if err := p.tcp.Connect(ctx); err != nil {
+		return err
+	}
+	conn := p.tcp.Conn()
+	if conn == nil {
+		return errors.New("not connected")
+	}
+
+	p.conn = conn
+	go p.readLoop(conn)
+	return nil
+}
+
+// Close closes the connection. Outstanding transactions fail with
+// net.ErrClosed.
+func (p *Pipeline) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.conn = nil
+	p.failPending(net.ErrClosed)
+	return p.tcp.Close()
+}
+
+// IsConnected returns true if the pipeline is connected.
+func (p *Pipeline) IsConnected() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.conn != nil
+}
+
+// Send writes an MBAP frame with the given transaction ID and waits for
+// the response carrying the same ID. It blocks while the in-flight window
+// is full. A response arriving after its request gave up is discarded.
+func (p *Pipeline) Send(ctx context.Context, txID uint16, data []byte) ([]byte, error) {
+	// Set deadline from context or use default timeout
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(p.tcp.timeout)
+	}
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+
+	// Wait for a free slot in the window
+	select {
+	case p.window <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-timer.C:
+		return nil, fmt.Errorf("window: %w", os.ErrDeadlineExceeded)
+	}
+	defer func() { <-p.window }()
+
+	p.mu.Lock()
+	conn := p.conn
+	if conn == nil {
+		p.mu.Unlock()
+		return nil, errors.New("not connected")
+	}
+	if _, busy := p.pending[txID]; busy {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("transaction ID %d already in flight", txID)
+	}
+	ch := make(chan pipelineResult, 1)
+	p.pending[txID] = ch
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		delete(p.pending, txID)
+		p.mu.Unlock()
+	}()
+
+	p.writeMu.Lock()
+	conn.SetWriteDeadline(deadline)
+	err := writeFull(conn, data)
+	p.writeMu.Unlock()
+	if err != nil {
+		p.fail(conn, err)
+		return nil, fmt.Errorf("write: %w", err)
+	}
+
+	select {
+	case res := <-ch:
+		return res.frame, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-timer.C:
+		return nil, os.ErrDeadlineExceeded
+	}
+}
+
+// readLoop reads response frames from conn and hands each one to the
+// request waiting for its transaction ID. Frames nobody waits for anymore
+// are dropped.
+func (p *Pipeline) readLoop(conn net.Conn) {
+	for {
+		frame, err := readMBAPFrame(conn, time.Time{})
+		if err != nil {
+			p.fail(conn, err)
+			return
+		}
+
+		txID := uint16(frame[0])<<8 | uint16(frame[1])
+		p.mu.Lock()
+		ch, ok := p.pending[txID]
+		if ok {
+			delete(p.pending, txID)
+		}
+		p.mu.Unlock()
+
+		if ok {
+			ch <- pipelineResult{frame: frame}
+		}
+	}
+}
+
+// fail closes conn after an I/O error and fails every outstanding
+// transaction on it.
+func (p *Pipeline) fail(conn net.Conn, err error) {
+	p.mu.Lock()
+	if p.conn != conn {
+		// Already replaced or closed
+		p.mu.Unlock()
+		return
+	}
+	p.conn = nil
+	p.tcp.Close()
+	p.failPending(err)
+	p.mu.Unlock()
+}
+
+// failPending fails every outstanding transaction with err. p.mu must be
+// held.
+func (p *Pipeline) failPending(err error) {
+	for txID, ch := range p.pending {
+		ch <- pipelineResult{err: err}
+		delete(p.pending, txID)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// SerialConfig holds the line settings of a serial port.
+type SerialConfig struct {
+	Device   string // Device path, e.g. /dev/ttyUSB0
+	BaudRate int    // Bits per second
+	DataBits int    // 5, 6, 7 or 8
+	Parity   byte   // 'N', 'E' or 'O'
+	StopBits int    // 1 or 2
+
+	// FrameDelay is the minimum silence enforced on the line before a new
+	// frame is written (3.5 character times in RTU mode).
+	FrameDelay time.Duration
+}
+
+// SerialTransport implements a serial line transport for Modbus RTU and ASCII.
+type SerialTransport struct {
+	cfg     SerialConfig
+	timeout time.Duration
+
+	mu           sync.Mutex
+	port         *os.File
+	lastActivity time.Time
+}
+
+// NewSerialTransport creates a new serial transport.
+func NewSerialTransport(cfg SerialConfig, timeout time.Duration) *SerialTransport {
+	return &SerialTransport{
+		cfg:     cfg,
+		timeout: timeout,
+	}
+}
+
+// Connect opens and configures the serial port.
+func (t *SerialTransport) Connect(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.port != nil {
+		return nil // Already open
+	}
+
+	port, err := openSerial(t.cfg)
+	if err != nil {
+		return fmt.Errorf("serial open: %w", err)
+	}
+
+	t.port = port
+	t.lastActivity = time.Now()
+	return nil
+}
+
+// Close closes the serial port.
+func (t *SerialTransport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.port == nil {
+		return nil
+	}
+
+	err := t.port.Close()
+	t.port = nil
+	return err
+}
+
+// IsConnected returns true if the serial port is open.
+func (t *SerialTransport) IsConnected() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.port != nil
+}
+
+// Exchange writes data and reads a single response frame using read.
+// The configured frame delay is observed before writing, and any stale
+// input left on the line is discarded so it cannot be mistaken for the
+// response. This method is thread-safe and holds the lock during the
+// entire transaction since a serial line carries one transaction at a time.
+func (t *SerialTransport) Exchange(ctx context.Context, data []byte, read ReadFunc) ([]byte, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.port == nil {
+		return nil, errors.New("not connected")
+	}
+
+	defer func() {
+		t.lastActivity = time.Now()
+	}()
+
+	// Enforce inter-frame silence since the previous transaction
+	if wait := t.cfg.FrameDelay - time.Since(t.lastActivity); wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	// Set deadline from context or use default timeout
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(t.timeout)
+	}
+
+	if err := flushInput(t.port); err != nil {
+		return nil, fmt.Errorf("flush: %w", err)
+	}
+
+	if err := t.port.SetWriteDeadline(deadline); err != nil {
+		return nil, fmt.Errorf("set deadline: %w", err)
+	}
+	if err := writeFull(t.port, data); err != nil {
+		return nil, fmt.Errorf("write: %w", err)
+	}
+
+	return read(t.port, deadline)
+}
@@ -0,0 +1,126 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+
+package transport
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+var baudRates = map[int]uint32{
+	1200:   unix.B1200,
+	2400:   unix.B2400,
+	4800:   unix.B4800,
+	9600:   unix.B9600,
+	19200:  unix.B19200,
+	38400:  unix.B38400,
+	57600:  unix.B57600,
+	115200: unix.B115200,
+	230400: unix.B230400,
+	460800: unix.B460800,
+	921600: unix.B921600,
+}
+
+var dataBitsFlags = map[int]uint32{
+	5: unix.CS5,
+	6: unix.CS6,
+	7: unix.CS7,
+	8: unix.CS8,
+}
+
+// openSerial opens the device in raw mode with the configured line settings.
+// The descriptor is non-blocking so that read and write deadlines are
+// supported through the runtime poller.
+func openSerial(cfg SerialConfig) (*os.File, error) {
+	speed, ok := baudRates[cfg.BaudRate]
+	if !ok {
+		return nil, fmt.Errorf("unsupported baud rate: %d", cfg.BaudRate)
+	}
+	csize, ok := dataBitsFlags[cfg.DataBits]
+	if !ok {
+		return nil, fmt.Errorf("unsupported data bits: %d", cfg.DataBits)
+	}
+	if cfg.StopBits != 1 && cfg.StopBits != 2 {
+		return nil, fmt.Errorf("unsupported stop bits: %d", cfg.StopBits)
+	}
+
+	fd, err := unix.Open(cfg.Device, unix.O_RDWR|unix.O_NOCTTY|unix.O_NONBLOCK|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	tio, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+	if err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("get attributes: %w", err)
+	}
+
+	// Raw mode: no line discipline processing
+	tio.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP |
+		unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON | unix.IXOFF | unix.IXANY | unix.INPCK
+	tio.Oflag &^= unix.OPOST
+	tio.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	tio.Cflag &^= unix.CSIZE | unix.PARENB | unix.PARODD | unix.CSTOPB | unix.CRTSCTS | unix.CBAUD
+	tio.Cflag |= unix.CLOCAL | unix.CREAD | csize | speed
+	tio.Ispeed = speed
+	tio.Ospeed = speed
+
+	switch cfg.Parity {
+	case 'N':
+	case 'E':
+		tio.Cflag |= unix.PARENB
+		tio.Iflag |= unix.INPCK
+	case 'O':
+		tio.Cflag |= unix.PARENB | unix.PARODD
+		tio.Iflag |= unix.INPCK
+	default:
+		unix.Close(fd)
+		return nil, fmt.Errorf("unsupported parity: %q", cfg.Parity)
+	}
+
+	if cfg.StopBits == 2 {
+		tio.Cflag |= unix.CSTOPB
+	}
+
+	tio.Cc[unix.VMIN] = 1
+	tio.Cc[unix.VTIME] = 0
+
+	if err := unix.IoctlSetTermios(fd, unix.TCSETS, tio); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("set attributes: %w", err)
+	}
+
+	return os.NewFile(uintptr(fd), cfg.Device), nil
+}
+
+// flushInput discards data received but not yet read.
+func flushInput(f *os.File) error {
+	rc, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var flushErr error
+	err = rc.Control(func(fd uintptr) {
+		flushErr = unix.IoctlSetInt(int(fd), unix.TCFLSH, unix.TCIFLUSH)
+	})
+	if err != nil {
+		return err
+	}
+	return flushErr
+}
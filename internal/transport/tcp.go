@@ -16,18 +16,20 @@ package transport
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
-	"io"
 	"net"
 	"sync"
 	"time"
 )
 
-// TCPTransport implements a TCP transport for Modbus TCP.
+// TCPTransport implements a TCP transport for Modbus TCP, optionally
+// secured with TLS.
 type TCPTransport struct {
-	addr    string
-	timeout time.Duration
+	addr      string
+	timeout   time.Duration
+	tlsConfig *tls.Config
 
 	mu   sync.Mutex
 	conn net.Conn
@@ -41,6 +43,26 @@ func NewTCPTransport(addr string, timeout time.Duration) *TCPTransport {
 	}
 }
 
+// NewTLSTransport creates a new TCP transport that performs a TLS handshake
+// after connecting. TLS 1.2 is used as the minimum version unless config
+// sets one.
+func NewTLSTransport(addr string, timeout time.Duration, config *tls.Config) *TCPTransport {
+	config = config.Clone()
+	if config.MinVersion == 0 {
+		config.MinVersion = tls.VersionTLS12
+	}
+	if config.ServerName == "" {
+		if host, _, err := net.SplitHostPort(addr); err == nil {
+			config.ServerName = host
+		}
+	}
+	return &TCPTransport{
+		addr:      addr,
+		timeout:   timeout,
+		tlsConfig: config,
+	}
+}
+
 // Connect establishes a TCP connection.
 func (t *TCPTransport) Connect(ctx context.Context) error {
 	t.mu.Lock()
@@ -67,6 +89,21 @@ func (t *TCPTransport) Connect(ctx context.Context) error {
 		tcpConn.SetNoDelay(true) // Disable Nagle's algorithm for low latency
 	}
 
+	if t.tlsConfig != nil {
+		tlsConn := tls.Client(conn, t.tlsConfig)
+		hsCtx := ctx
+		if t.timeout > 0 {
+			var cancel context.CancelFunc
+			hsCtx, cancel = context.WithTimeout(ctx, t.timeout)
+			defer cancel()
+		}
+		if err := tlsConn.HandshakeContext(hsCtx); err != nil {
+			conn.Close()
+			return fmt.Errorf("tls handshake: %w", err)
+		}
+		conn = tlsConn
+	}
+
 	t.conn = conn
 	return nil
 }
@@ -92,9 +129,17 @@ func (t *TCPTransport) IsConnected() bool {
 	return t.conn != nil
 }
 
-// Send sends data and returns the response.
+// Send sends an MBAP frame and returns the response frame.
 // This method is thread-safe and holds the lock during the entire transaction.
 func (t *TCPTransport) Send(ctx context.Context, data []byte) ([]byte, error) {
+	return t.Exchange(ctx, data, readMBAPFrame)
+}
+
+// Exchange writes data and reads a single response frame using read.
+// This method is thread-safe and holds the lock during the entire transaction.
+// The connection is closed on any error since the stream can no longer be
+// assumed to be aligned on a frame boundary.
+func (t *TCPTransport) Exchange(ctx context.Context, data []byte, read ReadFunc) ([]byte, error) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
@@ -113,34 +158,36 @@ func (t *TCPTransport) Send(ctx context.Context, data []byte) ([]byte, error) {
 	}
 
 	// Send request
-	written := 0
-	for written < len(data) {
-		n, err := t.conn.Write(data[written:])
-		if err != nil {
-			t.closeConnLocked()
-			return nil, fmt.Errorf("write: %w", err)
-		}
-		written += n
+	if err := writeFull(t.conn, data); err != nil {
+		t.closeConnLocked()
+		return nil, fmt.Errorf("write: %w", err)
 	}
 
+	response, err := read(t.conn, deadline)
+	if err != nil {
+		t.closeConnLocked()
+		return nil, err
+	}
+	return response, nil
+}
+
+// readMBAPFrame reads a complete MBAP header and PDU.
+func readMBAPFrame(conn Conn, deadline time.Time) ([]byte, error) {
 	// Read MBAP header (7 bytes)
 	header := make([]byte, 7)
-	if err := t.readFullLocked(header); err != nil {
-		t.closeConnLocked()
+	if err := readFull(conn, header); err != nil {
 		return nil, fmt.Errorf("read header: %w", err)
 	}
 
 	// Validate protocol ID (bytes 2-3 must be 0x0000)
 	protocolID := int(header[2])<<8 | int(header[3])
 	if protocolID != 0 {
-		t.closeConnLocked()
 		return nil, fmt.Errorf("invalid protocol ID: %d", protocolID)
 	}
 
 	// Parse length from header (bytes 4-5)
 	length := int(header[4])<<8 | int(header[5])
 	if length < 1 || length > 254 {
-		t.closeConnLocked()
 		return nil, fmt.Errorf("invalid length: %d", length)
 	}
 
@@ -149,8 +196,7 @@ func (t *TCPTransport) Send(ctx context.Context, data []byte) ([]byte, error) {
 	response := make([]byte, 7+pduLen)
 	copy(response, header)
 	if pduLen > 0 {
-		if err := t.readFullLocked(response[7:]); err != nil {
-			t.closeConnLocked()
+		if err := readFull(conn, response[7:]); err != nil {
 			return nil, fmt.Errorf("read pdu: %w", err)
 		}
 	}
@@ -180,20 +226,3 @@ func (t *TCPTransport) closeConnLocked() {
 		t.conn = nil
 	}
 }
-
-// readFullLocked reads exactly len(buf) bytes.
-// Must be called with mu held.
-func (t *TCPTransport) readFullLocked(buf []byte) error {
-	total := 0
-	for total < len(buf) {
-		n, err := t.conn.Read(buf[total:])
-		total += n
-		if err != nil {
-			if err == io.EOF && total == len(buf) {
-				return nil
-			}
-			return err
-		}
-	}
-	return nil
-}
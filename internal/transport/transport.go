@@ -0,0 +1,61 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package transport provides the byte stream transports used by the Modbus client.
+package transport
+
+import (
+	"io"
+	"time"
+)
+
+// Conn is the read side of a transport handed to a ReadFunc.
+type Conn interface {
+	io.Reader
+	SetReadDeadline(t time.Time) error
+}
+
+// ReadFunc reads one complete response frame from conn.
+// The deadline is the overall deadline of the exchange; implementations
+// may set shorter read deadlines but must not exceed it.
+type ReadFunc func(conn Conn, deadline time.Time) ([]byte, error)
+
+// readFull reads exactly len(buf) bytes from conn.
+func readFull(conn Conn, buf []byte) error {
+	total := 0
+	for total < len(buf) {
+		n, err := conn.Read(buf[total:])
+		total += n
+		if err != nil {
+			if err == io.EOF && total == len(buf) {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFull writes all of data to w.
+func writeFull(w io.Writer, data []byte) error {
+	written := 0
+	for written < len(data) {
+		n, err := w.Write(data[written:])
+		if err != nil {
+			return err
+		}
+		written += n
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// maxDatagramSize is the largest Modbus TCP ADU (MBAP header + 253 byte PDU).
+const maxDatagramSize = 260
+
+// MatchFunc reports whether a received datagram is the response to the
+// request being exchanged.
+type MatchFunc func(resp []byte) bool
+
+// UDPTransport implements a UDP transport carrying one MBAP frame per datagram.
+type UDPTransport struct {
+	addr        string
+	timeout     time.Duration
+	retransmits int
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewUDPTransport creates a new UDP transport. A request is retransmitted
+// up to retransmits times if no matching response arrives.
+func NewUDPTransport(addr string, timeout time.Duration, retransmits int) *UDPTransport {
+	if retransmits < 0 {
+		retransmits = 0
+	}
+	return &UDPTransport{
+		addr:        addr,
+		timeout:     timeout,
+		retransmits: retransmits,
+	}
+}
+
+// Connect creates a UDP socket bound to the remote address.
+func (t *UDPTransport) Connect(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.conn != nil {
+		return nil // Already connected
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", t.addr)
+	if err != nil {
+		return fmt.Errorf("udp connect: %w", err)
+	}
+
+	t.conn = conn
+	return nil
+}
+
+// Close closes the UDP socket.
+func (t *UDPTransport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.conn == nil {
+		return nil
+	}
+
+	err := t.conn.Close()
+	t.conn = nil
+	return err
+}
+
+// IsConnected returns true if the socket is open.
+func (t *UDPTransport) IsConnected() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.conn != nil
+}
+
+// Exchange sends data as a single datagram and waits for a datagram
+// accepted by match. Datagrams that do not match, such as late responses
+// to earlier requests, are dropped. The time until the deadline is split
+// evenly between the initial transmission and the retransmissions.
+func (t *UDPTransport) Exchange(ctx context.Context, data []byte, match MatchFunc) ([]byte, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.conn == nil {
+		return nil, errors.New("not connected")
+	}
+
+	// Set deadline from context or use default timeout
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(t.timeout)
+	}
+
+	attempts := t.retransmits + 1
+	interval := time.Until(deadline) / time.Duration(attempts)
+	buf := make([]byte, maxDatagramSize)
+
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if err := t.conn.SetWriteDeadline(deadline); err != nil {
+			return nil, fmt.Errorf("set deadline: %w", err)
+		}
+		if _, err := t.conn.Write(data); err != nil {
+			return nil, fmt.Errorf("write: %w", err)
+		}
+
+		wait := time.Now().Add(interval)
+		if attempt == attempts || wait.After(deadline) {
+			wait = deadline
+		}
+		if err := t.conn.SetReadDeadline(wait); err != nil {
+			return nil, fmt.Errorf("set deadline: %w", err)
+		}
+
+		for {
+			n, err := t.conn.Read(buf)
+			if err != nil {
+				if errors.Is(err, os.ErrDeadlineExceeded) && attempt < attempts {
+					break // Retransmit
+				}
+				return nil, err
+			}
+			if match(buf[:n]) {
+				response := make([]byte, n)
+				copy(response, buf[:n])
+				return response, nil
+			}
+		}
+	}
+
+	return nil, os.ErrDeadlineExceeded
+}
@@ -0,0 +1,462 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modbus
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// structField is a struct field mapped to a value of the device by its
+// `modbus` struct tag.
+type structField struct {
+	index int
+	tag   Tag
+}
+
+// isRegisterBits reports whether the tag holds some bits of a register,
+// which other such tags may share.
+func (t *Tag) isRegisterBits() bool {
+	return !t.isBitTable() && (t.Type == TypeBool || t.Type == TypeBitfield)
+}
+
+// bitMask returns the bits of the register held by a register bits tag.
+func (t *Tag) bitMask() uint16 {
+	if t.Type == TypeBitfield {
+		return uint16(1<<t.Bits-1) << t.Bit
+	}
+	return 1 << t.Bit
+}
+
+// structValue returns the struct v points to. Structs passed by value are
+// accepted when settable is false.
+func structValue(v any, settable bool) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	} else if settable {
+		return reflect.Value{}, fmt.Errorf("%w: expected a pointer to a struct, got %T", ErrInvalidValue, v)
+	}
+	if rv.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("%w: expected a struct, got %T", ErrInvalidValue, v)
+	}
+	return rv, nil
+}
+
+// structFields returns the fields of a struct type that have a `modbus`
+// struct tag, checking that no two of them overlap.
+func structFields(typ reflect.Type) ([]structField, error) {
+	var fields []structField
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		s, ok := f.Tag.Lookup("modbus")
+		if !ok || s == "-" {
+			continue
+		}
+		name := typ.Name() + "." + f.Name
+		if !f.IsExported() {
+			return nil, fmt.Errorf("%w: %s: field is not exported", ErrInvalidTag, name)
+		}
+		tag, err := parseStructTag(name, s, f.Type)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, structField{index: i, tag: tag})
+	}
+
+	for i := range fields {
+		a := &fields[i].tag
+		for j := i + 1; j < len(fields); j++ {
+			if b := &fields[j].tag; a.Overlaps(b) {
+				return nil, fmt.Errorf("%w: %s overlaps %s", ErrInvalidTag, a.Name, b.Name)
+			}
+		}
+	}
+	return fields, nil
+}
+
+// overlaps reports whether two points of the same table share an item.
+func (p Point) overlaps(q Point) bool {
+	return int(p.Address) < q.end() && int(q.Address) < p.end()
+}
+
+// parseStructTag parses a `modbus:"table,address[,option]..."` struct tag.
+// The table is coil, di, hr or ir. Options are a data type name, a byte
+// order name, len=N, bit=N, bits=N, scale=X, offset=X and unit=S; the data
+// type defaults to that of the field.
+func parseStructTag(name, s string, typ reflect.Type) (Tag, error) {
+	tag := Tag{Name: name}
+	parts := strings.Split(s, ",")
+	if len(parts) < 2 {
+		return tag, fmt.Errorf("%w: %s: expected table,address in %q", ErrInvalidTag, name, s)
+	}
+
+	table, err := ParseTable(parts[0])
+	if err != nil {
+		return tag, fmt.Errorf("%w: %s: unknown table %q", ErrInvalidTag, name, parts[0])
+	}
+	tag.Table = table
+
+	addr, err := strconv.ParseUint(strings.TrimSpace(parts[1]), 0, 16)
+	if err != nil {
+		return tag, fmt.Errorf("%w: %s: invalid address %q", ErrInvalidTag, name, parts[1])
+	}
+	tag.Address = uint16(addr)
+
+	typeSet := false
+	for _, opt := range parts[2:] {
+		opt = strings.TrimSpace(opt)
+		key, value, hasValue := strings.Cut(opt, "=")
+		if !hasValue {
+			if dt, err := ParseDataType(opt); err == nil {
+				tag.Type, typeSet = dt, true
+				continue
+			}
+			order, err := ParseByteOrder(opt)
+			if err != nil {
+				return tag, fmt.Errorf("%w: %s: unknown option %q", ErrInvalidTag, name, opt)
+			}
+			tag.ByteOrder = order
+			continue
+		}
+
+		var n uint64
+		switch key {
+		case "len":
+			n, err = strconv.ParseUint(value, 10, 16)
+			tag.Length = uint16(n)
+		case "bit":
+			n, err = strconv.ParseUint(value, 10, 8)
+			tag.Bit = uint8(n)
+		case "bits":
+			n, err = strconv.ParseUint(value, 10, 8)
+			tag.Bits = uint8(n)
+			if !typeSet {
+				tag.Type, typeSet = TypeBitfield, true
+			}
+		case "scale":
+			tag.Scale, err = strconv.ParseFloat(value, 64)
+		case "offset":
+			tag.Offset, err = strconv.ParseFloat(value, 64)
+		case "unit":
+			tag.Unit = value
+		default:
+			return tag, fmt.Errorf("%w: %s: unknown option %q", ErrInvalidTag, name, opt)
+		}
+		if err != nil {
+			return tag, fmt.Errorf("%w: %s: invalid %s %q", ErrInvalidTag, name, key, value)
+		}
+	}
+
+	if !typeSet {
+		dt, ok := kindDataType(typ.Kind())
+		if !ok {
+			return tag, fmt.Errorf("%w: %s: unsupported field type %s", ErrInvalidTag, name, typ)
+		}
+		tag.Type = dt
+	}
+	if err := checkFieldType(&tag, typ); err != nil {
+		return tag, err
+	}
+	return tag, tag.Validate()
+}
+
+// kindDataType returns the data type of a field of the given kind.
+func kindDataType(kind reflect.Kind) (DataType, bool) {
+	switch kind {
+	case reflect.Bool:
+		return TypeBool, true
+	case reflect.Int8, reflect.Int16:
+		return TypeInt16, true
+	case reflect.Uint8, reflect.Uint16:
+		return TypeUint16, true
+	case reflect.Int32:
+		return TypeInt32, true
+	case reflect.Uint32:
+		return TypeUint32, true
+	case reflect.Int, reflect.Int64:
+		return TypeInt64, true
+	case reflect.Uint, reflect.Uint64:
+		return TypeUint64, true
+	case reflect.Float32:
+		return TypeFloat32, true
+	case reflect.Float64:
+		return TypeFloat64, true
+	case reflect.String:
+		return TypeString, true
+	}
+	return 0, false
+}
+
+// checkFieldType checks that a field of type typ can hold the values of
+// the tag.
+func checkFieldType(tag *Tag, typ reflect.Type) error {
+	var ok bool
+	switch kind := typ.Kind(); {
+	case tag.Type == TypeBool:
+		ok = kind == reflect.Bool
+	case tag.Type == TypeString:
+		ok = kind == reflect.String
+	case tag.Type == TypeFloat32 || tag.Type == TypeFloat64 || tag.scaled():
+		ok = kind == reflect.Float32 || kind == reflect.Float64
+	default:
+		ok = isIntKind(kind) || isUintKind(kind) || kind == reflect.Float32 || kind == reflect.Float64
+	}
+	if !ok {
+		return fmt.Errorf("%w: %s: %s field cannot hold %s values", ErrInvalidTag, tag.Name, typ, tag.Type)
+	}
+	return nil
+}
+
+func isIntKind(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Int64
+}
+
+func isUintKind(kind reflect.Kind) bool {
+	return kind >= reflect.Uint && kind <= reflect.Uint64
+}
+
+// setField stores a value returned by Tag.Decode in a field.
+func setField(fv reflect.Value, tag *Tag, value any) error {
+	rv := reflect.ValueOf(value)
+	overflow := false
+	switch kind := fv.Kind(); {
+	case kind == reflect.Bool:
+		fv.SetBool(rv.Bool())
+	case kind == reflect.String:
+		fv.SetString(rv.String())
+	case isIntKind(kind):
+		var n int64
+		if isUintKind(rv.Kind()) {
+			overflow = rv.Uint() > math.MaxInt64
+			n = int64(rv.Uint())
+		} else {
+			n = rv.Int()
+		}
+		overflow = overflow || fv.OverflowInt(n)
+		if !overflow {
+			fv.SetInt(n)
+		}
+	case isUintKind(kind):
+		var u uint64
+		if isIntKind(rv.Kind()) {
+			overflow = rv.Int() < 0
+			u = uint64(rv.Int())
+		} else {
+			u = rv.Uint()
+		}
+		overflow = overflow || fv.OverflowUint(u)
+		if !overflow {
+			fv.SetUint(u)
+		}
+	default: // float
+		var f float64
+		switch {
+		case isIntKind(rv.Kind()):
+			f = float64(rv.Int())
+		case isUintKind(rv.Kind()):
+			f = float64(rv.Uint())
+		default:
+			f = rv.Float()
+		}
+		fv.SetFloat(f)
+	}
+	if overflow {
+		return fmt.Errorf("%w: %s: %v overflows %s", ErrInvalidValue, tag.Name, value, fv.Type())
+	}
+	return nil
+}
+
+// fieldValue returns the value of a field in a form accepted by Tag.Encode.
+func fieldValue(fv reflect.Value) any {
+	switch kind := fv.Kind(); {
+	case kind == reflect.Bool:
+		return fv.Bool()
+	case kind == reflect.String:
+		return fv.String()
+	case isIntKind(kind):
+		return fv.Int()
+	case isUintKind(kind):
+		return fv.Uint()
+	default:
+		return fv.Float()
+	}
+}
+
+// ReadStruct reads the fields of the struct v points to that have a
+// `modbus` struct tag, such as
+//
+//	type Drive struct {
+//		Speed   float32 `modbus:"hr,100,cdab"`
+//		Running bool    `modbus:"coil,5"`
+//	}
+//
+// The tag gives the table (coil, di, hr or ir) and address of the value,
+// followed by options: a data type (see ParseDataType) when it differs
+// from the field type, a byte order (see ParseByteOrder), and len=N, bit=N,
+// bits=N, scale=X, offset=X and unit=S as the fields of Tag. The values are
+// read with as few requests as PlanReads can build. If some requests fail,
+// the other fields are still set and the error is returned.
+func (c *Client) ReadStruct(ctx context.Context, v any, opts ...PlanOption) error {
+	return c.ReadStructWithUnit(ctx, c.UnitID(), v, opts...)
+}
+
+// WriteStruct writes the fields of the struct v that have a `modbus` struct
+// tag; see ReadStruct for the tag syntax. Consecutive coils and registers
+// are written together, and bits of a register with a single mask write
+// (FC22). Fields of discrete inputs and input registers are skipped.
+func (c *Client) WriteStruct(ctx context.Context, v any) error {
+	return c.WriteStructWithUnit(ctx, c.UnitID(), v)
+}
+
+// ReadStructWithUnit reads the fields of a struct using a specific unit ID.
+func (c *Client) ReadStructWithUnit(ctx context.Context, unitID UnitID, v any, opts ...PlanOption) error {
+	rv, err := structValue(v, true)
+	if err != nil {
+		return err
+	}
+	fields, err := structFields(rv.Type())
+	if err != nil {
+		return err
+	}
+
+	tags := make([]*Tag, len(fields))
+	for i := range fields {
+		tags[i] = &fields[i].tag
+	}
+	values, readErr := c.ReadTagsWithUnit(ctx, unitID, tags, opts...)
+	if values == nil {
+		return readErr
+	}
+	for i := range fields {
+		if values[i] == nil {
+			continue
+		}
+		if err := setField(rv.Field(fields[i].index), tags[i], values[i]); err != nil {
+			return err
+		}
+	}
+	return readErr
+}
+
+// WriteStructWithUnit writes the fields of a struct using a specific unit ID.
+func (c *Client) WriteStructWithUnit(ctx context.Context, unitID UnitID, v any) error {
+	rv, err := structValue(v, false)
+	if err != nil {
+		return err
+	}
+	fields, err := structFields(rv.Type())
+	if err != nil {
+		return err
+	}
+
+	coils := make(map[uint16]bool)
+	regs := make(map[uint16]uint16)
+	masks := make(map[uint16][2]uint16) // and, or
+	for i := range fields {
+		f := &fields[i]
+		value := fieldValue(rv.Field(f.index))
+		switch {
+		case f.tag.Table == TableCoils:
+			coils[f.tag.Address] = value.(bool)
+		case f.tag.Table != TableHoldingRegisters:
+			continue
+		case f.tag.isRegisterBits():
+			r, err := f.tag.Encode(value)
+			if err != nil {
+				return err
+			}
+			m, ok := masks[f.tag.Address]
+			if !ok {
+				m[0] = 0xFFFF
+			}
+			m[0] &^= f.tag.bitMask()
+			m[1] |= r[0]
+			masks[f.tag.Address] = m
+		default:
+			r, err := f.tag.Encode(value)
+			if err != nil {
+				return err
+			}
+			for j, reg := range r {
+				regs[f.tag.Address+uint16(j)] = reg
+			}
+		}
+	}
+
+	for _, run := range consecutiveRuns(coils) {
+		if len(run.values) == 1 {
+			err = c.WriteSingleCoilWithUnit(ctx, unitID, run.addr, run.values[0])
+		} else {
+			err = c.WriteCoilRangeWithUnit(ctx, unitID, run.addr, run.values)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	for _, run := range consecutiveRuns(regs) {
+		if len(run.values) == 1 {
+			err = c.WriteSingleRegisterWithUnit(ctx, unitID, run.addr, run.values[0])
+		} else {
+			err = c.WriteHoldingRangeWithUnit(ctx, unitID, run.addr, run.values)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	addrs := make([]uint16, 0, len(masks))
+	for addr := range masks {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i] < addrs[j] })
+	for _, addr := range addrs {
+		m := masks[addr]
+		if err := c.MaskWriteRegisterWithUnit(ctx, unitID, addr, m[0], m[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// valueRun is a run of values at consecutive addresses.
+type valueRun[T any] struct {
+	addr   uint16
+	values []T
+}
+
+// consecutiveRuns splits values by address into runs of consecutive
+// addresses, in address order.
+func consecutiveRuns[T any](values map[uint16]T) []valueRun[T] {
+	addrs := make([]uint16, 0, len(values))
+	for addr := range values {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i] < addrs[j] })
+
+	var runs []valueRun[T]
+	for i, addr := range addrs {
+		if i == 0 || addr != addrs[i-1]+1 {
+			runs = append(runs, valueRun[T]{addr: addr})
+		}
+		run := &runs[len(runs)-1]
+		run.values = append(run.values, values[addr])
+	}
+	return runs
+}
@@ -0,0 +1,166 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// recordingTransport records the function codes of the requests it sends.
+type recordingTransport struct {
+	loopbackTransport
+	requests []FunctionCode
+}
+
+func (t *recordingTransport) Send(ctx context.Context, unitID UnitID, pdu []byte) ([]byte, error) {
+	t.requests = append(t.requests, FunctionCode(pdu[0]))
+	return t.loopbackTransport.Send(ctx, unitID, pdu)
+}
+
+type testDrive struct {
+	Speed     float32 `modbus:"hr,100,cdab"`
+	Setpoint  int32   `modbus:"hr,102"`
+	Mode      uint8   `modbus:"hr,104,bits=4"`
+	Enabled   bool    `modbus:"hr,104,bit=15"`
+	Name      string  `modbus:"hr,105,len=2"`
+	Running   bool    `modbus:"coil,5"`
+	Fault     bool    `modbus:"coil,6"`
+	Temp      float64 `modbus:"ir,10,int16,scale=0.5"`
+	Alarm     bool    `modbus:"di,3"`
+	Untracked int
+}
+
+func TestClientStruct(t *testing.T) {
+	handler := NewMemoryHandler(65536, 65536)
+	handler.SetInputRegister(1, 10, 0xFF38) // -200
+	handler.SetDiscreteInput(1, 3, true)
+	transport := &recordingTransport{loopbackTransport: loopbackTransport{server: NewServer(handler)}}
+	client, err := NewClientWithTransport(transport, WithUnitID(1))
+	if err != nil {
+		t.Fatalf("NewClientWithTransport failed: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	handler.SetHoldingRegister(1, 104, 0x0F00)
+	in := testDrive{Speed: 1480.5, Setpoint: -5, Mode: 9, Enabled: true, Name: "M1", Running: true, Temp: 99, Alarm: false}
+	if err := client.WriteStruct(ctx, &in); err != nil {
+		t.Fatalf("WriteStruct failed: %v", err)
+	}
+	want := []FunctionCode{FuncWriteMultipleCoils, FuncWriteMultipleRegisters, FuncWriteMultipleRegisters, FuncMaskWriteRegister}
+	if fmt.Sprint(transport.requests) != fmt.Sprint(want) {
+		t.Errorf("Expected requests %v, got %v", want, transport.requests)
+	}
+	if regs, _ := handler.ReadHoldingRegisters(1, 100, 7); fmt.Sprintf("%04X", regs) != "[1000 44B9 FFFF FFFB 8F09 4D31 0000]" {
+		t.Errorf("Unexpected registers %04X", regs)
+	}
+
+	transport.requests = nil
+	var out testDrive
+	if err := client.ReadStruct(ctx, &out); err != nil {
+		t.Fatalf("ReadStruct failed: %v", err)
+	}
+	in.Temp, in.Alarm = -100, true
+	if out != in {
+		t.Errorf("Expected %+v, got %+v", in, out)
+	}
+	want = []FunctionCode{FuncReadCoils, FuncReadDiscreteInputs, FuncReadHoldingRegisters, FuncReadInputRegisters}
+	if fmt.Sprint(transport.requests) != fmt.Sprint(want) {
+		t.Errorf("Expected requests %v, got %v", want, transport.requests)
+	}
+
+	var small struct {
+		Value uint8 `modbus:"hr,100,uint32"`
+	}
+	if err := client.ReadStruct(ctx, &small); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Expected ErrInvalidValue for overflow, got %v", err)
+	}
+	if err := client.ReadStruct(ctx, in); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Expected ErrInvalidValue for a struct value, got %v", err)
+	}
+}
+
+func TestStructFieldErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		v    any
+	}{
+		{"overlap", &struct {
+			A uint32 `modbus:"hr,10"`
+			B uint16 `modbus:"hr,11"`
+		}{}},
+		{"overlapping bits", &struct {
+			A bool  `modbus:"hr,10,bit=3"`
+			B uint8 `modbus:"hr,10,bit=2,bits=2"`
+		}{}},
+		{"out of range", &struct {
+			A uint64 `modbus:"ir,65533"`
+		}{}},
+		{"unknown table", &struct {
+			A uint16 `modbus:"xx,1"`
+		}{}},
+		{"unknown option", &struct {
+			A uint16 `modbus:"hr,1,abdc"`
+		}{}},
+		{"bool register type", &struct {
+			A bool `modbus:"hr,1,int16"`
+		}{}},
+		{"scaled integer field", &struct {
+			A int16 `modbus:"hr,1,scale=0.1"`
+		}{}},
+		{"unsupported field", &struct {
+			A []uint16 `modbus:"hr,1"`
+		}{}},
+		{"missing address", &struct {
+			A uint16 `modbus:"hr"`
+		}{}},
+	}
+
+	client, err := NewClientWithTransport(&loopbackTransport{server: NewServer(NewMemoryHandler(65536, 65536))}, WithUnitID(1))
+	if err != nil {
+		t.Fatalf("NewClientWithTransport failed: %v", err)
+	}
+	defer client.Close()
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := client.ReadStruct(context.Background(), tt.v); !errors.Is(err, ErrInvalidTag) {
+				t.Errorf("Expected ErrInvalidTag, got %v", err)
+			}
+		})
+	}
+
+	// Bits of the same register and the same coil in different tables
+	// do not overlap
+	ok := &struct {
+		A bool  `modbus:"hr,10,bit=3"`
+		B uint8 `modbus:"hr,10,bit=4,bits=2"`
+		C bool  `modbus:"coil,10"`
+		D bool  `modbus:"di,10"`
+	}{}
+	if err := client.ReadStruct(context.Background(), ok); err != nil {
+		t.Errorf("ReadStruct failed: %v", err)
+	}
+}
@@ -248,12 +248,26 @@ func (fc FunctionCode) String() string {
 		return "Diagnostics"
 	case FuncGetCommEventCounter:
 		return "GetCommEventCounter"
+	case FuncGetCommEventLog:
+		return "GetCommEventLog"
 	case FuncWriteMultipleCoils:
 		return "WriteMultipleCoils"
 	case FuncWriteMultipleRegisters:
 		return "WriteMultipleRegisters"
 	case FuncReportServerID:
 		return "ReportServerID"
+	case FuncReadFileRecord:
+		return "ReadFileRecord"
+	case FuncWriteFileRecord:
+		return "WriteFileRecord"
+	case FuncMaskWriteRegister:
+		return "MaskWriteRegister"
+	case FuncReadWriteMultipleRegisters:
+		return "ReadWriteMultipleRegisters"
+	case FuncReadFIFOQueue:
+		return "ReadFIFOQueue"
+	case FuncEncapsulatedInterfaceTransport:
+		return "EncapsulatedInterfaceTransport"
 	default:
 		return "Unknown"
 	}
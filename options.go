@@ -15,6 +15,7 @@
 package modbus
 
 import (
+	"crypto/tls"
 	"log/slog"
 	"time"
 )
@@ -42,6 +43,30 @@ type clientOptions struct {
 
 	// Pool settings (for pool creation)
 	poolSize int
+
+	// Framing on TCP connections
+	framing Framing
+
+	// TLS settings (Modbus/TCP Security)
+	tlsConfig *tls.Config
+
+	// Maximum outstanding transactions, 0 disables pipelining
+	maxInFlight int
+
+	// Retransmissions of unanswered UDP requests
+	udpRetransmits int
+
+	// Custom transport, replaces the one built from the address
+	transport Transporter
+
+	// Serial line settings (RTU and ASCII clients)
+	baudRate int
+	dataBits int
+	parity   Parity
+	stopBits int
+
+	// ASCII end-of-frame character
+	asciiDelimiter byte
 }
 
 func defaultOptions() *clientOptions {
@@ -54,6 +79,12 @@ func defaultOptions() *clientOptions {
 		maxRetries:       3,
 		logger:           slog.Default(),
 		poolSize:         5,
+		baudRate:         DefaultBaudRate,
+		dataBits:         8,
+		parity:           ParityEven,
+		stopBits:         1,
+		asciiDelimiter:   DefaultASCIIDelimiter,
+		udpRetransmits:   2,
 	}
 }
 
@@ -127,6 +158,89 @@ func WithPoolSize(size int) Option {
 	}
 }
 
+// WithFraming sets the framing used by TCP clients. FramingRTU talks to
+// serial device servers that tunnel raw RTU frames over TCP.
+func WithFraming(f Framing) Option {
+	return func(o *clientOptions) {
+		o.framing = f
+	}
+}
+
+// WithTLSConfig enables Modbus/TCP Security. The connection is secured with
+// TLS using config, which should carry the client certificate for mutual
+// authentication (config.Certificates) and the trusted CAs (config.RootCAs).
+// Secure servers usually listen on DefaultTLSPort.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(o *clientOptions) {
+		o.tlsConfig = config
+	}
+}
+
+// WithPipelining lets a Modbus TCP client keep up to maxInFlight requests
+// outstanding on its connection. Responses are matched to requests by
+// transaction ID, so concurrent calls are not serialized by the round trip
+// time. The server must support concurrent transactions. A value of 0
+// disables pipelining.
+func WithPipelining(maxInFlight int) Option {
+	return func(o *clientOptions) {
+		o.maxInFlight = maxInFlight
+	}
+}
+
+// WithUDPRetransmits sets how many times a UDP client retransmits a request
+// that has not been answered. The timeout is shared between all attempts.
+func WithUDPRetransmits(n int) Option {
+	return func(o *clientOptions) {
+		o.udpRetransmits = n
+	}
+}
+
+// WithTransport sets a custom transport for the client. It replaces the
+// transport NewClient would build from the address. A transport must not
+// be shared between clients.
+func WithTransport(t Transporter) Option {
+	return func(o *clientOptions) {
+		o.transport = t
+	}
+}
+
+// WithBaudRate sets the serial line speed for RTU and ASCII clients.
+func WithBaudRate(baud int) Option {
+	return func(o *clientOptions) {
+		o.baudRate = baud
+	}
+}
+
+// WithDataBits sets the number of data bits for RTU and ASCII clients.
+func WithDataBits(bits int) Option {
+	return func(o *clientOptions) {
+		o.dataBits = bits
+	}
+}
+
+// WithParity sets the serial line parity for RTU and ASCII clients.
+func WithParity(p Parity) Option {
+	return func(o *clientOptions) {
+		o.parity = p
+	}
+}
+
+// WithStopBits sets the number of stop bits for RTU and ASCII clients.
+func WithStopBits(bits int) Option {
+	return func(o *clientOptions) {
+		o.stopBits = bits
+	}
+}
+
+// WithASCIIDelimiter sets the character that ends ASCII frames (LF by default).
+// It must match the delimiter configured on the server, see
+// DiagChangeASCIIInputDelimiter.
+func WithASCIIDelimiter(delimiter byte) Option {
+	return func(o *clientOptions) {
+		o.asciiDelimiter = delimiter
+	}
+}
+
 // ServerOption is a functional option for configuring the server.
 type ServerOption func(*serverOptions)
 
@@ -210,3 +324,35 @@ func WithClientOptions(opts ...Option) PoolOption {
 		o.clientOpts = opts
 	}
 }
+
+// RangeOption is a functional option for range operations such as
+// Client.ReadHoldingRange.
+type RangeOption func(*rangeOptions)
+
+type rangeOptions struct {
+	chunkSize   int
+	concurrency int
+}
+
+func defaultRangeOptions() *rangeOptions {
+	return &rangeOptions{
+		concurrency: 1,
+	}
+}
+
+// WithChunkSize limits the quantity of each request of a range operation,
+// for devices that accept less than the protocol maximum.
+func WithChunkSize(n int) RangeOption {
+	return func(o *rangeOptions) {
+		o.chunkSize = n
+	}
+}
+
+// WithConcurrency lets a range operation keep up to n requests in flight.
+// It pays off on pipelined connections (see WithPipelining); other
+// transports still send the requests one at a time.
+func WithConcurrency(n int) RangeOption {
+	return func(o *rangeOptions) {
+		o.concurrency = n
+	}
+}
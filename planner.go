@@ -0,0 +1,298 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modbus
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Table identifies one of the four Modbus data tables.
+type Table int
+
+const (
+	TableCoils Table = iota
+	TableDiscreteInputs
+	TableHoldingRegisters
+	TableInputRegisters
+)
+
+// String returns a string representation of Table.
+func (t Table) String() string {
+	switch t {
+	case TableCoils:
+		return "Coils"
+	case TableDiscreteInputs:
+		return "DiscreteInputs"
+	case TableHoldingRegisters:
+		return "HoldingRegisters"
+	case TableInputRegisters:
+		return "InputRegisters"
+	default:
+		return "Unknown"
+	}
+}
+
+// ParseTable parses a table name, ignoring case: coils (coil, c), discrete
+// inputs (di, discrete), holding registers (hr, holding) or input registers
+// (ir, input), as well as the names returned by Table.String.
+func ParseTable(s string) (Table, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "coil", "coils", "c":
+		return TableCoils, nil
+	case "di", "discrete", "discrete-inputs", "discreteinputs":
+		return TableDiscreteInputs, nil
+	case "hr", "holding", "holding-registers", "holdingregisters":
+		return TableHoldingRegisters, nil
+	case "ir", "input", "input-registers", "inputregisters":
+		return TableInputRegisters, nil
+	}
+	return 0, fmt.Errorf("%w: unknown table %q", ErrInvalidValue, s)
+}
+
+// maxReadQuantity returns the protocol limit of a read request on t.
+func (t Table) maxReadQuantity() int {
+	switch t {
+	case TableCoils:
+		return MaxQuantityCoils
+	case TableDiscreteInputs:
+		return MaxQuantityDiscreteInputs
+	default:
+		return MaxQuantityRegisters
+	}
+}
+
+// Point is a value made of Width consecutive items of a table, such as a
+// coil or a 32-bit value held in two registers. A zero Width counts as 1.
+type Point struct {
+	Table   Table
+	Address uint16
+	Width   uint16
+}
+
+// end returns the address following the last item of the point.
+func (p Point) end() int {
+	if p.Width == 0 {
+		return int(p.Address) + 1
+	}
+	return int(p.Address) + int(p.Width)
+}
+
+// AddressRange is an inclusive range of addresses of a table.
+type AddressRange struct {
+	Table Table
+	Start uint16
+	End   uint16
+}
+
+// overlaps reports whether the addresses [start, end) of table t intersect r.
+func (r AddressRange) overlaps(t Table, start, end int) bool {
+	return r.Table == t && start <= int(r.End) && end > int(r.Start)
+}
+
+// PlanOption is a functional option for PlanReads.
+type PlanOption func(*planOptions)
+
+type planOptions struct {
+	maxGap      int
+	maxQuantity int
+	forbidden   []AddressRange
+}
+
+// WithMaxGap lets a request also read up to n unused items between two
+// points, trading a larger response for fewer requests.
+func WithMaxGap(n int) PlanOption {
+	return func(o *planOptions) {
+		o.maxGap = n
+	}
+}
+
+// WithMaxQuantity limits the quantity of each planned request, for devices
+// that accept less than the protocol maximum.
+func WithMaxQuantity(n int) PlanOption {
+	return func(o *planOptions) {
+		o.maxQuantity = n
+	}
+}
+
+// WithForbiddenRange keeps planned requests out of addresses the device
+// refuses to read, such as holes in its register map. It can be given
+// several times.
+func WithForbiddenRange(table Table, start, end uint16) PlanOption {
+	return func(o *planOptions) {
+		o.forbidden = append(o.forbidden, AddressRange{Table: table, Start: start, End: end})
+	}
+}
+
+// PlannedRead is one read request of a ReadPlan.
+type PlannedRead struct {
+	Table    Table
+	Address  uint16
+	Quantity uint16
+	Points   []int // indexes of the points read by the request
+}
+
+// ReadPlan is a set of read requests covering a list of points, built by
+// PlanReads and executed by Client.ExecutePlan.
+type ReadPlan struct {
+	Points   []Point
+	Requests []PlannedRead
+}
+
+// PlanReads merges points into as few FC01-FC04 requests as possible. Two
+// points share a request when the request stays within the maximum
+// quantity, the unused items between them do not exceed the gap tolerance
+// (see WithMaxGap) and no forbidden range is read. A point is never split
+// across requests.
+func PlanReads(points []Point, opts ...PlanOption) (*ReadPlan, error) {
+	options := &planOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	order := make([]int, len(points))
+	for i, p := range points {
+		if p.Table < TableCoils || p.Table > TableInputRegisters {
+			return nil, fmt.Errorf("modbus: point %d: unknown table %d", i, int(p.Table))
+		}
+		if p.end() > 65536 {
+			return nil, fmt.Errorf("%w: point %d exceeds address 65535", ErrInvalidAddress, i)
+		}
+		if p.end()-int(p.Address) > options.quantityLimit(p.Table) {
+			return nil, fmt.Errorf("%w: point %d is wider than a request", ErrInvalidQuantity, i)
+		}
+		if options.isForbidden(p.Table, int(p.Address), p.end()) {
+			return nil, fmt.Errorf("%w: point %d is in a forbidden range", ErrInvalidAddress, i)
+		}
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		pa, pb := points[order[a]], points[order[b]]
+		if pa.Table != pb.Table {
+			return pa.Table < pb.Table
+		}
+		return pa.Address < pb.Address
+	})
+
+	plan := &ReadPlan{Points: points}
+	var cur *PlannedRead
+	var curEnd int
+	for _, i := range order {
+		p := points[i]
+		if cur != nil && cur.Table == p.Table && int(p.Address)-curEnd <= options.maxGap {
+			end := max(curEnd, p.end())
+			if end-int(cur.Address) <= options.quantityLimit(p.Table) &&
+				!options.isForbidden(p.Table, int(cur.Address), end) {
+				cur.Points = append(cur.Points, i)
+				curEnd = end
+				cur.Quantity = uint16(end - int(cur.Address))
+				continue
+			}
+		}
+		plan.Requests = append(plan.Requests, PlannedRead{
+			Table:    p.Table,
+			Address:  p.Address,
+			Quantity: uint16(p.end() - int(p.Address)),
+			Points:   []int{i},
+		})
+		cur = &plan.Requests[len(plan.Requests)-1]
+		curEnd = p.end()
+	}
+	return plan, nil
+}
+
+// quantityLimit returns the largest request quantity on table t.
+func (o *planOptions) quantityLimit(t Table) int {
+	if o.maxQuantity > 0 && o.maxQuantity < t.maxReadQuantity() {
+		return o.maxQuantity
+	}
+	return t.maxReadQuantity()
+}
+
+// isForbidden reports whether the addresses [start, end) of table t
+// intersect a forbidden range.
+func (o *planOptions) isForbidden(t Table, start, end int) bool {
+	for _, r := range o.forbidden {
+		if r.overlaps(t, start, end) {
+			return true
+		}
+	}
+	return false
+}
+
+// PointValue is the value read for a Point.
+type PointValue struct {
+	Bits      []bool   // coils and discrete inputs
+	Registers []uint16 // holding and input registers
+	Err       error    // error of the request that covers the point
+}
+
+// ExecutePlan runs the requests of plan and returns the value of every point,
+// in the order of plan.Points. WithConcurrency keeps several requests in
+// flight. If some requests fail, their points carry the error and the
+// failures are also reported as a *RangeError.
+func (c *Client) ExecutePlan(ctx context.Context, plan *ReadPlan, opts ...RangeOption) ([]PointValue, error) {
+	return c.ExecutePlanWithUnit(ctx, c.UnitID(), plan, opts...)
+}
+
+// ExecutePlanWithUnit runs a read plan using a specific unit ID.
+func (c *Client) ExecutePlanWithUnit(ctx context.Context, unitID UnitID, plan *ReadPlan, opts ...RangeOption) ([]PointValue, error) {
+	options := defaultRangeOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	chunks := make([]chunk, len(plan.Requests))
+	for i, req := range plan.Requests {
+		chunks[i] = chunk{addr: req.Address, qty: req.Quantity}
+	}
+
+	values := make([]PointValue, len(plan.Points))
+	err := runChunks(ctx, chunks, options, func(ctx context.Context, i int) error {
+		req := plan.Requests[i]
+		var bits []bool
+		var regs []uint16
+		var err error
+		switch req.Table {
+		case TableCoils:
+			bits, err = c.ReadCoilsWithUnit(ctx, unitID, req.Address, req.Quantity)
+		case TableDiscreteInputs:
+			bits, err = c.ReadDiscreteInputsWithUnit(ctx, unitID, req.Address, req.Quantity)
+		case TableHoldingRegisters:
+			regs, err = c.ReadHoldingRegistersWithUnit(ctx, unitID, req.Address, req.Quantity)
+		case TableInputRegisters:
+			regs, err = c.ReadInputRegistersWithUnit(ctx, unitID, req.Address, req.Quantity)
+		}
+
+		for _, pi := range req.Points {
+			p := plan.Points[pi]
+			if err != nil {
+				values[pi].Err = err
+				continue
+			}
+			from := int(p.Address) - int(req.Address)
+			to := p.end() - int(req.Address)
+			if bits != nil {
+				values[pi].Bits = append([]bool(nil), bits[from:to]...)
+			} else {
+				values[pi].Registers = append([]uint16(nil), regs[from:to]...)
+			}
+		}
+		return err
+	})
+	return values, err
+}
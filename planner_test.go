@@ -0,0 +1,152 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestPlanReads(t *testing.T) {
+	hr := func(addr, width uint16) Point {
+		return Point{Table: TableHoldingRegisters, Address: addr, Width: width}
+	}
+
+	tests := []struct {
+		name     string
+		points   []Point
+		opts     []PlanOption
+		expected string
+	}{
+		{"adjacent", []Point{hr(3, 1), hr(0, 1), hr(1, 2)}, nil, "[HoldingRegisters 0+4 [1 2 0]]"},
+		{"overlapping", []Point{hr(0, 2), hr(1, 2)}, nil, "[HoldingRegisters 0+3 [0 1]]"},
+		{"zero width", []Point{hr(0, 0), hr(1, 0)}, nil, "[HoldingRegisters 0+2 [0 1]]"},
+		{"gap", []Point{hr(0, 1), hr(10, 1)}, nil, "[HoldingRegisters 0+1 [0]] [HoldingRegisters 10+1 [1]]"},
+		{"gap tolerance", []Point{hr(0, 1), hr(10, 1)}, []PlanOption{WithMaxGap(9)}, "[HoldingRegisters 0+11 [0 1]]"},
+		{"max quantity", []Point{hr(0, 1), hr(123, 2)}, []PlanOption{WithMaxGap(200)}, "[HoldingRegisters 0+125 [0 1]]"},
+		{"over max quantity", []Point{hr(0, 1), hr(124, 2)}, []PlanOption{WithMaxGap(200)}, "[HoldingRegisters 0+1 [0]] [HoldingRegisters 124+2 [1]]"},
+		{"device max quantity", []Point{hr(0, 1), hr(2, 2)}, []PlanOption{WithMaxGap(10), WithMaxQuantity(3)}, "[HoldingRegisters 0+1 [0]] [HoldingRegisters 2+2 [1]]"},
+		{"forbidden", []Point{hr(0, 1), hr(5, 1)}, []PlanOption{WithMaxGap(10), WithForbiddenRange(TableHoldingRegisters, 2, 3)}, "[HoldingRegisters 0+1 [0]] [HoldingRegisters 5+1 [1]]"},
+		{"forbidden other table", []Point{hr(0, 1), hr(5, 1)}, []PlanOption{WithMaxGap(10), WithForbiddenRange(TableInputRegisters, 2, 3)}, "[HoldingRegisters 0+6 [0 1]]"},
+		{"tables", []Point{hr(0, 1), {Table: TableCoils, Address: 1}}, []PlanOption{WithMaxGap(10)}, "[Coils 1+1 [1]] [HoldingRegisters 0+1 [0]]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := PlanReads(tt.points, tt.opts...)
+			if err != nil {
+				t.Fatalf("PlanReads failed: %v", err)
+			}
+			var got string
+			for i, req := range plan.Requests {
+				if i > 0 {
+					got += " "
+				}
+				got += fmt.Sprintf("[%s %d+%d %v]", req.Table, req.Address, req.Quantity, req.Points)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPlanReadsInvalidPoints(t *testing.T) {
+	tests := []struct {
+		name   string
+		point  Point
+		opts   []PlanOption
+		target error
+	}{
+		{"too wide", Point{Table: TableHoldingRegisters, Width: 126}, nil, ErrInvalidQuantity},
+		{"wider than device limit", Point{Table: TableCoils, Width: 20}, []PlanOption{WithMaxQuantity(16)}, ErrInvalidQuantity},
+		{"end of address space", Point{Table: TableInputRegisters, Address: 65535, Width: 2}, nil, ErrInvalidAddress},
+		{"forbidden", Point{Table: TableHoldingRegisters, Address: 9, Width: 2}, []PlanOption{WithForbiddenRange(TableHoldingRegisters, 10, 19)}, ErrInvalidAddress},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := PlanReads([]Point{tt.point}, tt.opts...); !errors.Is(err, tt.target) {
+				t.Errorf("Expected %v, got %v", tt.target, err)
+			}
+		})
+	}
+
+	if _, err := PlanReads([]Point{{Table: Table(7)}}); err == nil {
+		t.Error("Expected error for unknown table")
+	}
+}
+
+func TestClientExecutePlan(t *testing.T) {
+	handler := NewMemoryHandler(65536, 65536)
+	for i := uint16(0); i < 200; i++ {
+		handler.SetHoldingRegister(1, i, 1000+i)
+	}
+	handler.SetCoil(1, 7, true)
+	server := NewServer(handler)
+	server.HandleFunc(FuncReadInputRegisters, func(unitID UnitID, pdu []byte) ([]byte, error) {
+		return nil, NewModbusError(FuncReadInputRegisters, ExceptionServerDeviceBusy)
+	})
+
+	client, err := NewClientWithTransport(&loopbackTransport{server: server}, WithUnitID(1))
+	if err != nil {
+		t.Fatalf("NewClientWithTransport failed: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	points := []Point{
+		{Table: TableHoldingRegisters, Address: 150, Width: 2},
+		{Table: TableCoils, Address: 7},
+		{Table: TableHoldingRegisters, Address: 3},
+		{Table: TableInputRegisters, Address: 0},
+		{Table: TableHoldingRegisters, Address: 5, Width: 2},
+	}
+	plan, err := PlanReads(points, WithMaxGap(4))
+	if err != nil {
+		t.Fatalf("PlanReads failed: %v", err)
+	}
+	if len(plan.Requests) != 4 {
+		t.Fatalf("Expected 4 requests, got %d", len(plan.Requests))
+	}
+
+	values, err := client.ExecutePlan(ctx, plan, WithConcurrency(2))
+	var rangeErr *RangeError
+	if !errors.As(err, &rangeErr) || len(rangeErr.Chunks) != 1 || !IsException(err, ExceptionServerDeviceBusy) {
+		t.Fatalf("Expected one busy chunk, got %v", err)
+	}
+
+	if fmt.Sprint(values[0].Registers) != "[1150 1151]" {
+		t.Errorf("Point 0: expected [1150 1151], got %v", values[0].Registers)
+	}
+	if len(values[1].Bits) != 1 || !values[1].Bits[0] {
+		t.Errorf("Point 1: expected [true], got %v", values[1].Bits)
+	}
+	if fmt.Sprint(values[2].Registers) != "[1003]" {
+		t.Errorf("Point 2: expected [1003], got %v", values[2].Registers)
+	}
+	if !IsException(values[3].Err, ExceptionServerDeviceBusy) || values[3].Registers != nil {
+		t.Errorf("Point 3: expected busy error, got %+v", values[3])
+	}
+	if fmt.Sprint(values[4].Registers) != "[1005 1006]" {
+		t.Errorf("Point 4: expected [1005 1006], got %v", values[4].Registers)
+	}
+}
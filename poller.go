@@ -0,0 +1,380 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// PollGroup is a set of tags read together at a fixed interval.
+type PollGroup struct {
+	Name     string
+	Interval time.Duration
+
+	// UnitID is the unit the tags are read from; zero uses the unit of
+	// the client.
+	UnitID UnitID
+
+	Tags []*Tag
+
+	// Deadband filters the changes of values from the last update
+	// delivered. The zero Deadband delivers every change.
+	Deadband Deadband
+
+	// StaleAfter is the time without a successful read of a tag after
+	// which its good value becomes uncertain with ReasonStale; zero counts
+	// as three intervals.
+	StaleAfter time.Duration
+}
+
+// Update reports the new value or quality of a polled tag.
+type Update struct {
+	Group string
+	Tag   *Tag
+	DataValue
+}
+
+// PollStats holds the counters of a poll group.
+type PollStats struct {
+	Polls        int64
+	Errors       int64
+	Overruns     int64 // polls that ended after the next one was due
+	LastDuration time.Duration
+}
+
+// PollerOption is a functional option for NewPoller and NewPoolPoller.
+type PollerOption func(*pollerOptions)
+
+type pollerOptions struct {
+	jitter  time.Duration
+	handler func(Update)
+	buffer  int
+}
+
+// WithJitter delays every poll by a random duration up to d, so that
+// groups with the same interval do not poll at the same time.
+func WithJitter(d time.Duration) PollerOption {
+	return func(o *pollerOptions) {
+		o.jitter = d
+	}
+}
+
+// WithUpdateHandler delivers updates to fn instead of the Updates channel.
+// Calls for a group are sequential; calls for different groups may be
+// concurrent.
+func WithUpdateHandler(fn func(Update)) PollerOption {
+	return func(o *pollerOptions) {
+		o.handler = fn
+	}
+}
+
+// WithUpdateBuffer sets the capacity of the Updates channel.
+func WithUpdateBuffer(n int) PollerOption {
+	return func(o *pollerOptions) {
+		o.buffer = n
+	}
+}
+
+// Poller reads groups of tags at independent intervals and delivers an
+// Update, by exception, when a value changes by more than the deadband of
+// its group or when its quality changes.
+type Poller struct {
+	acquire func(context.Context) (*Client, error)
+	release func(*Client)
+	opts    *pollerOptions
+	updates chan Update
+
+	mu      sync.Mutex
+	groups  []*pollGroup
+	running bool
+}
+
+// pollGroup is the state of a poll group.
+type pollGroup struct {
+	PollGroup
+
+	// mu serializes the changes of the tag states and their delivery.
+	mu         sync.Mutex
+	states     []tagState
+	staleTimer *time.Timer
+
+	polls        Counter
+	errors       Counter
+	overruns     Counter
+	lastDuration atomic.Int64
+}
+
+// tagState is the state of a polled tag.
+type tagState struct {
+	current   DataValue
+	delivered DataValue // value of the last update
+	lastGood  time.Time // time of the last successful read
+}
+
+// NewPoller creates a poller reading through a connected client.
+func NewPoller(client *Client, opts ...PollerOption) *Poller {
+	return newPoller(func(context.Context) (*Client, error) { return client, nil }, func(*Client) {}, opts)
+}
+
+// NewPoolPoller creates a poller reading through clients of a pool, so
+// that groups are polled over several connections.
+func NewPoolPoller(pool *Pool, opts ...PollerOption) *Poller {
+	return newPoller(pool.Get, pool.Put, opts)
+}
+
+func newPoller(acquire func(context.Context) (*Client, error), release func(*Client), opts []PollerOption) *Poller {
+	options := &pollerOptions{buffer: 64}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return &Poller{
+		acquire: acquire,
+		release: release,
+		opts:    options,
+		updates: make(chan Update, max(options.buffer, 0)),
+	}
+}
+
+// AddGroup adds a poll group before Run.
+func (p *Poller) AddGroup(g PollGroup) error {
+	if g.Interval <= 0 {
+		return fmt.Errorf("%w: poll group %s: interval must be positive", ErrInvalidValue, g.Name)
+	}
+	if len(g.Tags) == 0 {
+		return fmt.Errorf("%w: poll group %s has no tags", ErrInvalidValue, g.Name)
+	}
+	points := make([]Point, len(g.Tags))
+	for i, tag := range g.Tags {
+		if err := tag.Validate(); err != nil {
+			return err
+		}
+		points[i] = tag.Point()
+	}
+	if _, err := PlanReads(points); err != nil {
+		return err
+	}
+	if g.StaleAfter <= 0 {
+		g.StaleAfter = 3 * g.Interval
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.running {
+		return errors.New("modbus: poller already started")
+	}
+	for _, other := range p.groups {
+		if other.Name == g.Name {
+			return fmt.Errorf("%w: duplicate poll group %s", ErrInvalidValue, g.Name)
+		}
+	}
+	p.groups = append(p.groups, &pollGroup{PollGroup: g, states: make([]tagState, len(g.Tags))})
+	return nil
+}
+
+// Updates returns the channel updates are delivered to, unless
+// WithUpdateHandler is set. Polls wait while the channel is full; it is
+// closed when Run returns.
+func (p *Poller) Updates() <-chan Update {
+	return p.updates
+}
+
+// Stats returns the counters of a poll group.
+func (p *Poller) Stats(group string) (PollStats, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, g := range p.groups {
+		if g.Name == group {
+			return PollStats{
+				Polls:        g.polls.Value(),
+				Errors:       g.errors.Value(),
+				Overruns:     g.overruns.Value(),
+				LastDuration: time.Duration(g.lastDuration.Load()),
+			}, true
+		}
+	}
+	return PollStats{}, false
+}
+
+// Run polls the groups until ctx is done and returns its error. A poller
+// runs once.
+func (p *Poller) Run(ctx context.Context) error {
+	p.mu.Lock()
+	if p.running {
+		p.mu.Unlock()
+		return errors.New("modbus: poller already started")
+	}
+	p.running = true
+	groups := p.groups
+	p.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, g := range groups {
+		g.staleTimer = time.AfterFunc(g.StaleAfter, func() { p.markStale(ctx, g) })
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.runGroup(ctx, g)
+		}()
+	}
+	<-ctx.Done()
+	wg.Wait()
+
+	for _, g := range groups {
+		g.staleTimer.Stop()
+		// Wait for a running stale check
+		g.mu.Lock()
+		g.mu.Unlock()
+	}
+	close(p.updates)
+	return ctx.Err()
+}
+
+// runGroup polls a group at its interval. Polls that end after the next
+// one was due count as overruns, and the missed polls are skipped.
+func (p *Poller) runGroup(ctx context.Context, g *pollGroup) {
+	next := time.Now()
+	timer := time.NewTimer(p.jitter())
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		p.poll(ctx, g)
+
+		next = next.Add(g.Interval)
+		if late := time.Since(next); late > 0 {
+			g.overruns.Add(1)
+			next = next.Add((late/g.Interval + 1) * g.Interval)
+		}
+		timer.Reset(time.Until(next) + p.jitter())
+	}
+}
+
+// jitter returns a random poll delay.
+func (p *Poller) jitter() time.Duration {
+	if p.opts.jitter <= 0 {
+		return 0
+	}
+	return rand.N(p.opts.jitter)
+}
+
+// poll reads the tags of a group and delivers the changes.
+func (p *Poller) poll(ctx context.Context, g *pollGroup) {
+	start := time.Now()
+	var values []any
+	var errs []error
+	client, err := p.acquire(ctx)
+	if err == nil {
+		unitID := g.UnitID
+		if unitID == 0 {
+			unitID = client.UnitID()
+		}
+		values, errs, err = client.readTags(ctx, unitID, g.Tags)
+		p.release(client)
+	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	now := time.Now()
+	g.polls.Add(1)
+	g.lastDuration.Store(int64(now.Sub(start)))
+	if err != nil {
+		g.errors.Add(1)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for i := range g.Tags {
+		if values != nil && values[i] != nil {
+			g.states[i].lastGood = now
+			p.update(ctx, g, i, NewDataValue(values[i], nil, now))
+		} else {
+			// The quality of a tag follows the error of its own request
+			tagErr := err
+			if errs != nil && errs[i] != nil {
+				tagErr = errs[i]
+			}
+			p.update(ctx, g, i, NewDataValue(g.states[i].current.Value, tagErr, now))
+		}
+	}
+	g.scheduleStale()
+}
+
+// markStale downgrades the good values of a group whose tags have not been
+// read successfully for StaleAfter.
+func (p *Poller) markStale(ctx context.Context, g *pollGroup) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if ctx.Err() != nil {
+		return
+	}
+	now := time.Now()
+	for i := range g.states {
+		st := &g.states[i]
+		if st.current.Quality == QualityGood && !st.lastGood.IsZero() && now.Sub(st.lastGood) >= g.StaleAfter {
+			p.update(ctx, g, i, DataValue{Value: st.current.Value, Timestamp: now, Quality: QualityUncertain, Reason: ReasonStale})
+		}
+	}
+	g.scheduleStale()
+}
+
+// scheduleStale arms the stale timer of a group for the first good value
+// that becomes stale. g.mu must be held.
+func (g *pollGroup) scheduleStale() {
+	var due time.Time
+	for _, st := range g.states {
+		if st.current.Quality != QualityGood || st.lastGood.IsZero() {
+			continue
+		}
+		if t := st.lastGood.Add(g.StaleAfter); due.IsZero() || t.Before(due) {
+			due = t
+		}
+	}
+	if !due.IsZero() {
+		g.staleTimer.Reset(time.Until(due))
+	}
+}
+
+// update records the value of tag i of a group and delivers an update if
+// the deadband of the group reports it. g.mu must be held.
+func (p *Poller) update(ctx context.Context, g *pollGroup, i int, dv DataValue) {
+	st := &g.states[i]
+	st.current = dv
+	if !g.Deadband.Report(st.delivered, dv) {
+		return
+	}
+	st.delivered = dv
+
+	u := Update{Group: g.Name, Tag: g.Tags[i], DataValue: dv}
+	if p.opts.handler != nil {
+		p.opts.handler(u)
+		return
+	}
+	select {
+	case p.updates <- u:
+	case <-ctx.Done():
+	}
+}
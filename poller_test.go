@@ -0,0 +1,287 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modbus
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// faultyTransport is a loopback transport that can fail or delay requests.
+type faultyTransport struct {
+	loopbackTransport
+	fail  atomic.Bool
+	delay atomic.Int64
+}
+
+func (t *faultyTransport) Send(ctx context.Context, unitID UnitID, pdu []byte) ([]byte, error) {
+	if d := time.Duration(t.delay.Load()); d > 0 {
+		select {
+		case <-time.After(d):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if t.fail.Load() {
+		return nil, ErrConnectionClosed
+	}
+	return t.loopbackTransport.Send(ctx, unitID, pdu)
+}
+
+func newPollerTestClient(t *testing.T) (*Client, *MemoryHandler, *faultyTransport) {
+	t.Helper()
+	handler := NewMemoryHandler(65536, 65536)
+	transport := &faultyTransport{loopbackTransport: loopbackTransport{server: NewServer(handler)}}
+	client, err := NewClientWithTransport(transport, WithUnitID(1), WithTimeout(time.Second), WithMaxRetries(0))
+	if err != nil {
+		t.Fatalf("NewClientWithTransport failed: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	return client, handler, transport
+}
+
+// nextUpdate waits for the next update of the poller.
+func nextUpdate(t *testing.T, updates <-chan Update) Update {
+	t.Helper()
+	select {
+	case u := <-updates:
+		return u
+	case <-time.After(2 * time.Second):
+		t.Fatal("No update received")
+		return Update{}
+	}
+}
+
+func TestPoller(t *testing.T) {
+	client, handler, _ := newPollerTestClient(t)
+	handler.SetHoldingRegister(1, 0, 100)
+	handler.SetCoil(1, 4, true)
+
+	level := &Tag{Name: "level", Table: TableHoldingRegisters, Address: 0, Type: TypeUint16}
+	pump := &Tag{Name: "pump", Table: TableCoils, Address: 4, Type: TypeBool}
+
+	poller := NewPoller(client, WithJitter(time.Millisecond))
+	if err := poller.AddGroup(PollGroup{Name: "fast", Interval: 5 * time.Millisecond, Tags: []*Tag{level}, Deadband: Deadband{Absolute: 5}}); err != nil {
+		t.Fatalf("AddGroup failed: %v", err)
+	}
+	if err := poller.AddGroup(PollGroup{Name: "slow", Interval: 20 * time.Millisecond, Tags: []*Tag{pump}}); err != nil {
+		t.Fatalf("AddGroup failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- poller.Run(ctx) }()
+
+	initial := map[string]any{}
+	for len(initial) < 2 {
+		u := nextUpdate(t, poller.Updates())
+		if u.Quality != QualityGood {
+			t.Fatalf("Expected good quality, got %s", u.Quality)
+		}
+		initial[u.Tag.Name] = u.Value
+	}
+	if initial["level"] != uint16(100) || initial["pump"] != true {
+		t.Errorf("Unexpected initial values %v", initial)
+	}
+
+	// Changes within the deadband are filtered, changes from the last
+	// delivered value beyond it are not
+	handler.SetHoldingRegister(1, 0, 104)
+	time.Sleep(30 * time.Millisecond)
+	handler.SetHoldingRegister(1, 0, 106)
+	if u := nextUpdate(t, poller.Updates()); u.Tag != level || u.Value != uint16(106) {
+		t.Errorf("Expected level 106, got %s %v", u.Tag.Name, u.Value)
+	}
+
+	handler.SetCoil(1, 4, false)
+	if u := nextUpdate(t, poller.Updates()); u.Tag != pump || u.Value != false || u.Group != "slow" {
+		t.Errorf("Expected pump off, got %s %v", u.Tag.Name, u.Value)
+	}
+
+	if err := poller.AddGroup(PollGroup{Name: "late", Interval: time.Second, Tags: []*Tag{level}}); err == nil {
+		t.Error("Expected an error adding a group to a running poller")
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	for range poller.Updates() {
+	}
+	if stats, ok := poller.Stats("fast"); !ok || stats.Polls < 2 {
+		t.Errorf("Expected several polls, got %+v", stats)
+	}
+}
+
+func TestPollerQuality(t *testing.T) {
+	client, handler, transport := newPollerTestClient(t)
+	handler.SetHoldingRegister(1, 10, 7)
+	tag := &Tag{Name: "speed", Table: TableHoldingRegisters, Address: 10, Type: TypeUint16}
+
+	updates := make(chan Update, 16)
+	poller := NewPoller(client, WithUpdateHandler(func(u Update) { updates <- u }))
+	if err := poller.AddGroup(PollGroup{Name: "drive", Interval: 10 * time.Millisecond, Tags: []*Tag{tag}, StaleAfter: 40 * time.Millisecond}); err != nil {
+		t.Fatalf("AddGroup failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go poller.Run(ctx)
+
+	if u := nextUpdate(t, updates); u.Quality != QualityGood || u.Value != uint16(7) {
+		t.Fatalf("Expected good 7, got %s %v", u.Quality, u.Value)
+	}
+
+	// A failed poll keeps the last value, reported once
+	transport.fail.Store(true)
+	u := nextUpdate(t, updates)
+	if u.Quality != QualityBad || u.Reason != ReasonCommFailure || u.Value != uint16(7) || !errors.Is(u.Err, ErrConnectionClosed) {
+		t.Fatalf("Expected bad 7, got %s %s %v (%v)", u.Quality, u.Reason, u.Value, u.Err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	transport.fail.Store(false)
+	if u := nextUpdate(t, updates); u.Quality != QualityGood {
+		t.Fatalf("Expected good after recovery, got %s", u.Quality)
+	}
+
+	// A poll slower than StaleAfter makes values stale, and overruns
+	transport.delay.Store(int64(100 * time.Millisecond))
+	if u := nextUpdate(t, updates); u.Quality != QualityUncertain || u.Reason != ReasonStale || u.Value != uint16(7) {
+		t.Fatalf("Expected stale 7, got %s %s %v", u.Quality, u.Reason, u.Value)
+	}
+	transport.delay.Store(0)
+	if u := nextUpdate(t, updates); u.Quality != QualityGood {
+		t.Fatalf("Expected good after a slow poll, got %s", u.Quality)
+	}
+	if stats, _ := poller.Stats("drive"); stats.Overruns == 0 || stats.Errors == 0 {
+		t.Errorf("Expected overruns and errors, got %+v", stats)
+	}
+}
+
+func TestPollerStalePerTag(t *testing.T) {
+	client, handler, transport := newPollerTestClient(t)
+	handler.SetHoldingRegister(1, 0, 3)
+	transport.server.HandleFunc(FuncReadInputRegisters, func(unitID UnitID, pdu []byte) ([]byte, error) {
+		return nil, NewModbusError(FuncReadInputRegisters, ExceptionServerDeviceFailure)
+	})
+	good := &Tag{Name: "good", Table: TableHoldingRegisters, Address: 0, Type: TypeUint16}
+	failing := &Tag{Name: "failing", Table: TableInputRegisters, Address: 0, Type: TypeUint16}
+
+	updates := make(chan Update, 16)
+	poller := NewPoller(client, WithUpdateHandler(func(u Update) { updates <- u }))
+	if err := poller.AddGroup(PollGroup{Name: "g", Interval: 10 * time.Millisecond, Tags: []*Tag{good, failing}, StaleAfter: 30 * time.Millisecond}); err != nil {
+		t.Fatalf("AddGroup failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go poller.Run(ctx)
+
+	// A tag that keeps failing does not make the other tags of the group stale
+	deadline := time.After(150 * time.Millisecond)
+	for {
+		select {
+		case u := <-updates:
+			if u.Tag == good && u.Quality != QualityGood {
+				t.Fatalf("Expected good quality for %s, got %s %s", u.Tag.Name, u.Quality, u.Reason)
+			}
+			if u.Tag == failing && u.Reason != ReasonDeviceFailure {
+				t.Fatalf("Expected device failure for %s, got %s %s", u.Tag.Name, u.Quality, u.Reason)
+			}
+			continue
+		case <-deadline:
+		}
+		break
+	}
+
+	// Once its reads stop, the good tag becomes stale
+	handler.SetHoldingRegister(1, 0, 4)
+	transport.delay.Store(int64(100 * time.Millisecond))
+	for {
+		u := nextUpdate(t, updates)
+		if u.Tag == good && u.Quality != QualityGood {
+			if u.Reason != ReasonStale {
+				t.Fatalf("Expected stale, got %s %s", u.Quality, u.Reason)
+			}
+			break
+		}
+	}
+}
+
+func TestPollerReasonPerTag(t *testing.T) {
+	client, _, transport := newPollerTestClient(t)
+	transport.server.HandleFunc(FuncReadInputRegisters, func(unitID UnitID, pdu []byte) ([]byte, error) {
+		return nil, NewModbusError(FuncReadInputRegisters, ExceptionIllegalDataAddress)
+	})
+	transport.server.HandleFunc(FuncReadHoldingRegisters, func(unitID UnitID, pdu []byte) ([]byte, error) {
+		return nil, NewModbusError(FuncReadHoldingRegisters, ExceptionServerDeviceBusy)
+	})
+	missing := &Tag{Name: "missing", Table: TableInputRegisters, Address: 0, Type: TypeUint16}
+	busy := &Tag{Name: "busy", Table: TableHoldingRegisters, Address: 0, Type: TypeUint16}
+
+	updates := make(chan Update, 16)
+	poller := NewPoller(client, WithUpdateHandler(func(u Update) { updates <- u }))
+	if err := poller.AddGroup(PollGroup{Name: "g", Interval: time.Second, Tags: []*Tag{missing, busy}}); err != nil {
+		t.Fatalf("AddGroup failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go poller.Run(ctx)
+
+	// Each tag takes the reason of its own request
+	want := map[*Tag]Reason{missing: ReasonConfigError, busy: ReasonDeviceBusy}
+	for range want {
+		u := nextUpdate(t, updates)
+		if u.Reason != want[u.Tag] {
+			t.Errorf("Expected %s for %s, got %s (%v)", want[u.Tag], u.Tag.Name, u.Reason, u.Err)
+		}
+	}
+}
+
+func TestPollerGroupErrors(t *testing.T) {
+	client, _, _ := newPollerTestClient(t)
+	poller := NewPoller(client)
+	tag := &Tag{Name: "a", Table: TableHoldingRegisters, Address: 0, Type: TypeUint16}
+
+	tests := []struct {
+		name  string
+		group PollGroup
+	}{
+		{"no interval", PollGroup{Name: "g", Tags: []*Tag{tag}}},
+		{"no tags", PollGroup{Name: "g", Interval: time.Second}},
+		{"invalid tag", PollGroup{Name: "g", Interval: time.Second, Tags: []*Tag{{Table: TableCoils, Type: TypeFloat32}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := poller.AddGroup(tt.group); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+
+	if err := poller.AddGroup(PollGroup{Name: "g", Interval: time.Second, Tags: []*Tag{tag}}); err != nil {
+		t.Fatalf("AddGroup failed: %v", err)
+	}
+	if err := poller.AddGroup(PollGroup{Name: "g", Interval: time.Second, Tags: []*Tag{tag}}); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Expected ErrInvalidValue for a duplicate group, got %v", err)
+	}
+}
@@ -0,0 +1,310 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profile
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/edgeo-scada/modbus"
+	"gopkg.in/yaml.v3"
+)
+
+// pointSpec is a point as written in a YAML profile or a CSV row.
+type pointSpec struct {
+	Name        string `yaml:"name"`
+	Table       string `yaml:"table"`
+	Address     string `yaml:"address"`
+	Type        string `yaml:"type"`
+	Order       string `yaml:"order"`
+	ByteOrder   string `yaml:"byte_order"`
+	WordOrder   string `yaml:"word_order"`
+	Length      string `yaml:"length"`
+	Bit         string `yaml:"bit"`
+	Bits        string `yaml:"bits"`
+	Scale       string `yaml:"scale"`
+	Offset      string `yaml:"offset"`
+	Unit        string `yaml:"unit"`
+	Access      string `yaml:"access"`
+	Description string `yaml:"description"`
+}
+
+// Values of the byte_order and word_order keys. A big-endian word order
+// stores the most significant register first.
+var (
+	byteOrders = map[string]modbus.ByteOrder{
+		"big":    modbus.BigEndian,
+		"little": modbus.LittleEndian,
+	}
+	wordOrders = map[string]modbus.WordOrder{
+		"big":    modbus.HighWordFirst,
+		"high":   modbus.HighWordFirst,
+		"little": modbus.LowWordFirst,
+		"low":    modbus.LowWordFirst,
+	}
+)
+
+// profileSpec is a YAML profile.
+type profileSpec struct {
+	Name   string      `yaml:"name"`
+	UnitID uint8       `yaml:"unit_id"`
+	Points []pointSpec `yaml:"points"`
+}
+
+// Load reads a profile from a .yaml, .yml or .csv file. Without a name in
+// the file, the profile is named after the file, without extension.
+func Load(path string) (*Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var p *Profile
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		p, err = ParseYAML(data)
+	case ".csv":
+		p, err = ParseCSV(bytes.NewReader(data))
+	default:
+		return nil, fmt.Errorf("%w: %s: unsupported file type %q", ErrInvalidProfile, path, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if p.Name == "" {
+		p.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return p, nil
+}
+
+// ParseYAML parses and validates a YAML profile:
+//
+//	name: inverter
+//	unit_id: 3
+//	points:
+//	  - name: ac_power
+//	    table: ir
+//	    address: 30775
+//	    type: int32
+//	    order: CDAB
+//	    scale: 0.1
+//	    unit: W
+//	    access: r
+//
+// The keys of a point are the fields of modbus.Tag and Point: table (see
+// modbus.ParseTable), address, type (see modbus.ParseDataType), order (see
+// modbus.ParseByteOrder), length, bit, bits, scale, offset, unit, access
+// (see ParseAccess) and description. Devices documented with separate byte
+// and word orders use byte_order (big or little) and word_order (big or
+// high, little or low) instead of order. Without a type, coils and discrete
+// inputs are bool, registers with bits a bitfield, registers with a bit
+// bool and other registers uint16. Unknown keys are rejected.
+func ParseYAML(data []byte) (*Profile, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var spec profileSpec
+	if err := dec.Decode(&spec); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProfile, err)
+	}
+
+	p := &Profile{Name: spec.Name, UnitID: modbus.UnitID(spec.UnitID)}
+	for i := range spec.Points {
+		pt, err := spec.Points[i].point()
+		if err != nil {
+			return nil, fmt.Errorf("%w: point %d: %v", ErrInvalidProfile, i+1, err)
+		}
+		p.Points = append(p.Points, pt)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ParseCSV parses and validates a CSV profile. The first row names the
+// columns, in any order: name, table and address are required, and type,
+// order, byte_order, word_order, length, bit, bits, scale, offset, unit,
+// access and description are optional, as in YAML profiles. Other columns are ignored, so that
+// vendor spreadsheets can be exported as they are. Lines starting with #
+// are comments.
+func ParseCSV(r io.Reader) (*Profile, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: reading header: %v", ErrInvalidProfile, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, h := range header {
+		name := strings.ToLower(strings.TrimSpace(h))
+		name = strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+		columns[name] = i
+	}
+	for _, name := range []string{"name", "table", "address"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: missing column %q", ErrInvalidProfile, name)
+		}
+	}
+
+	p := &Profile{}
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidProfile, err)
+		}
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		spec := pointSpec{
+			Name:        field("name"),
+			Table:       field("table"),
+			Address:     field("address"),
+			Type:        field("type"),
+			Order:       field("order"),
+			ByteOrder:   field("byte_order"),
+			WordOrder:   field("word_order"),
+			Length:      field("length"),
+			Bit:         field("bit"),
+			Bits:        field("bits"),
+			Scale:       field("scale"),
+			Offset:      field("offset"),
+			Unit:        field("unit"),
+			Access:      field("access"),
+			Description: field("description"),
+		}
+		pt, err := spec.point()
+		if err != nil {
+			line, _ := cr.FieldPos(0)
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidProfile, line, err)
+		}
+		p.Points = append(p.Points, pt)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// point converts the specification to a point, applying the default type
+// described in ParseYAML.
+func (s *pointSpec) point() (Point, error) {
+	pt := Point{Description: s.Description}
+	pt.Name = s.Name
+	pt.Unit = s.Unit
+	if s.Name == "" {
+		return pt, errors.New("missing name")
+	}
+
+	var err error
+	if pt.Table, err = modbus.ParseTable(s.Table); err != nil {
+		return pt, fmt.Errorf("%s: unknown table %q", s.Name, s.Table)
+	}
+	if s.Address == "" {
+		return pt, fmt.Errorf("%s: missing address", s.Name)
+	}
+
+	var n uint64
+	for _, f := range []struct {
+		key, value string
+		bits       int
+		set        func(uint64)
+	}{
+		{"address", s.Address, 16, func(n uint64) { pt.Address = uint16(n) }},
+		{"length", s.Length, 16, func(n uint64) { pt.Length = uint16(n) }},
+		{"bit", s.Bit, 8, func(n uint64) { pt.Bit = uint8(n) }},
+		{"bits", s.Bits, 8, func(n uint64) { pt.Bits = uint8(n) }},
+	} {
+		if f.value == "" {
+			continue
+		}
+		if n, err = strconv.ParseUint(f.value, 0, f.bits); err != nil {
+			return pt, fmt.Errorf("%s: invalid %s %q", s.Name, f.key, f.value)
+		}
+		f.set(n)
+	}
+
+	for _, f := range []struct {
+		key, value string
+		dst        *float64
+	}{
+		{"scale", s.Scale, &pt.Scale},
+		{"offset", s.Offset, &pt.Offset},
+	} {
+		if f.value == "" {
+			continue
+		}
+		if *f.dst, err = strconv.ParseFloat(f.value, 64); err != nil {
+			return pt, fmt.Errorf("%s: invalid %s %q", s.Name, f.key, f.value)
+		}
+	}
+
+	switch {
+	case s.Type != "":
+		if pt.Type, err = modbus.ParseDataType(s.Type); err != nil {
+			return pt, fmt.Errorf("%s: unknown type %q", s.Name, s.Type)
+		}
+	case pt.Table == modbus.TableCoils || pt.Table == modbus.TableDiscreteInputs:
+		pt.Type = modbus.TypeBool
+	case s.Bits != "":
+		pt.Type = modbus.TypeBitfield
+	case s.Bit != "":
+		pt.Type = modbus.TypeBool
+	default:
+		pt.Type = modbus.TypeUint16
+	}
+
+	if s.Order != "" && (s.ByteOrder != "" || s.WordOrder != "") {
+		return pt, fmt.Errorf("%s: order with byte_order or word_order", s.Name)
+	}
+	if s.Order != "" {
+		if pt.ByteOrder, err = modbus.ParseByteOrder(s.Order); err != nil {
+			return pt, fmt.Errorf("%s: unknown order %q", s.Name, s.Order)
+		}
+	}
+	if s.ByteOrder != "" {
+		var ok bool
+		if pt.ByteOrder, ok = byteOrders[strings.ToLower(s.ByteOrder)]; !ok {
+			return pt, fmt.Errorf("%s: unknown byte_order %q", s.Name, s.ByteOrder)
+		}
+	}
+	if s.WordOrder != "" {
+		var ok bool
+		if pt.WordOrder, ok = wordOrders[strings.ToLower(s.WordOrder)]; !ok {
+			return pt, fmt.Errorf("%s: unknown word_order %q", s.Name, s.WordOrder)
+		}
+	}
+	if s.Access != "" {
+		if pt.Access, err = ParseAccess(s.Access); err != nil {
+			return pt, fmt.Errorf("%s: unknown access %q", s.Name, s.Access)
+		}
+	}
+	return pt, nil
+}
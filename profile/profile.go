@@ -0,0 +1,221 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package profile loads device profiles: the named points of a device
+// register map, described in YAML or CSV, each mapped to a modbus.Tag.
+package profile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/edgeo-scada/modbus"
+)
+
+var (
+	// ErrInvalidProfile indicates a malformed profile or point definition.
+	ErrInvalidProfile = errors.New("profile: invalid profile")
+
+	// ErrUnknownPoint indicates a point name that no profile defines.
+	ErrUnknownPoint = errors.New("profile: unknown point")
+
+	// ErrAccessDenied indicates a read of a write-only point or a write
+	// of a read-only point.
+	ErrAccessDenied = errors.New("profile: access denied")
+)
+
+// Access tells whether a point can be read, written, or both.
+type Access uint8
+
+const (
+	AccessRead Access = 1 << iota
+	AccessWrite
+
+	AccessReadWrite = AccessRead | AccessWrite
+)
+
+// String returns a string representation of Access.
+func (a Access) String() string {
+	switch a {
+	case AccessRead:
+		return "r"
+	case AccessWrite:
+		return "w"
+	case AccessReadWrite:
+		return "rw"
+	default:
+		return ""
+	}
+}
+
+// ParseAccess parses r, w or rw, or the longer forms ro, read, wo, write,
+// read-write and read/write, ignoring case.
+func ParseAccess(s string) (Access, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "r", "ro", "read", "read-only":
+		return AccessRead, nil
+	case "w", "wo", "write", "write-only":
+		return AccessWrite, nil
+	case "rw", "read-write", "read/write":
+		return AccessReadWrite, nil
+	}
+	return 0, fmt.Errorf("%w: unknown access %q", ErrInvalidProfile, s)
+}
+
+// Point is a named value of a device. The embedded Tag holds the point
+// name and how its value is stored.
+type Point struct {
+	modbus.Tag
+
+	// Access defaults to read-write for coils and holding registers and
+	// to read-only for discrete inputs and input registers.
+	Access      Access
+	Description string
+}
+
+// access returns the access of the point, applying the default.
+func (p *Point) access() Access {
+	if p.Access != 0 {
+		return p.Access
+	}
+	if p.Table == modbus.TableCoils || p.Table == modbus.TableHoldingRegisters {
+		return AccessReadWrite
+	}
+	return AccessRead
+}
+
+// Readable reports whether the point can be read.
+func (p *Point) Readable() bool {
+	return p.access()&AccessRead != 0
+}
+
+// Writable reports whether the point can be written.
+func (p *Point) Writable() bool {
+	return p.access()&AccessWrite != 0
+}
+
+// Profile is the register map of a device.
+type Profile struct {
+	Name string
+
+	// UnitID is the unit of the device; zero uses the unit of the client.
+	UnitID modbus.UnitID
+
+	Points []Point
+}
+
+// Point returns the point with the given name, or nil.
+func (p *Profile) Point(name string) *Point {
+	for i := range p.Points {
+		if p.Points[i].Name == name {
+			return &p.Points[i]
+		}
+	}
+	return nil
+}
+
+// Validate checks the points of the profile: their names must be unique,
+// their tags valid, their access allowed by their table, and no two of
+// them may overlap.
+func (p *Profile) Validate() error {
+	names := make(map[string]bool, len(p.Points))
+	for i := range p.Points {
+		pt := &p.Points[i]
+		if pt.Name == "" {
+			return fmt.Errorf("%w: point %d has no name", ErrInvalidProfile, i+1)
+		}
+		if names[pt.Name] {
+			return fmt.Errorf("%w: duplicate point %s", ErrInvalidProfile, pt.Name)
+		}
+		names[pt.Name] = true
+
+		if err := pt.Validate(); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidProfile, err)
+		}
+		if pt.Access&^AccessReadWrite != 0 {
+			return fmt.Errorf("%w: %s: unknown access %d", ErrInvalidProfile, pt.Name, pt.Access)
+		}
+		if pt.Writable() && (pt.Table == modbus.TableDiscreteInputs || pt.Table == modbus.TableInputRegisters) {
+			return fmt.Errorf("%w: %s: %s are read-only", ErrInvalidProfile, pt.Name, pt.Table)
+		}
+	}
+
+	for i := range p.Points {
+		a := &p.Points[i]
+		for j := i + 1; j < len(p.Points); j++ {
+			if b := &p.Points[j]; a.Overlaps(&b.Tag) {
+				return fmt.Errorf("%w: %s overlaps %s", ErrInvalidProfile, a.Name, b.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// unit returns the unit ID used to reach the device through c.
+func (p *Profile) unit(c *modbus.Client) modbus.UnitID {
+	if p.UnitID != 0 {
+		return p.UnitID
+	}
+	return c.UnitID()
+}
+
+// Read reads the values of points of the profile with as few requests as
+// possible; see modbus.Client.ReadTags for the returned values.
+func (p *Profile) Read(ctx context.Context, c *modbus.Client, points []*Point, opts ...modbus.PlanOption) ([]any, error) {
+	tags := make([]*modbus.Tag, len(points))
+	for i, pt := range points {
+		if !pt.Readable() {
+			return nil, fmt.Errorf("%w: %s is write-only", ErrAccessDenied, pt.Name)
+		}
+		tags[i] = &pt.Tag
+	}
+	return c.ReadTagsWithUnit(ctx, p.unit(c), tags, opts...)
+}
+
+// Write writes the value of a point of the profile; see
+// modbus.Client.WriteTag for the accepted values.
+func (p *Profile) Write(ctx context.Context, c *modbus.Client, point *Point, value any) error {
+	if !point.Writable() {
+		return fmt.Errorf("%w: %s is read-only", ErrAccessDenied, point.Name)
+	}
+	return c.WriteTagWithUnit(ctx, p.unit(c), &point.Tag, value)
+}
+
+// Profiles is a set of profiles whose points are named
+// "profile.point".
+type Profiles []*Profile
+
+// Profile returns the profile with the given name, or nil.
+func (ps Profiles) Profile(name string) *Profile {
+	for _, p := range ps {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// Lookup returns the profile and point of a "profile.point" name.
+func (ps Profiles) Lookup(name string) (*Profile, *Point, error) {
+	for _, p := range ps {
+		if point, ok := strings.CutPrefix(name, p.Name+"."); ok {
+			if pt := p.Point(point); pt != nil {
+				return p, pt, nil
+			}
+		}
+	}
+	return nil, nil, fmt.Errorf("%w: %s", ErrUnknownPoint, name)
+}
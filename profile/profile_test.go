@@ -0,0 +1,303 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profile
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/edgeo-scada/modbus"
+)
+
+const inverterYAML = `
+name: inverter
+unit_id: 3
+points:
+  - name: ac_power
+    table: ir
+    address: 0x10
+    type: s32
+    order: cdab
+    scale: 0.5
+    unit: W
+  - name: status
+    table: ir
+    address: 18
+    bits: 4
+  - name: alarm
+    table: ir
+    address: 18
+    bit: 15
+  - name: setpoint
+    table: hr
+    address: 100
+    type: uint16
+    access: rw
+    description: Active power limit
+  - name: model
+    table: hr
+    address: 101
+    type: string
+    length: 2
+    access: r
+  - name: enable
+    table: coil
+    address: 5
+`
+
+func TestParseYAML(t *testing.T) {
+	p, err := ParseYAML([]byte(inverterYAML))
+	if err != nil {
+		t.Fatalf("ParseYAML failed: %v", err)
+	}
+	if p.Name != "inverter" || p.UnitID != 3 || len(p.Points) != 6 {
+		t.Fatalf("Unexpected profile %s, unit %d, %d points", p.Name, p.UnitID, len(p.Points))
+	}
+
+	power := p.Point("ac_power")
+	if power.Table != modbus.TableInputRegisters || power.Address != 16 || power.Type != modbus.TypeInt32 ||
+		power.ByteOrder != modbus.OrderCDAB || power.Scale != 0.5 || power.Unit != "W" {
+		t.Errorf("Unexpected ac_power %+v", power)
+	}
+	if power.Writable() || !power.Readable() {
+		t.Errorf("Expected ac_power to be read-only")
+	}
+	if st := p.Point("status"); st.Type != modbus.TypeBitfield || st.Bits != 4 {
+		t.Errorf("Expected a 4-bit bitfield, got %+v", st)
+	}
+	if alarm := p.Point("alarm"); alarm.Type != modbus.TypeBool || alarm.Bit != 15 {
+		t.Errorf("Expected bit 15, got %+v", alarm)
+	}
+	if sp := p.Point("setpoint"); !sp.Writable() || sp.Description != "Active power limit" {
+		t.Errorf("Unexpected setpoint %+v", sp)
+	}
+	if p.Point("model").Writable() {
+		t.Errorf("Expected model to be read-only")
+	}
+	if enable := p.Point("enable"); enable.Type != modbus.TypeBool || !enable.Writable() {
+		t.Errorf("Unexpected enable %+v", enable)
+	}
+	if p.Point("missing") != nil {
+		t.Errorf("Expected no point")
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	data := `# exported from the vendor register map
+Name,Table,Address,Type,Order,Scale,Unit,Access,Vendor Notes
+ac_power,ir,16,s32,CDAB,0.5,W,,see manual
+setpoint,hr,100,,,,,rw,
+"dc voltage",input,20,float32,,,V,r,
+`
+	p, err := ParseCSV(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("ParseCSV failed: %v", err)
+	}
+	if len(p.Points) != 3 {
+		t.Fatalf("Expected 3 points, got %d", len(p.Points))
+	}
+	if power := p.Point("ac_power"); power.Type != modbus.TypeInt32 || power.ByteOrder != modbus.OrderCDAB || power.Scale != 0.5 {
+		t.Errorf("Unexpected ac_power %+v", power)
+	}
+	if sp := p.Point("setpoint"); sp.Type != modbus.TypeUint16 || sp.Access != AccessReadWrite {
+		t.Errorf("Unexpected setpoint %+v", sp)
+	}
+	if dc := p.Point("dc voltage"); dc == nil || dc.Type != modbus.TypeFloat32 || dc.Unit != "V" {
+		t.Errorf("Unexpected dc voltage %+v", dc)
+	}
+
+	_, err = ParseCSV(strings.NewReader("name,table,address\nok,hr,1\nbad,hr,70000\n"))
+	if !errors.Is(err, ErrInvalidProfile) || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("Expected an error on line 3, got %v", err)
+	}
+	if _, err := ParseCSV(strings.NewReader("name,address\nok,1\n")); !errors.Is(err, ErrInvalidProfile) {
+		t.Errorf("Expected ErrInvalidProfile for a missing column, got %v", err)
+	}
+
+	// Separate byte and word order columns
+	p, err = ParseCSV(strings.NewReader("Name,Table,Address,Type,Byte Order,Word Order\nenergy,ir,0,uint32,little,low\nfreq,ir,2,float32,,little\n"))
+	if err != nil {
+		t.Fatalf("ParseCSV failed: %v", err)
+	}
+	if energy := p.Point("energy"); energy.ByteOrder != modbus.LittleEndian || energy.WordOrder != modbus.LowWordFirst {
+		t.Errorf("Unexpected energy %+v", energy)
+	}
+	if freq := p.Point("freq"); freq.ByteOrder != modbus.BigEndian || freq.WordOrder != modbus.LowWordFirst {
+		t.Errorf("Unexpected freq %+v", freq)
+	}
+}
+
+func TestProfileErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		points string
+	}{
+		{"overlap", `
+  - {name: a, table: hr, address: 10, type: uint32}
+  - {name: b, table: hr, address: 11}`},
+		{"overlapping bits", `
+  - {name: a, table: hr, address: 10, bit: 3}
+  - {name: b, table: hr, address: 10, bit: 2, bits: 2}`},
+		{"duplicate", `
+  - {name: a, table: hr, address: 10}
+  - {name: a, table: hr, address: 11}`},
+		{"writable input", `
+  - {name: a, table: ir, address: 10, access: rw}`},
+		{"unknown table", `
+  - {name: a, table: xx, address: 10}`},
+		{"unknown type", `
+  - {name: a, table: hr, address: 10, type: int128}`},
+		{"unknown order", `
+  - {name: a, table: hr, address: 10, order: ACBD}`},
+		{"order with word order", `
+  - {name: a, table: hr, address: 10, type: uint32, order: CDAB, word_order: low}`},
+		{"unknown byte order", `
+  - {name: a, table: hr, address: 10, byte_order: middle}`},
+		{"unknown word order", `
+  - {name: a, table: hr, address: 10, word_order: CDAB}`},
+		{"unknown access", `
+  - {name: a, table: hr, address: 10, access: x}`},
+		{"unknown key", `
+  - {name: a, table: hr, address: 10, scaling: 2}`},
+		{"missing address", `
+  - {name: a, table: hr}`},
+		{"missing name", `
+  - {table: hr, address: 10}`},
+		{"invalid scale", `
+  - {name: a, table: hr, address: 10, scale: x}`},
+		{"out of range", `
+  - {name: a, table: hr, address: 65535, type: float32}`},
+		{"bitfield too wide", `
+  - {name: a, table: hr, address: 10, bit: 8, bits: 9}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseYAML([]byte("points:" + tt.points)); !errors.Is(err, ErrInvalidProfile) {
+				t.Errorf("Expected ErrInvalidProfile, got %v", err)
+			}
+		})
+	}
+
+	// Disjoint bits of a register and the same address in different tables
+	ok := `points:
+  - {name: a, table: hr, address: 10, bit: 3}
+  - {name: b, table: hr, address: 10, bit: 4, bits: 2}
+  - {name: c, table: coil, address: 10}
+  - {name: d, table: di, address: 10}`
+	if _, err := ParseYAML([]byte(ok)); err != nil {
+		t.Errorf("ParseYAML failed: %v", err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"inverter.yaml": inverterYAML,
+		"meter.csv":     "name,table,address,type\nenergy,ir,0,uint32\n",
+		"notes.txt":     "",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	inverter, err := Load(filepath.Join(dir, "inverter.yaml"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	meter, err := Load(filepath.Join(dir, "meter.csv"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if meter.Name != "meter" {
+		t.Errorf("Expected the file name as profile name, got %q", meter.Name)
+	}
+	if _, err := Load(filepath.Join(dir, "notes.txt")); !errors.Is(err, ErrInvalidProfile) {
+		t.Errorf("Expected ErrInvalidProfile for a text file, got %v", err)
+	}
+
+	profiles := Profiles{inverter, meter}
+	if profiles.Profile("meter") != meter {
+		t.Errorf("Expected the meter profile")
+	}
+	p, pt, err := profiles.Lookup("meter.energy")
+	if err != nil || p != meter || pt.Name != "energy" {
+		t.Errorf("Unexpected lookup %v %v %v", p, pt, err)
+	}
+	for _, name := range []string{"meter.power", "pump.energy", "energy"} {
+		if _, _, err := profiles.Lookup(name); !errors.Is(err, ErrUnknownPoint) {
+			t.Errorf("%s: expected ErrUnknownPoint, got %v", name, err)
+		}
+	}
+}
+
+func TestProfileReadWrite(t *testing.T) {
+	handler := modbus.NewMemoryHandler(65536, 65536)
+	handler.SetInputRegister(3, 16, 0x0BB8) // 3000 low word first
+	handler.SetInputRegister(3, 17, 0x0000)
+	handler.SetInputRegister(3, 18, 0x8005)
+	server := modbus.NewServer(handler)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	go server.Serve(listener)
+	defer server.Close()
+
+	client, err := modbus.NewClient(listener.Addr().String(), modbus.WithUnitID(1))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	p, err := ParseYAML([]byte(inverterYAML))
+	if err != nil {
+		t.Fatalf("ParseYAML failed: %v", err)
+	}
+
+	if err := p.Write(ctx, client, p.Point("setpoint"), 1500); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if regs, _ := handler.ReadHoldingRegisters(3, 100, 1); regs[0] != 1500 {
+		t.Errorf("Expected 1500 at unit 3, got %d", regs[0])
+	}
+	if err := p.Write(ctx, client, p.Point("ac_power"), 1.0); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("Expected ErrAccessDenied, got %v", err)
+	}
+
+	points := []*Point{p.Point("ac_power"), p.Point("status"), p.Point("alarm"), p.Point("setpoint")}
+	values, err := p.Read(ctx, client, points)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	want := []any{1500.0, uint16(5), true, uint16(1500)}
+	for i := range want {
+		if values[i] != want[i] {
+			t.Errorf("%s: expected %v (%T), got %v (%T)", points[i].Name, want[i], want[i], values[i], values[i])
+		}
+	}
+}
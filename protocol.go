@@ -15,6 +15,7 @@
 package modbus
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
@@ -232,12 +233,17 @@ func BuildGetCommEventCounterPDU() []byte {
 	return []byte{byte(FuncGetCommEventCounter)}
 }
 
+// BuildGetCommEventLogPDU builds a PDU for getting the comm event log (FC12).
+func BuildGetCommEventLogPDU() []byte {
+	return []byte{byte(FuncGetCommEventLog)}
+}
+
 // BuildWriteMultipleCoilsPDU builds a PDU for writing multiple coils (FC15).
 func BuildWriteMultipleCoilsPDU(addr uint16, values []bool) ([]byte, error) {
-	qty := uint16(len(values))
-	if qty < 1 || qty > MaxQuantityCoils {
-		return nil, fmt.Errorf("%w: quantity must be 1-%d", ErrInvalidQuantity, MaxQuantityCoils)
+	if len(values) < 1 || len(values) > MaxQuantityWriteCoils {
+		return nil, fmt.Errorf("%w: quantity must be 1-%d", ErrInvalidQuantity, MaxQuantityWriteCoils)
 	}
+	qty := uint16(len(values))
 	if uint32(addr)+uint32(qty) > 65536 {
 		return nil, fmt.Errorf("%w: address range exceeds 65535", ErrInvalidAddress)
 	}
@@ -285,6 +291,118 @@ func BuildReportServerIDPDU() []byte {
 	return []byte{byte(FuncReportServerID)}
 }
 
+// BuildReadFileRecordPDU builds a PDU for reading file records (FC20).
+// Each sub-request reads Length registers of a file starting at Record.
+func BuildReadFileRecordPDU(reqs []FileSubRequest) ([]byte, error) {
+	if len(reqs) == 0 {
+		return nil, fmt.Errorf("%w: at least one sub-request required", ErrInvalidQuantity)
+	}
+	pdu := make([]byte, 2, 2+7*len(reqs))
+	pdu[0] = byte(FuncReadFileRecord)
+
+	respSize := 2
+	for _, r := range reqs {
+		if r.File == 0 || r.Record > MaxFileRecordNumber {
+			return nil, fmt.Errorf("%w: file must be 1-65535 and record 0-%d", ErrInvalidAddress, MaxFileRecordNumber)
+		}
+		if r.Length < 1 {
+			return nil, fmt.Errorf("%w: record length must be at least 1", ErrInvalidQuantity)
+		}
+		respSize += 2 + 2*int(r.Length)
+		pdu = appendFileSubRequest(pdu, r.File, r.Record, r.Length)
+	}
+	if respSize > MaxPDUSize || len(pdu)-2 > 0xF5 {
+		return nil, fmt.Errorf("%w: file records exceed the PDU size", ErrInvalidQuantity)
+	}
+	pdu[1] = byte(len(pdu) - 2)
+	return pdu, nil
+}
+
+// BuildWriteFileRecordPDU builds a PDU for writing file records (FC21).
+func BuildWriteFileRecordPDU(records []FileRecord) ([]byte, error) {
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: at least one record required", ErrInvalidQuantity)
+	}
+	pdu := make([]byte, 2, MaxPDUSize)
+	pdu[0] = byte(FuncWriteFileRecord)
+
+	for _, r := range records {
+		if r.File == 0 || r.Record > MaxFileRecordNumber {
+			return nil, fmt.Errorf("%w: file must be 1-65535 and record 0-%d", ErrInvalidAddress, MaxFileRecordNumber)
+		}
+		if len(r.Data) == 0 || len(pdu)+7+2*len(r.Data) > MaxPDUSize {
+			return nil, fmt.Errorf("%w: file records must hold 1 register and fit in the PDU", ErrInvalidQuantity)
+		}
+		pdu = appendFileSubRequest(pdu, r.File, r.Record, uint16(len(r.Data)))
+		pdu = append(pdu, Uint16sToBytes(r.Data)...)
+	}
+	pdu[1] = byte(len(pdu) - 2)
+	return pdu, nil
+}
+
+// appendFileSubRequest appends the reference type, file number, record
+// number and record length of a file record sub-request.
+func appendFileSubRequest(pdu []byte, file, record, length uint16) []byte {
+	pdu = append(pdu, FileRecordReferenceType)
+	pdu = binary.BigEndian.AppendUint16(pdu, file)
+	pdu = binary.BigEndian.AppendUint16(pdu, record)
+	return binary.BigEndian.AppendUint16(pdu, length)
+}
+
+// BuildMaskWriteRegisterPDU builds a PDU for a mask write register (FC22).
+// The register becomes (current AND andMask) OR (orMask AND NOT andMask).
+func BuildMaskWriteRegisterPDU(addr, andMask, orMask uint16) []byte {
+	pdu := make([]byte, 7)
+	pdu[0] = byte(FuncMaskWriteRegister)
+	binary.BigEndian.PutUint16(pdu[1:3], addr)
+	binary.BigEndian.PutUint16(pdu[3:5], andMask)
+	binary.BigEndian.PutUint16(pdu[5:7], orMask)
+	return pdu
+}
+
+// BuildReadWriteMultipleRegistersPDU builds a PDU for reading and writing
+// multiple registers in one transaction (FC23).
+func BuildReadWriteMultipleRegistersPDU(readAddr, readQty, writeAddr uint16, values []uint16) ([]byte, error) {
+	if readQty < 1 || readQty > MaxQuantityRegisters {
+		return nil, fmt.Errorf("%w: read quantity must be 1-%d", ErrInvalidQuantity, MaxQuantityRegisters)
+	}
+	writeQty := uint16(len(values))
+	if writeQty < 1 || writeQty > MaxQuantityReadWriteRegisters {
+		return nil, fmt.Errorf("%w: write quantity must be 1-%d", ErrInvalidQuantity, MaxQuantityReadWriteRegisters)
+	}
+	if uint32(readAddr)+uint32(readQty) > 65536 || uint32(writeAddr)+uint32(writeQty) > 65536 {
+		return nil, fmt.Errorf("%w: address range exceeds 65535", ErrInvalidAddress)
+	}
+	byteCount := writeQty * 2
+	pdu := make([]byte, 10+byteCount)
+	pdu[0] = byte(FuncReadWriteMultipleRegisters)
+	binary.BigEndian.PutUint16(pdu[1:3], readAddr)
+	binary.BigEndian.PutUint16(pdu[3:5], readQty)
+	binary.BigEndian.PutUint16(pdu[5:7], writeAddr)
+	binary.BigEndian.PutUint16(pdu[7:9], writeQty)
+	pdu[9] = byte(byteCount)
+
+	// Pack registers
+	for i, v := range values {
+		binary.BigEndian.PutUint16(pdu[10+i*2:], v)
+	}
+	return pdu, nil
+}
+
+// BuildReadFIFOQueuePDU builds a PDU for reading a FIFO queue (FC24).
+func BuildReadFIFOQueuePDU(addr uint16) []byte {
+	pdu := make([]byte, 3)
+	pdu[0] = byte(FuncReadFIFOQueue)
+	binary.BigEndian.PutUint16(pdu[1:3], addr)
+	return pdu
+}
+
+// BuildReadDeviceIdentificationPDU builds a PDU for reading device
+// identification (FC43/14), starting at objectID.
+func BuildReadDeviceIdentificationPDU(readCode, objectID uint8) []byte {
+	return []byte{byte(FuncEncapsulatedInterfaceTransport), MEIReadDeviceIdentification, readCode, objectID}
+}
+
 // Response parsing helpers
 
 // ParseCoilsResponse parses a coils response (FC01/FC02) and returns the values.
@@ -305,7 +423,7 @@ func ParseCoilsResponse(pdu []byte, qty uint16) ([]bool, error) {
 	return values, nil
 }
 
-// ParseRegistersResponse parses a registers response (FC03/FC04) and returns the values.
+// ParseRegistersResponse parses a registers response (FC03/FC04/FC23) and returns the values.
 func ParseRegistersResponse(pdu []byte, qty uint16) ([]uint16, error) {
 	if len(pdu) < 2 {
 		return nil, fmt.Errorf("%w: response too short", ErrInvalidResponse)
@@ -355,6 +473,68 @@ func ParseWriteMultipleResponse(pdu []byte, expectedAddr, expectedQty uint16) er
 	return nil
 }
 
+// ParseReadFileRecordResponse parses a read file record response (FC20)
+// and returns one record per sub-request.
+func ParseReadFileRecordResponse(pdu []byte, reqs []FileSubRequest) ([]FileRecord, error) {
+	if len(pdu) < 2 {
+		return nil, fmt.Errorf("%w: response too short", ErrInvalidResponse)
+	}
+	dataLen := int(pdu[1])
+	if len(pdu) < 2+dataLen {
+		return nil, fmt.Errorf("%w: incomplete response", ErrInvalidResponse)
+	}
+
+	data := pdu[2 : 2+dataLen]
+	records := make([]FileRecord, 0, len(reqs))
+	for _, r := range reqs {
+		if len(data) < 2 {
+			return nil, fmt.Errorf("%w: missing file record", ErrInvalidResponse)
+		}
+		n := int(data[0])
+		if data[1] != FileRecordReferenceType {
+			return nil, fmt.Errorf("%w: unexpected reference type %d", ErrInvalidResponse, data[1])
+		}
+		if n != 1+2*int(r.Length) || len(data) < 1+n {
+			return nil, fmt.Errorf("%w: record length mismatch", ErrInvalidResponse)
+		}
+		records = append(records, FileRecord{
+			File:   r.File,
+			Record: r.Record,
+			Data:   BytesToUint16s(data[2 : 1+n]),
+		})
+		data = data[1+n:]
+	}
+	return records, nil
+}
+
+// ParseWriteFileRecordResponse parses a write file record response (FC21)
+// and validates that it echoes the request.
+func ParseWriteFileRecordResponse(pdu []byte, records []FileRecord) error {
+	expected, err := BuildWriteFileRecordPDU(records)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(pdu, expected) {
+		return fmt.Errorf("%w: response does not echo request", ErrInvalidResponse)
+	}
+	return nil
+}
+
+// ParseMaskWriteRegisterResponse parses a mask write register response (FC22)
+// and validates that it echoes the request.
+func ParseMaskWriteRegisterResponse(pdu []byte, expectedAddr, expectedAnd, expectedOr uint16) error {
+	if len(pdu) < 7 {
+		return fmt.Errorf("%w: response too short", ErrInvalidResponse)
+	}
+	if binary.BigEndian.Uint16(pdu[1:3]) != expectedAddr {
+		return fmt.Errorf("%w: address mismatch", ErrInvalidResponse)
+	}
+	if binary.BigEndian.Uint16(pdu[3:5]) != expectedAnd || binary.BigEndian.Uint16(pdu[5:7]) != expectedOr {
+		return fmt.Errorf("%w: mask mismatch", ErrInvalidResponse)
+	}
+	return nil
+}
+
 // ParseExceptionStatusResponse parses an exception status response (FC07).
 func ParseExceptionStatusResponse(pdu []byte) (uint8, error) {
 	if len(pdu) < 2 {
@@ -386,6 +566,28 @@ func ParseGetCommEventCounterResponse(pdu []byte) (status, eventCount uint16, er
 	return status, eventCount, nil
 }
 
+// ParseGetCommEventLogResponse parses a get comm event log response (FC12).
+func ParseGetCommEventLogResponse(pdu []byte) (*CommEventLog, error) {
+	if len(pdu) < 8 {
+		return nil, fmt.Errorf("%w: response too short", ErrInvalidResponse)
+	}
+	byteCount := int(pdu[1])
+	if byteCount < 6 || byteCount > 6+MaxCommEvents {
+		return nil, fmt.Errorf("%w: invalid byte count %d", ErrInvalidResponse, byteCount)
+	}
+	if len(pdu) < 2+byteCount {
+		return nil, fmt.Errorf("%w: incomplete response", ErrInvalidResponse)
+	}
+	events := make([]byte, byteCount-6)
+	copy(events, pdu[8:2+byteCount])
+	return &CommEventLog{
+		Status:       binary.BigEndian.Uint16(pdu[2:4]),
+		EventCount:   binary.BigEndian.Uint16(pdu[4:6]),
+		MessageCount: binary.BigEndian.Uint16(pdu[6:8]),
+		Events:       events,
+	}, nil
+}
+
 // ParseReportServerIDResponse parses a report server ID response (FC17).
 func ParseReportServerIDResponse(pdu []byte) ([]byte, error) {
 	if len(pdu) < 2 {
@@ -400,6 +602,54 @@ func ParseReportServerIDResponse(pdu []byte) ([]byte, error) {
 	return data, nil
 }
 
+// ParseReadFIFOQueueResponse parses a read FIFO queue response (FC24)
+// and returns the queued values.
+func ParseReadFIFOQueueResponse(pdu []byte) ([]uint16, error) {
+	if len(pdu) < 5 {
+		return nil, fmt.Errorf("%w: response too short", ErrInvalidResponse)
+	}
+	byteCount := int(binary.BigEndian.Uint16(pdu[1:3]))
+	fifoCount := int(binary.BigEndian.Uint16(pdu[3:5]))
+	if fifoCount > MaxFIFOCount || byteCount != 2+2*fifoCount {
+		return nil, fmt.Errorf("%w: invalid FIFO count %d", ErrInvalidResponse, fifoCount)
+	}
+	if len(pdu) < 3+byteCount {
+		return nil, fmt.Errorf("%w: incomplete response", ErrInvalidResponse)
+	}
+	return BytesToUint16s(pdu[5 : 3+byteCount]), nil
+}
+
+// ParseReadDeviceIdentificationResponse parses one page of a read device
+// identification response (FC43/14).
+func ParseReadDeviceIdentificationResponse(pdu []byte) (*ReadDeviceIdentificationResponse, error) {
+	if len(pdu) < 7 {
+		return nil, fmt.Errorf("%w: response too short", ErrInvalidResponse)
+	}
+	if pdu[1] != MEIReadDeviceIdentification {
+		return nil, fmt.Errorf("%w: unexpected MEI type 0x%02X", ErrInvalidResponse, pdu[1])
+	}
+
+	resp := &ReadDeviceIdentificationResponse{
+		ReadCode:        pdu[2],
+		ConformityLevel: pdu[3],
+		MoreFollows:     pdu[4] == 0xFF,
+		NextObjectID:    pdu[5],
+		Objects:         make([]DeviceObject, 0, pdu[6]),
+	}
+
+	n := 7
+	for i := 0; i < int(pdu[6]); i++ {
+		if len(pdu) < n+2 || len(pdu) < n+2+int(pdu[n+1]) {
+			return nil, fmt.Errorf("%w: incomplete response", ErrInvalidResponse)
+		}
+		value := make([]byte, pdu[n+1])
+		copy(value, pdu[n+2:])
+		resp.Objects = append(resp.Objects, DeviceObject{ID: pdu[n], Value: value})
+		n += 2 + len(value)
+	}
+	return resp, nil
+}
+
 // IsExceptionResponse checks if the PDU is an exception response.
 func IsExceptionResponse(pdu []byte) bool {
 	return len(pdu) > 0 && (pdu[0]&0x80) != 0
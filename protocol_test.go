@@ -16,6 +16,7 @@ package modbus
 
 import (
 	"bytes"
+	"errors"
 	"testing"
 )
 
@@ -201,6 +202,105 @@ func TestBuildWriteMultipleRegistersPDU(t *testing.T) {
 	}
 }
 
+func TestBuildMaskWriteRegisterPDU(t *testing.T) {
+	pdu := BuildMaskWriteRegisterPDU(0x0004, 0x00F2, 0x0025)
+	expected := []byte{0x16, 0x00, 0x04, 0x00, 0xF2, 0x00, 0x25}
+	if !bytes.Equal(pdu, expected) {
+		t.Errorf("Expected %x, got %x", expected, pdu)
+	}
+
+	if err := ParseMaskWriteRegisterResponse(pdu, 0x0004, 0x00F2, 0x0025); err != nil {
+		t.Errorf("ParseMaskWriteRegisterResponse failed: %v", err)
+	}
+	if err := ParseMaskWriteRegisterResponse(pdu, 0x0004, 0x00F2, 0x0026); err == nil {
+		t.Error("Expected error for mask mismatch")
+	}
+}
+
+func TestBuildReadFileRecordPDU(t *testing.T) {
+	reqs := []FileSubRequest{
+		{File: 4, Record: 1, Length: 2},
+		{File: 3, Record: 9, Length: 2},
+	}
+	pdu, err := BuildReadFileRecordPDU(reqs)
+	if err != nil {
+		t.Fatalf("BuildReadFileRecordPDU failed: %v", err)
+	}
+	expected := []byte{0x14, 0x0E,
+		0x06, 0x00, 0x04, 0x00, 0x01, 0x00, 0x02,
+		0x06, 0x00, 0x03, 0x00, 0x09, 0x00, 0x02}
+	if !bytes.Equal(pdu, expected) {
+		t.Errorf("Expected %x, got %x", expected, pdu)
+	}
+
+	resp := []byte{0x14, 0x0C,
+		0x05, 0x06, 0x0D, 0xFE, 0x00, 0x20,
+		0x05, 0x06, 0x33, 0xCD, 0x00, 0x40}
+	records, err := ParseReadFileRecordResponse(resp, reqs)
+	if err != nil {
+		t.Fatalf("ParseReadFileRecordResponse failed: %v", err)
+	}
+	if len(records) != 2 || records[1].File != 3 || records[1].Record != 9 {
+		t.Fatalf("Unexpected records: %+v", records)
+	}
+	if records[0].Data[0] != 0x0DFE || records[0].Data[1] != 0x0020 ||
+		records[1].Data[0] != 0x33CD || records[1].Data[1] != 0x0040 {
+		t.Errorf("Unexpected record data: %+v", records)
+	}
+
+	if _, err := ParseReadFileRecordResponse(resp[:8], reqs); !errors.Is(err, ErrInvalidResponse) {
+		t.Errorf("Expected ErrInvalidResponse for missing record, got %v", err)
+	}
+	if _, err := BuildReadFileRecordPDU([]FileSubRequest{{File: 1, Record: 10000, Length: 1}}); !errors.Is(err, ErrInvalidAddress) {
+		t.Errorf("Expected ErrInvalidAddress for record 10000, got %v", err)
+	}
+	if _, err := BuildReadFileRecordPDU([]FileSubRequest{{File: 1, Length: 125}}); !errors.Is(err, ErrInvalidQuantity) {
+		t.Errorf("Expected ErrInvalidQuantity for oversized response, got %v", err)
+	}
+}
+
+func TestBuildWriteFileRecordPDU(t *testing.T) {
+	records := []FileRecord{{File: 4, Record: 7, Data: []uint16{0x06AF, 0x04BE, 0x100D}}}
+	pdu, err := BuildWriteFileRecordPDU(records)
+	if err != nil {
+		t.Fatalf("BuildWriteFileRecordPDU failed: %v", err)
+	}
+	expected := []byte{0x15, 0x0D,
+		0x06, 0x00, 0x04, 0x00, 0x07, 0x00, 0x03, 0x06, 0xAF, 0x04, 0xBE, 0x10, 0x0D}
+	if !bytes.Equal(pdu, expected) {
+		t.Errorf("Expected %x, got %x", expected, pdu)
+	}
+
+	if err := ParseWriteFileRecordResponse(pdu, records); err != nil {
+		t.Errorf("ParseWriteFileRecordResponse failed: %v", err)
+	}
+	pdu[len(pdu)-1]++
+	if err := ParseWriteFileRecordResponse(pdu, records); !errors.Is(err, ErrInvalidResponse) {
+		t.Errorf("Expected ErrInvalidResponse for altered echo, got %v", err)
+	}
+}
+
+func TestBuildReadWriteMultipleRegistersPDU(t *testing.T) {
+	values := []uint16{0x00FF, 0x00FF, 0x00FF}
+	pdu, err := BuildReadWriteMultipleRegistersPDU(0x0003, 6, 0x000E, values)
+	if err != nil {
+		t.Fatalf("BuildReadWriteMultipleRegistersPDU failed: %v", err)
+	}
+
+	expected := []byte{0x17, 0x00, 0x03, 0x00, 0x06, 0x00, 0x0E, 0x00, 0x03, 0x06,
+		0x00, 0xFF, 0x00, 0xFF, 0x00, 0xFF}
+	if !bytes.Equal(pdu, expected) {
+		t.Errorf("Expected %x, got %x", expected, pdu)
+	}
+
+	if _, err := BuildReadWriteMultipleRegistersPDU(0, 126, 0, values); err == nil {
+		t.Error("Expected error for read quantity 126")
+	}
+	if _, err := BuildReadWriteMultipleRegistersPDU(0, 1, 0, make([]uint16, 122)); err == nil {
+		t.Error("Expected error for write quantity 122")
+	}
+}
+
 func TestParseCoilsResponse(t *testing.T) {
 	// Response for reading 19 coils
 	pdu := []byte{0x01, 0x03, 0xCD, 0x6B, 0x05}
@@ -237,6 +337,74 @@ func TestParseRegistersResponse(t *testing.T) {
 	}
 }
 
+func TestParseGetCommEventLogResponse(t *testing.T) {
+	pdu := []byte{0x0C, 0x08, 0x00, 0x00, 0x01, 0x08, 0x01, 0x21, 0x20, 0x00}
+	log, err := ParseGetCommEventLogResponse(pdu)
+	if err != nil {
+		t.Fatalf("ParseGetCommEventLogResponse failed: %v", err)
+	}
+	if log.Status != 0 || log.EventCount != 0x0108 || log.MessageCount != 0x0121 {
+		t.Errorf("Unexpected counters: %+v", log)
+	}
+	if !bytes.Equal(log.Events, []byte{0x20, 0x00}) {
+		t.Errorf("Expected events 20 00, got % X", log.Events)
+	}
+
+	if _, err := ParseGetCommEventLogResponse(pdu[:9]); !errors.Is(err, ErrInvalidResponse) {
+		t.Errorf("Expected ErrInvalidResponse for truncated log, got %v", err)
+	}
+}
+
+func TestReadFIFOQueuePDU(t *testing.T) {
+	if pdu := BuildReadFIFOQueuePDU(0x04DE); !bytes.Equal(pdu, []byte{0x18, 0x04, 0xDE}) {
+		t.Errorf("Unexpected request PDU %x", pdu)
+	}
+
+	resp := []byte{0x18, 0x00, 0x06, 0x00, 0x02, 0x01, 0xB8, 0x12, 0x84}
+	values, err := ParseReadFIFOQueueResponse(resp)
+	if err != nil {
+		t.Fatalf("ParseReadFIFOQueueResponse failed: %v", err)
+	}
+	if len(values) != 2 || values[0] != 0x01B8 || values[1] != 0x1284 {
+		t.Errorf("Unexpected values: %v", values)
+	}
+
+	if _, err := ParseReadFIFOQueueResponse(resp[:7]); !errors.Is(err, ErrInvalidResponse) {
+		t.Errorf("Expected ErrInvalidResponse for truncated response, got %v", err)
+	}
+	if _, err := ParseReadFIFOQueueResponse([]byte{0x18, 0x00, 0x08, 0x00, 0x02}); !errors.Is(err, ErrInvalidResponse) {
+		t.Errorf("Expected ErrInvalidResponse for count mismatch, got %v", err)
+	}
+}
+
+func TestParseReadDeviceIdentificationResponse(t *testing.T) {
+	if pdu := BuildReadDeviceIdentificationPDU(ReadDeviceIDBasic, 0); !bytes.Equal(pdu, []byte{0x2B, 0x0E, 0x01, 0x00}) {
+		t.Errorf("Unexpected request PDU %x", pdu)
+	}
+
+	pdu := []byte{0x2B, 0x0E, 0x01, 0x01, 0xFF, 0x02, 0x02,
+		0x00, 0x07, 'C', 'o', 'm', 'p', 'a', 'n', 'y',
+		0x01, 0x04, 'P', 'C', '0', '1'}
+
+	resp, err := ParseReadDeviceIdentificationResponse(pdu)
+	if err != nil {
+		t.Fatalf("ParseReadDeviceIdentificationResponse failed: %v", err)
+	}
+	if resp.ReadCode != ReadDeviceIDBasic || resp.ConformityLevel != 0x01 {
+		t.Errorf("Unexpected header: %+v", resp)
+	}
+	if !resp.MoreFollows || resp.NextObjectID != 0x02 {
+		t.Errorf("Expected more follows from object 2, got %v/%d", resp.MoreFollows, resp.NextObjectID)
+	}
+	if len(resp.Objects) != 2 || string(resp.Objects[0].Value) != "Company" || string(resp.Objects[1].Value) != "PC01" {
+		t.Errorf("Unexpected objects: %+v", resp.Objects)
+	}
+
+	if _, err := ParseReadDeviceIdentificationResponse(pdu[:len(pdu)-1]); !errors.Is(err, ErrInvalidResponse) {
+		t.Errorf("Expected ErrInvalidResponse for truncated object, got %v", err)
+	}
+}
+
 func TestIsExceptionResponse(t *testing.T) {
 	// Normal response
 	normalPDU := []byte{0x03, 0x02, 0x00, 0x01}
@@ -0,0 +1,228 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modbus
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// chunk is one request of a range operation.
+type chunk struct {
+	addr   uint16
+	qty    uint16
+	offset int // index of the first value in the range
+}
+
+// splitRange splits count items starting at addr into chunks of at most
+// max items, or of the chunk size set in opts when it is smaller.
+func splitRange(addr uint16, count, max int, opts *rangeOptions) ([]chunk, error) {
+	if count < 1 {
+		return nil, fmt.Errorf("%w: count must be at least 1", ErrInvalidQuantity)
+	}
+	if int(addr)+count > 65536 {
+		return nil, fmt.Errorf("%w: address range exceeds 65535", ErrInvalidAddress)
+	}
+	size := max
+	if opts.chunkSize > 0 && opts.chunkSize < max {
+		size = opts.chunkSize
+	}
+
+	chunks := make([]chunk, 0, (count+size-1)/size)
+	for offset := 0; offset < count; offset += size {
+		n := size
+		if count-offset < n {
+			n = count - offset
+		}
+		chunks = append(chunks, chunk{addr: addr + uint16(offset), qty: uint16(n), offset: offset})
+	}
+	return chunks, nil
+}
+
+// runChunks calls fn with the index of every chunk, with up to
+// opts.concurrency calls in flight, and reports the failed chunks as a
+// *RangeError.
+func runChunks(ctx context.Context, chunks []chunk, opts *rangeOptions, fn func(ctx context.Context, i int) error) error {
+	errs := make([]error, len(chunks))
+
+	if opts.concurrency <= 1 {
+		for i := range chunks {
+			errs[i] = fn(ctx, i)
+		}
+	} else {
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, opts.concurrency)
+		for i := range chunks {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func() {
+				defer wg.Done()
+				errs[i] = fn(ctx, i)
+				<-sem
+			}()
+		}
+		wg.Wait()
+	}
+
+	var rangeErr *RangeError
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		if rangeErr == nil {
+			rangeErr = &RangeError{Total: len(chunks)}
+		}
+		rangeErr.Chunks = append(rangeErr.Chunks, &ChunkError{
+			Address:  chunks[i].addr,
+			Quantity: chunks[i].qty,
+			Err:      err,
+		})
+	}
+	if rangeErr != nil {
+		return rangeErr
+	}
+	return nil
+}
+
+// readRange reads count items starting at addr with as many requests of at
+// most max items as needed.
+func readRange[T any](ctx context.Context, addr uint16, count, max int, opts []RangeOption,
+	read func(ctx context.Context, addr, qty uint16) ([]T, error)) ([]T, error) {
+	options := defaultRangeOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+	chunks, err := splitRange(addr, count, max, options)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]T, count)
+	err = runChunks(ctx, chunks, options, func(ctx context.Context, i int) error {
+		ch := chunks[i]
+		v, err := read(ctx, ch.addr, ch.qty)
+		if err != nil {
+			return err
+		}
+		copy(values[ch.offset:ch.offset+int(ch.qty)], v)
+		return nil
+	})
+	return values, err
+}
+
+// writeRange writes values starting at addr with as many requests of at
+// most max items as needed.
+func writeRange[T any](ctx context.Context, addr uint16, values []T, max int, opts []RangeOption,
+	write func(ctx context.Context, addr uint16, values []T) error) error {
+	options := defaultRangeOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+	chunks, err := splitRange(addr, len(values), max, options)
+	if err != nil {
+		return err
+	}
+
+	return runChunks(ctx, chunks, options, func(ctx context.Context, i int) error {
+		ch := chunks[i]
+		return write(ctx, ch.addr, values[ch.offset:ch.offset+int(ch.qty)])
+	})
+}
+
+// ReadCoilRange reads count coils starting at addr, splitting the range into
+// as many requests as needed (FC01). If some requests fail, the values read
+// by the others are returned with a *RangeError; failed values are false.
+func (c *Client) ReadCoilRange(ctx context.Context, addr uint16, count int, opts ...RangeOption) ([]bool, error) {
+	return c.ReadCoilRangeWithUnit(ctx, c.UnitID(), addr, count, opts...)
+}
+
+// ReadDiscreteInputRange reads count discrete inputs starting at addr,
+// splitting the range into as many requests as needed (FC02). Failures are
+// reported as for ReadCoilRange.
+func (c *Client) ReadDiscreteInputRange(ctx context.Context, addr uint16, count int, opts ...RangeOption) ([]bool, error) {
+	return c.ReadDiscreteInputRangeWithUnit(ctx, c.UnitID(), addr, count, opts...)
+}
+
+// ReadHoldingRange reads count holding registers starting at addr, splitting
+// the range into as many requests as needed (FC03). If some requests fail,
+// the values read by the others are returned with a *RangeError; failed
+// values are zero.
+func (c *Client) ReadHoldingRange(ctx context.Context, addr uint16, count int, opts ...RangeOption) ([]uint16, error) {
+	return c.ReadHoldingRangeWithUnit(ctx, c.UnitID(), addr, count, opts...)
+}
+
+// ReadInputRange reads count input registers starting at addr, splitting the
+// range into as many requests as needed (FC04). Failures are reported as for
+// ReadHoldingRange.
+func (c *Client) ReadInputRange(ctx context.Context, addr uint16, count int, opts ...RangeOption) ([]uint16, error) {
+	return c.ReadInputRangeWithUnit(ctx, c.UnitID(), addr, count, opts...)
+}
+
+// WriteCoilRange writes values to the coils starting at addr, splitting the
+// range into as many requests as needed (FC15). Failed requests are reported
+// as a *RangeError; the other requests are still written.
+func (c *Client) WriteCoilRange(ctx context.Context, addr uint16, values []bool, opts ...RangeOption) error {
+	return c.WriteCoilRangeWithUnit(ctx, c.UnitID(), addr, values, opts...)
+}
+
+// WriteHoldingRange writes values to the holding registers starting at addr,
+// splitting the range into as many requests as needed (FC16). Failures are
+// reported as for WriteCoilRange.
+func (c *Client) WriteHoldingRange(ctx context.Context, addr uint16, values []uint16, opts ...RangeOption) error {
+	return c.WriteHoldingRangeWithUnit(ctx, c.UnitID(), addr, values, opts...)
+}
+
+// ReadCoilRangeWithUnit reads a range of coils using a specific unit ID.
+func (c *Client) ReadCoilRangeWithUnit(ctx context.Context, unitID UnitID, addr uint16, count int, opts ...RangeOption) ([]bool, error) {
+	return readRange(ctx, addr, count, MaxQuantityCoils, opts, func(ctx context.Context, addr, qty uint16) ([]bool, error) {
+		return c.ReadCoilsWithUnit(ctx, unitID, addr, qty)
+	})
+}
+
+// ReadDiscreteInputRangeWithUnit reads a range of discrete inputs using a specific unit ID.
+func (c *Client) ReadDiscreteInputRangeWithUnit(ctx context.Context, unitID UnitID, addr uint16, count int, opts ...RangeOption) ([]bool, error) {
+	return readRange(ctx, addr, count, MaxQuantityDiscreteInputs, opts, func(ctx context.Context, addr, qty uint16) ([]bool, error) {
+		return c.ReadDiscreteInputsWithUnit(ctx, unitID, addr, qty)
+	})
+}
+
+// ReadHoldingRangeWithUnit reads a range of holding registers using a specific unit ID.
+func (c *Client) ReadHoldingRangeWithUnit(ctx context.Context, unitID UnitID, addr uint16, count int, opts ...RangeOption) ([]uint16, error) {
+	return readRange(ctx, addr, count, MaxQuantityRegisters, opts, func(ctx context.Context, addr, qty uint16) ([]uint16, error) {
+		return c.ReadHoldingRegistersWithUnit(ctx, unitID, addr, qty)
+	})
+}
+
+// ReadInputRangeWithUnit reads a range of input registers using a specific unit ID.
+func (c *Client) ReadInputRangeWithUnit(ctx context.Context, unitID UnitID, addr uint16, count int, opts ...RangeOption) ([]uint16, error) {
+	return readRange(ctx, addr, count, MaxQuantityRegisters, opts, func(ctx context.Context, addr, qty uint16) ([]uint16, error) {
+		return c.ReadInputRegistersWithUnit(ctx, unitID, addr, qty)
+	})
+}
+
+// WriteCoilRangeWithUnit writes a range of coils using a specific unit ID.
+func (c *Client) WriteCoilRangeWithUnit(ctx context.Context, unitID UnitID, addr uint16, values []bool, opts ...RangeOption) error {
+	return writeRange(ctx, addr, values, MaxQuantityWriteCoils, opts, func(ctx context.Context, addr uint16, values []bool) error {
+		return c.WriteMultipleCoilsWithUnit(ctx, unitID, addr, values)
+	})
+}
+
+// WriteHoldingRangeWithUnit writes a range of holding registers using a specific unit ID.
+func (c *Client) WriteHoldingRangeWithUnit(ctx context.Context, unitID UnitID, addr uint16, values []uint16, opts ...RangeOption) error {
+	return writeRange(ctx, addr, values, MaxQuantityWriteRegisters, opts, func(ctx context.Context, addr uint16, values []uint16) error {
+		return c.WriteMultipleRegistersWithUnit(ctx, unitID, addr, values)
+	})
+}
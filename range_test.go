@@ -0,0 +1,147 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modbus
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+func TestSplitRange(t *testing.T) {
+	tests := []struct {
+		name      string
+		addr      uint16
+		count     int
+		chunkSize int
+		expected  []chunk
+	}{
+		{"single", 10, 5, 0, []chunk{{10, 5, 0}}},
+		{"exact", 0, 250, 0, []chunk{{0, 125, 0}, {125, 125, 125}}},
+		{"remainder", 100, 130, 0, []chunk{{100, 125, 0}, {225, 5, 125}}},
+		{"device limit", 0, 50, 20, []chunk{{0, 20, 0}, {20, 20, 20}, {40, 10, 40}}},
+		{"limit above max", 0, 130, 200, []chunk{{0, 125, 0}, {125, 5, 125}}},
+		{"end of address space", 65530, 6, 0, []chunk{{65530, 6, 0}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks, err := splitRange(tt.addr, tt.count, MaxQuantityRegisters, &rangeOptions{chunkSize: tt.chunkSize})
+			if err != nil {
+				t.Fatalf("splitRange failed: %v", err)
+			}
+			if len(chunks) != len(tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, chunks)
+			}
+			for i := range chunks {
+				if chunks[i] != tt.expected[i] {
+					t.Errorf("Chunk %d: expected %v, got %v", i, tt.expected[i], chunks[i])
+				}
+			}
+		})
+	}
+
+	if _, err := splitRange(0, 0, MaxQuantityRegisters, &rangeOptions{}); !errors.Is(err, ErrInvalidQuantity) {
+		t.Errorf("Expected ErrInvalidQuantity, got %v", err)
+	}
+	if _, err := splitRange(65530, 7, MaxQuantityRegisters, &rangeOptions{}); !errors.Is(err, ErrInvalidAddress) {
+		t.Errorf("Expected ErrInvalidAddress, got %v", err)
+	}
+}
+
+func TestClientRange(t *testing.T) {
+	handler := NewMemoryHandler(65536, 65536)
+	for i := uint16(0); i < 300; i++ {
+		handler.SetHoldingRegister(1, i, i*2)
+	}
+	server := NewServer(handler)
+	// Only registers 0-299 can be read
+	server.HandleFunc(FuncReadHoldingRegisters, func(unitID UnitID, pdu []byte) ([]byte, error) {
+		if int(binary.BigEndian.Uint16(pdu[1:3]))+int(binary.BigEndian.Uint16(pdu[3:5])) > 300 {
+			return nil, NewModbusError(FuncReadHoldingRegisters, ExceptionIllegalDataAddress)
+		}
+		return server.handleReadHoldingRegisters(unitID, pdu)
+	})
+	client, err := NewClientWithTransport(&loopbackTransport{server: server}, WithUnitID(1))
+	if err != nil {
+		t.Fatalf("NewClientWithTransport failed: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	for _, concurrency := range []int{1, 4} {
+		values, err := client.ReadHoldingRange(ctx, 10, 280, WithConcurrency(concurrency))
+		if err != nil {
+			t.Fatalf("ReadHoldingRange failed: %v", err)
+		}
+		for i, v := range values {
+			if v != uint16(10+i)*2 {
+				t.Fatalf("Concurrency %d: register %d: expected %d, got %d", concurrency, 10+i, (10+i)*2, v)
+			}
+		}
+	}
+
+	// The chunk covering registers 300 and above fails
+	values, err := client.ReadHoldingRange(ctx, 0, 400, WithChunkSize(100))
+	var rangeErr *RangeError
+	if !errors.As(err, &rangeErr) {
+		t.Fatalf("Expected *RangeError, got %v", err)
+	}
+	if rangeErr.Total != 4 || len(rangeErr.Chunks) != 1 || rangeErr.Chunks[0].Address != 300 || rangeErr.Chunks[0].Quantity != 100 {
+		t.Errorf("Unexpected range error: %v", rangeErr)
+	}
+	if !IsIllegalDataAddress(err) {
+		t.Errorf("Expected the chunk error to unwrap to an exception, got %v", err)
+	}
+	if len(values) != 400 || values[299] != 598 || values[300] != 0 {
+		t.Errorf("Expected values of the successful chunks, got %d values", len(values))
+	}
+
+	write := make([]uint16, 250)
+	for i := range write {
+		write[i] = uint16(1000 + i)
+	}
+	if err := client.WriteHoldingRange(ctx, 20, write, WithConcurrency(2)); err != nil {
+		t.Fatalf("WriteHoldingRange failed: %v", err)
+	}
+	regs, _ := handler.ReadHoldingRegisters(1, 20, uint16(len(write)))
+	for i, want := range write {
+		if regs[i] != want {
+			t.Fatalf("Register %d: expected %d, got %d", 20+i, want, regs[i])
+		}
+	}
+
+	coils := make([]bool, 4000)
+	for i := range coils {
+		coils[i] = i%3 == 0
+	}
+	if err := client.WriteCoilRange(ctx, 0, coils); err != nil {
+		t.Fatalf("WriteCoilRange failed: %v", err)
+	}
+	got, err := client.ReadCoilRange(ctx, 0, len(coils))
+	if err != nil {
+		t.Fatalf("ReadCoilRange failed: %v", err)
+	}
+	for i := range coils {
+		if got[i] != coils[i] {
+			t.Fatalf("Coil %d: expected %v, got %v", i, coils[i], got[i])
+		}
+	}
+}
@@ -0,0 +1,261 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modbus
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/edgeo-scada/modbus/internal/transport"
+)
+
+// RTU framing constants.
+const (
+	// RTUMinFrameSize is the size of the smallest RTU frame (unit ID, function code, CRC).
+	RTUMinFrameSize = 4
+
+	// RTUMaxFrameSize is the maximum size of an RTU frame.
+	RTUMaxFrameSize = 256
+
+	// rtuSilenceTimeout delimits responses whose length cannot be predicted.
+	// OS scheduling and USB adapter latency make true 3.5 character timing
+	// unreliable on the receive side, so a more generous gap is used.
+	rtuSilenceTimeout = 50 * time.Millisecond
+)
+
+// CRC16 computes the Modbus RTU CRC-16 (polynomial 0xA001, initial value 0xFFFF).
+func CRC16(data []byte) uint16 {
+	crc := uint16(0xFFFF)
+	for _, b := range data {
+		crc ^= uint16(b)
+		for i := 0; i < 8; i++ {
+			if crc&0x0001 != 0 {
+				crc = (crc >> 1) ^ 0xA001
+			} else {
+				crc >>= 1
+			}
+		}
+	}
+	return crc
+}
+
+// EncodeRTUFrame builds an RTU frame (unit ID + PDU + CRC, low byte first).
+func EncodeRTUFrame(unitID UnitID, pdu []byte) []byte {
+	frame := make([]byte, 1+len(pdu)+2)
+	frame[0] = byte(unitID)
+	copy(frame[1:], pdu)
+	crc := CRC16(frame[:1+len(pdu)])
+	frame[1+len(pdu)] = byte(crc)
+	frame[2+len(pdu)] = byte(crc >> 8)
+	return frame
+}
+
+// DecodeRTUFrame validates the CRC of an RTU frame and returns its unit ID and PDU.
+func DecodeRTUFrame(frame []byte) (UnitID, []byte, error) {
+	if len(frame) < RTUMinFrameSize {
+		return 0, nil, fmt.Errorf("%w: RTU frame too short", ErrInvalidFrame)
+	}
+	if len(frame) > RTUMaxFrameSize {
+		return 0, nil, fmt.Errorf("%w: RTU frame too long", ErrInvalidFrame)
+	}
+	n := len(frame) - 2
+	expected := CRC16(frame[:n])
+	actual := uint16(frame[n]) | uint16(frame[n+1])<<8
+	if actual != expected {
+		return 0, nil, fmt.Errorf("%w: expected %04X, got %04X", ErrInvalidCRC, expected, actual)
+	}
+	pdu := make([]byte, n-1)
+	copy(pdu, frame[1:n])
+	return UnitID(frame[0]), pdu, nil
+}
+
+// rtuFrameDelay returns the 3.5 character inter-frame delay for a baud rate.
+// Above 19200 baud the specification recommends a fixed 1.75ms.
+func rtuFrameDelay(baudRate int) time.Duration {
+	if baudRate <= 0 || baudRate > 19200 {
+		return 1750 * time.Microsecond
+	}
+	// A character is 11 bits on the wire (start, 8 data, parity, stop)
+	return time.Duration(int64(time.Second) * 11 * 35 / 10 / int64(baudRate))
+}
+
+// rtuResponseSize predicts the total size of the RTU response to req from
+// the bytes received so far. It returns -1 if more bytes are needed to
+// decide, and 0 if the size cannot be predicted and the frame must be
+// delimited by line silence.
+func rtuResponseSize(req []byte, head []byte) int {
+	if len(head) < 2 {
+		return -1
+	}
+	fc := head[1]
+	if fc&0x80 != 0 {
+		// Exception: unit ID, function code, exception code, CRC
+		return 5
+	}
+
+	switch FunctionCode(fc) {
+	case FuncReadCoils, FuncReadDiscreteInputs, FuncReadHoldingRegisters,
+		FuncReadInputRegisters, FuncGetCommEventLog, FuncReportServerID,
+		FuncReadFileRecord, FuncReadWriteMultipleRegisters:
+		if len(head) < 3 {
+			return -1
+		}
+		return 3 + int(head[2]) + 2
+	case FuncWriteSingleCoil, FuncWriteSingleRegister, FuncWriteMultipleCoils,
+		FuncWriteMultipleRegisters, FuncGetCommEventCounter:
+		return 8
+	case FuncMaskWriteRegister:
+		return 10
+	case FuncReadExceptionStatus:
+		return 5
+	case FuncDiagnostics, FuncWriteFileRecord:
+		// Diagnostics and write file record responses echo the request
+		return 1 + len(req) + 2
+	case FuncReadFIFOQueue:
+		// Two-byte byte count
+		if len(head) < 4 {
+			return -1
+		}
+		return 4 + int(binary.BigEndian.Uint16(head[2:4])) + 2
+	case FuncEncapsulatedInterfaceTransport:
+		return rtuDeviceIDResponseSize(head)
+	default:
+		return 0
+	}
+}
+
+// rtuDeviceIDResponseSize walks the object list of a read device
+// identification response (FC43/14) to find its size.
+func rtuDeviceIDResponseSize(head []byte) int {
+	if len(head) < 3 {
+		return -1
+	}
+	if head[2] != MEIReadDeviceIdentification {
+		return 0
+	}
+	// Unit ID, function code, MEI type, read code, conformity level,
+	// more follows, next object ID, number of objects
+	n := 8
+	if len(head) < n {
+		return -1
+	}
+	for i := 0; i < int(head[7]); i++ {
+		if len(head) < n+2 {
+			return -1
+		}
+		n += 2 + int(head[n+1])
+	}
+	return n + 2
+}
+
+// readRTUResponse reads one RTU frame, using the function code to predict
+// its length and falling back to silence detection for unknown functions.
+func readRTUResponse(conn transport.Conn, deadline time.Time, req []byte) ([]byte, error) {
+	buf := make([]byte, RTUMaxFrameSize)
+	n := 0
+
+	if err := conn.SetReadDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	for {
+		size := rtuResponseSize(req, buf[:n])
+		if size > RTUMaxFrameSize {
+			return nil, fmt.Errorf("%w: RTU frame too long", ErrInvalidFrame)
+		}
+		if size == 0 {
+			return readUntilSilence(conn, deadline, buf, n)
+		}
+		if size > 0 && n >= size {
+			return buf[:size], nil
+		}
+
+		want := n + 1
+		if size > 0 {
+			want = size
+		}
+		m, err := conn.Read(buf[n:want])
+		n += m
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// readUntilSilence keeps reading into buf until the line has been idle for
+// rtuSilenceTimeout, then returns the bytes received.
+func readUntilSilence(conn transport.Conn, deadline time.Time, buf []byte, n int) ([]byte, error) {
+	for n < len(buf) {
+		gap := time.Now().Add(rtuSilenceTimeout)
+		if gap.After(deadline) {
+			gap = deadline
+		}
+		if err := conn.SetReadDeadline(gap); err != nil {
+			return nil, err
+		}
+		m, err := conn.Read(buf[n:])
+		n += m
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) && n >= RTUMinFrameSize && time.Now().Before(deadline) {
+				break
+			}
+			return nil, err
+		}
+	}
+	return buf[:n], nil
+}
+
+// rtuTransport frames PDUs for Modbus RTU over a byte stream.
+type rtuTransport struct {
+	stream streamTransport
+}
+
+// Connect opens the underlying stream.
+func (t *rtuTransport) Connect(ctx context.Context) error {
+	return t.stream.Connect(ctx)
+}
+
+// Close closes the underlying stream.
+func (t *rtuTransport) Close() error {
+	return t.stream.Close()
+}
+
+// Send sends a PDU in an RTU frame and returns the response PDU.
+func (t *rtuTransport) Send(ctx context.Context, unitID UnitID, pdu []byte) ([]byte, error) {
+	respData, err := t.stream.Exchange(ctx, EncodeRTUFrame(unitID, pdu),
+		func(conn transport.Conn, deadline time.Time) ([]byte, error) {
+			return readRTUResponse(conn, deadline, pdu)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	respUnit, respPDU, err := DecodeRTUFrame(respData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate unit ID
+	if respUnit != unitID {
+		return nil, fmt.Errorf("%w: unit ID mismatch (expected %d, got %d)",
+			ErrInvalidResponse, unitID, respUnit)
+	}
+
+	return respPDU, nil
+}
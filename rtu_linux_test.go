@@ -0,0 +1,165 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+
+package modbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// openPTY opens a pseudo-terminal pair and returns the master side and
+// the path of the slave device.
+func openPTY(t *testing.T) (*os.File, string) {
+	t.Helper()
+
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		t.Skipf("pseudo-terminals not available: %v", err)
+	}
+
+	rc, err := master.SyscallConn()
+	if err != nil {
+		master.Close()
+		t.Fatalf("SyscallConn failed: %v", err)
+	}
+
+	var n int
+	var ctlErr error
+	rc.Control(func(fd uintptr) {
+		if ctlErr = unix.IoctlSetPointerInt(int(fd), unix.TIOCSPTLCK, 0); ctlErr != nil {
+			return
+		}
+		n, ctlErr = unix.IoctlGetInt(int(fd), unix.TIOCGPTN)
+	})
+	if ctlErr != nil {
+		master.Close()
+		t.Fatalf("pty setup failed: %v", ctlErr)
+	}
+
+	return master, fmt.Sprintf("/dev/pts/%d", n)
+}
+
+func TestRTUClientIntegration(t *testing.T) {
+	master, slave := openPTY(t)
+	defer master.Close()
+
+	handler := NewMemoryHandler(65536, 65536)
+	handler.SetHoldingRegister(1, 0, 1234)
+	handler.SetHoldingRegister(1, 1, 5678)
+	handler.SetCoil(1, 3, true)
+	handler.SetServerID([]byte("RTU Test"))
+
+	go serveRTU(master, NewServer(handler), nil)
+
+	client, err := NewRTUClient(slave, WithUnitID(1), WithBaudRate(115200), WithTimeout(2*time.Second))
+	if err != nil {
+		t.Fatalf("NewRTUClient failed: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	t.Run("ReadHoldingRegisters", func(t *testing.T) {
+		regs, err := client.ReadHoldingRegisters(ctx, 0, 2)
+		if err != nil {
+			t.Fatalf("ReadHoldingRegisters failed: %v", err)
+		}
+		if regs[0] != 1234 || regs[1] != 5678 {
+			t.Errorf("Expected [1234 5678], got %v", regs)
+		}
+	})
+
+	t.Run("WriteMultipleCoils", func(t *testing.T) {
+		values := []bool{true, false, true, true}
+		if err := client.WriteMultipleCoils(ctx, 10, values); err != nil {
+			t.Fatalf("WriteMultipleCoils failed: %v", err)
+		}
+		coils, err := client.ReadCoils(ctx, 10, 4)
+		if err != nil {
+			t.Fatalf("ReadCoils failed: %v", err)
+		}
+		for i, v := range values {
+			if coils[i] != v {
+				t.Errorf("Coil[%d]: expected %v, got %v", 10+i, v, coils[i])
+			}
+		}
+	})
+
+	t.Run("Diagnostics", func(t *testing.T) {
+		data := []byte{0xAB, 0xCD}
+		resp, err := client.Diagnostics(ctx, DiagReturnQueryData, data)
+		if err != nil {
+			t.Fatalf("Diagnostics failed: %v", err)
+		}
+		if string(resp) != string(data) {
+			t.Errorf("Expected echo % X, got % X", data, resp)
+		}
+	})
+
+	t.Run("ReportServerID", func(t *testing.T) {
+		id, err := client.ReportServerID(ctx)
+		if err != nil {
+			t.Fatalf("ReportServerID failed: %v", err)
+		}
+		if string(id) != "RTU Test" {
+			t.Errorf("Expected 'RTU Test', got %q", id)
+		}
+	})
+
+	t.Run("Exception", func(t *testing.T) {
+		_, err := client.Diagnostics(ctx, 0x99, nil)
+		if !IsIllegalFunction(err) {
+			t.Errorf("Expected illegal function, got %v", err)
+		}
+	})
+}
+
+func TestRTUClientInvalidCRC(t *testing.T) {
+	master, slave := openPTY(t)
+	defer master.Close()
+
+	handler := NewMemoryHandler(65536, 65536)
+	go serveRTU(master, NewServer(handler), func(frame []byte) []byte {
+		frame[len(frame)-1] ^= 0xFF
+		return frame
+	})
+
+	client, err := NewRTUClient(slave, WithBaudRate(115200), WithTimeout(2*time.Second))
+	if err != nil {
+		t.Fatalf("NewRTUClient failed: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	_, err = client.ReadHoldingRegisters(ctx, 0, 1)
+	if !errors.Is(err, ErrInvalidCRC) {
+		t.Errorf("Expected ErrInvalidCRC, got %v", err)
+	}
+}
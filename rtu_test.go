@@ -0,0 +1,283 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modbus
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestCRC16(t *testing.T) {
+	tests := []struct {
+		data     []byte
+		expected uint16
+	}{
+		{[]byte{0x01, 0x03, 0x00, 0x00, 0x00, 0x0A}, 0xCDC5},
+		{[]byte{0x11, 0x03, 0x00, 0x6B, 0x00, 0x03}, 0x8776},
+		{[]byte{0x11, 0x05, 0x00, 0xAC, 0xFF, 0x00}, 0x8B4E},
+	}
+
+	for _, tt := range tests {
+		if crc := CRC16(tt.data); crc != tt.expected {
+			t.Errorf("CRC16(% X): expected %04X, got %04X", tt.data, tt.expected, crc)
+		}
+	}
+}
+
+func TestEncodeRTUFrame(t *testing.T) {
+	pdu := []byte{0x03, 0x00, 0x00, 0x00, 0x0A}
+	expected := []byte{0x01, 0x03, 0x00, 0x00, 0x00, 0x0A, 0xC5, 0xCD}
+
+	result := EncodeRTUFrame(1, pdu)
+	if !bytes.Equal(result, expected) {
+		t.Errorf("Expected % X, got % X", expected, result)
+	}
+}
+
+func TestDecodeRTUFrame(t *testing.T) {
+	frame := []byte{0x01, 0x03, 0x00, 0x00, 0x00, 0x0A, 0xC5, 0xCD}
+
+	unitID, pdu, err := DecodeRTUFrame(frame)
+	if err != nil {
+		t.Fatalf("DecodeRTUFrame failed: %v", err)
+	}
+	if unitID != 1 {
+		t.Errorf("UnitID: expected 1, got %d", unitID)
+	}
+	if !bytes.Equal(pdu, frame[1:6]) {
+		t.Errorf("PDU: expected % X, got % X", frame[1:6], pdu)
+	}
+}
+
+func TestDecodeRTUFrame_BadCRC(t *testing.T) {
+	frame := []byte{0x01, 0x03, 0x00, 0x00, 0x00, 0x0A, 0xC5, 0xCE}
+
+	_, _, err := DecodeRTUFrame(frame)
+	if !errors.Is(err, ErrInvalidCRC) {
+		t.Errorf("Expected ErrInvalidCRC, got %v", err)
+	}
+}
+
+func TestDecodeRTUFrame_TooShort(t *testing.T) {
+	_, _, err := DecodeRTUFrame([]byte{0x01, 0x03, 0x00})
+	if !errors.Is(err, ErrInvalidFrame) {
+		t.Errorf("Expected ErrInvalidFrame, got %v", err)
+	}
+}
+
+func TestRTUResponseSize(t *testing.T) {
+	readReq := []byte{0x03, 0x00, 0x00, 0x00, 0x02}
+	diagReq := []byte{0x08, 0x00, 0x00, 0x12, 0x34}
+
+	tests := []struct {
+		name     string
+		req      []byte
+		head     []byte
+		expected int
+	}{
+		{"need function code", readReq, []byte{0x01}, -1},
+		{"need byte count", readReq, []byte{0x01, 0x03}, -1},
+		{"read registers", readReq, []byte{0x01, 0x03, 0x04}, 9},
+		{"exception", readReq, []byte{0x01, 0x83}, 5},
+		{"write single", []byte{0x06}, []byte{0x01, 0x06}, 8},
+		{"write multiple", []byte{0x10}, []byte{0x01, 0x10}, 8},
+		{"exception status", []byte{0x07}, []byte{0x01, 0x07}, 5},
+		{"diagnostics echo", diagReq, []byte{0x01, 0x08}, 8},
+		{"mask write", []byte{0x16}, []byte{0x01, 0x16}, 10},
+		{"read file record", []byte{0x14}, []byte{0x01, 0x14, 0x0C}, 17},
+		{"write file record", make([]byte, 15), []byte{0x01, 0x15}, 18},
+		{"read/write registers", []byte{0x17}, []byte{0x01, 0x17, 0x02}, 7},
+		{"comm event log", []byte{0x0C}, []byte{0x01, 0x0C, 0x08}, 13},
+		{"fifo need byte count", []byte{0x18}, []byte{0x01, 0x18, 0x00}, -1},
+		{"fifo", []byte{0x18}, []byte{0x01, 0x18, 0x00, 0x06}, 12},
+		{"device id need header", []byte{0x2B}, []byte{0x01, 0x2B, 0x0E, 0x01, 0x81}, -1},
+		{"device id need object", []byte{0x2B}, []byte{0x01, 0x2B, 0x0E, 0x01, 0x81, 0x00, 0x00, 0x02, 0x00, 0x03, 'A', 'B', 'C'}, -1},
+		{"device id", []byte{0x2B}, []byte{0x01, 0x2B, 0x0E, 0x01, 0x81, 0x00, 0x00, 0x02, 0x00, 0x03, 'A', 'B', 'C', 0x01, 0x01}, 18},
+		{"other MEI type", []byte{0x2B}, []byte{0x01, 0x2B, 0x0D}, 0},
+		{"unknown function", []byte{0x41}, []byte{0x01, 0x41}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if size := rtuResponseSize(tt.req, tt.head); size != tt.expected {
+				t.Errorf("Expected %d, got %d", tt.expected, size)
+			}
+		})
+	}
+}
+
+func TestRTUFrameDelay(t *testing.T) {
+	// 3.5 characters of 11 bits at 9600 baud
+	if d := rtuFrameDelay(9600); d != 4010416*time.Nanosecond {
+		t.Errorf("9600 baud: expected ~4.01ms, got %v", d)
+	}
+	if d := rtuFrameDelay(115200); d != 1750*time.Microsecond {
+		t.Errorf("115200 baud: expected 1.75ms, got %v", d)
+	}
+}
+
+func TestNewRTUClient(t *testing.T) {
+	if _, err := NewRTUClient(""); err == nil {
+		t.Error("Expected error for empty device")
+	}
+
+	client, err := NewRTUClient("/dev/ttyUSB0", WithBaudRate(9600), WithParity(ParityNone), WithStopBits(2))
+	if err != nil {
+		t.Fatalf("NewRTUClient failed: %v", err)
+	}
+	defer client.Close()
+
+	if client.Address() != "/dev/ttyUSB0" {
+		t.Errorf("Address: expected /dev/ttyUSB0, got %s", client.Address())
+	}
+	if client.opts.baudRate != 9600 {
+		t.Errorf("BaudRate: expected 9600, got %d", client.opts.baudRate)
+	}
+	if client.opts.parity != ParityNone {
+		t.Errorf("Parity: expected N, got %c", client.opts.parity)
+	}
+}
+
+// serveRTU answers RTU requests read from rw (a pty or a TCP connection)
+// using the server's request processing. The frame transform, if set, is
+// applied to each encoded response before it is written.
+func serveRTU(rw io.ReadWriter, server *Server, transform func([]byte) []byte) {
+	buf := make([]byte, 0, RTUMaxFrameSize)
+	chunk := make([]byte, RTUMaxFrameSize)
+
+	for {
+		n, err := rw.Read(chunk)
+		if err != nil {
+			return
+		}
+		buf = append(buf, chunk[:n]...)
+
+		unitID, pdu, err := DecodeRTUFrame(buf)
+		if err != nil {
+			if len(buf) >= RTUMaxFrameSize {
+				buf = buf[:0]
+			}
+			continue
+		}
+		buf = buf[:0]
+
+		resp := server.processRequest(context.Background(), &Frame{
+			Header: MBAPHeader{UnitID: unitID},
+			PDU:    pdu,
+		}, nil)
+
+		frame := EncodeRTUFrame(resp.Header.UnitID, resp.PDU)
+		if transform != nil {
+			frame = transform(frame)
+		}
+		rw.Write(frame)
+	}
+}
+
+// listenStream starts a TCP listener whose connections are served by serve.
+func listenStream(t *testing.T, serve func(conn net.Conn)) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				serve(conn)
+			}()
+		}
+	}()
+
+	return ln.Addr().String()
+}
+
+func TestRTUOverTCPClient(t *testing.T) {
+	handler := NewMemoryHandler(65536, 65536)
+	handler.SetHoldingRegister(1, 0, 1234)
+	handler.SetHoldingRegister(1, 1, 5678)
+	handler.SetServerID([]byte("Gateway"))
+	server := NewServer(handler)
+	server.HandleFunc(0x41, func(unitID UnitID, pdu []byte) ([]byte, error) {
+		return append([]byte{0x41}, pdu[1:]...), nil
+	})
+
+	addr := listenStream(t, func(conn net.Conn) {
+		serveRTU(conn, server, nil)
+	})
+
+	client, err := NewClient(addr, WithUnitID(1), WithFraming(FramingRTU), WithTimeout(2*time.Second))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	regs, err := client.ReadHoldingRegisters(ctx, 0, 2)
+	if err != nil {
+		t.Fatalf("ReadHoldingRegisters failed: %v", err)
+	}
+	if regs[0] != 1234 || regs[1] != 5678 {
+		t.Errorf("Expected [1234 5678], got %v", regs)
+	}
+
+	if err := client.WriteSingleRegister(ctx, 10, 42); err != nil {
+		t.Fatalf("WriteSingleRegister failed: %v", err)
+	}
+	if v, _ := handler.ReadHoldingRegisters(1, 10, 1); v[0] != 42 {
+		t.Errorf("Expected register 10 = 42, got %d", v[0])
+	}
+
+	// Response length given by its byte count
+	id, err := client.ReportServerID(ctx)
+	if err != nil {
+		t.Fatalf("ReportServerID failed: %v", err)
+	}
+	if string(id) != "Gateway" {
+		t.Errorf("Expected 'Gateway', got %q", id)
+	}
+
+	// Silence-delimited response of a user-defined function
+	resp, err := client.SendPDU(ctx, 1, []byte{0x41, 0x01, 0x02})
+	if err != nil {
+		t.Fatalf("SendPDU failed: %v", err)
+	}
+	if !bytes.Equal(resp, []byte{0x41, 0x01, 0x02}) {
+		t.Errorf("Expected echo, got % X", resp)
+	}
+}
+
+func TestNewClient_UnknownFraming(t *testing.T) {
+	if _, err := NewClient("localhost:502", WithFraming(Framing(99))); err == nil {
+		t.Error("Expected error for unknown framing")
+	}
+}
@@ -16,7 +16,9 @@ package modbus
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -27,17 +29,31 @@ import (
 	"time"
 )
 
-// Server is a Modbus TCP server.
+// Server is a Modbus TCP server. It can also answer Modbus TCP frames
+// carried in UDP datagrams, see ServeUDP.
 type Server struct {
 	handler Handler
 	opts    *serverOptions
 
-	mu       sync.Mutex
-	listener net.Listener
-	conns    map[net.Conn]struct{}
-	closed   int32
-	wg       sync.WaitGroup
-	metrics  *ServerMetrics
+	mu          sync.Mutex
+	listener    net.Listener
+	conns       map[net.Conn]struct{}
+	packetConns map[net.PacketConn]struct{}
+	closed      int32
+	wg          sync.WaitGroup
+	metrics     *ServerMetrics
+	comm        commTracker
+
+	funcsMu sync.RWMutex
+	funcs   map[FunctionCode]FunctionHandler
+
+	chainMu    sync.RWMutex
+	middleware []Middleware
+	chain      RequestHandler
+
+	// ctx is cancelled by Close.
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 // ServerMetrics holds server-side metrics.
@@ -56,14 +72,77 @@ func NewServer(handler Handler, opts ...ServerOption) *Server {
 		opt(options)
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
 	return &Server{
-		handler: handler,
-		opts:    options,
-		conns:   make(map[net.Conn]struct{}),
-		metrics: &ServerMetrics{},
+		handler:     handler,
+		opts:        options,
+		conns:       make(map[net.Conn]struct{}),
+		packetConns: make(map[net.PacketConn]struct{}),
+		metrics:     &ServerMetrics{},
+		ctx:         ctx,
+		cancel:      cancel,
 	}
 }
 
+// HandleFunc registers fn to serve requests with function code fc, such as
+// the user-defined codes 65-72 and 100-110. A function registered for a
+// standard code replaces the built-in implementation. A nil fn removes the
+// registration.
+func (s *Server) HandleFunc(fc FunctionCode, fn FunctionHandler) {
+	s.funcsMu.Lock()
+	defer s.funcsMu.Unlock()
+
+	if fn == nil {
+		delete(s.funcs, fc)
+		return
+	}
+	if s.funcs == nil {
+		s.funcs = make(map[FunctionCode]FunctionHandler)
+	}
+	s.funcs[fc] = fn
+}
+
+// functionHandler returns the function registered for fc, if any.
+func (s *Server) functionHandler(fc FunctionCode) FunctionHandler {
+	s.funcsMu.RLock()
+	defer s.funcsMu.RUnlock()
+	return s.funcs[fc]
+}
+
+// Use appends middleware to the handling of requests. The first middleware
+// added is the outermost: it sees requests first and responses last.
+// Middleware serves every request with a function code, built-in or
+// registered with HandleFunc, except those ignored in listen only mode and
+// those rejected by a RoleAuthorizer. The context of a request is cancelled
+// when its connection or the server is closed.
+func (s *Server) Use(mw ...Middleware) {
+	s.chainMu.Lock()
+	defer s.chainMu.Unlock()
+
+	s.middleware = append(s.middleware, mw...)
+	h := RequestHandler(s.serveRequest)
+	for i := len(s.middleware) - 1; i >= 0; i-- {
+		h = s.middleware[i](h)
+	}
+	s.chain = h
+}
+
+// requestHandler returns the handler of requests, middleware included.
+func (s *Server) requestHandler() RequestHandler {
+	s.chainMu.RLock()
+	defer s.chainMu.RUnlock()
+	if s.chain == nil {
+		return s.serveRequest
+	}
+	return s.chain
+}
+
+// SetDiagnosticRegister sets the diagnostic register of a unit, returned by
+// FC08 sub-function 02 until it is cleared by sub-function 0A.
+func (s *Server) SetDiagnosticRegister(unitID UnitID, value uint16) {
+	s.comm.setDiagnosticRegister(unitID, value)
+}
+
 // Metrics returns the server metrics.
 func (s *Server) Metrics() *ServerMetrics {
 	return s.metrics
@@ -109,7 +188,7 @@ func (s *Server) Serve(listener net.Listener) error {
 		s.mu.Unlock()
 
 		// Configure TCP options
-		if tcpConn, ok := conn.(*net.TCPConn); ok {
+		if tcpConn, ok := tcpConnOf(conn); ok {
 			tcpConn.SetKeepAlive(true)
 			tcpConn.SetKeepAlivePeriod(30 * time.Second)
 			tcpConn.SetNoDelay(true)
@@ -120,11 +199,97 @@ func (s *Server) Serve(listener net.Listener) error {
 	}
 }
 
+// ListenAndServeUDP starts serving UDP datagrams on the given address.
+func (s *Server) ListenAndServeUDP(addr string) error {
+	conn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return err
+	}
+	return s.ServeUDP(conn)
+}
+
+// ServeUDP answers Modbus TCP frames received as datagrams on conn. Each
+// datagram carries one request and is answered with one datagram.
+func (s *Server) ServeUDP(conn net.PacketConn) error {
+	s.mu.Lock()
+	if atomic.LoadInt32(&s.closed) == 1 {
+		s.mu.Unlock()
+		return conn.Close()
+	}
+	s.packetConns[conn] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.packetConns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	s.opts.logger.Info("server started", slog.String("addr", conn.LocalAddr().String()),
+		slog.String("network", "udp"))
+
+	buf := make([]byte, MBAPHeaderSize+MaxPDUSize)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if atomic.LoadInt32(&s.closed) == 1 {
+				return nil
+			}
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				continue
+			}
+			return err
+		}
+
+		s.handleDatagram(conn, addr, buf[:n])
+	}
+}
+
+// handleDatagram answers a single request datagram. Malformed datagrams
+// are dropped without a response.
+func (s *Server) handleDatagram(conn net.PacketConn, addr net.Addr, data []byte) {
+	defer func() {
+		// Recover from panic to prevent server crash
+		if r := recover(); r != nil {
+			s.opts.logger.Error("panic in datagram handler",
+				slog.String("remote", addr.String()),
+				slog.Any("panic", r),
+				slog.String("stack", string(debug.Stack())))
+		}
+	}()
+
+	var frame Frame
+	if err := frame.Decode(data); err != nil {
+		s.opts.logger.Debug("invalid datagram",
+			slog.String("remote", addr.String()),
+			slog.String("error", err.Error()))
+		return
+	}
+
+	s.metrics.RequestsTotal.Add(1)
+	response := s.processRequest(s.ctx, &frame, &peer{addr: addr})
+	if response == nil {
+		return
+	}
+
+	if _, err := conn.WriteTo(response.Encode(), addr); err != nil {
+		s.metrics.RequestsErrors.Add(1)
+		s.opts.logger.Debug("write error",
+			slog.String("remote", addr.String()),
+			slog.String("error", err.Error()))
+		return
+	}
+
+	s.metrics.RequestsSuccess.Add(1)
+}
+
 // Close shuts down the server gracefully.
 func (s *Server) Close() error {
 	if !atomic.CompareAndSwapInt32(&s.closed, 0, 1) {
 		return nil
 	}
+	s.cancel()
 
 	s.mu.Lock()
 	var err error
@@ -134,6 +299,9 @@ func (s *Server) Close() error {
 	for conn := range s.conns {
 		conn.Close()
 	}
+	for conn := range s.packetConns {
+		conn.Close()
+	}
 	s.mu.Unlock()
 
 	s.wg.Wait()
@@ -179,6 +347,22 @@ func (s *Server) handleConn(conn net.Conn) {
 	s.opts.logger.Debug("connection accepted",
 		slog.String("remote", conn.RemoteAddr().String()))
 
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+
+	// Modbus/TCP Security: requests are authorized by client role
+	var role string
+	tlsConn, secure := conn.(*tls.Conn)
+	if secure {
+		var err error
+		if role, err = s.tlsRole(tlsConn); err != nil {
+			s.opts.logger.Debug("tls handshake failed",
+				slog.String("remote", conn.RemoteAddr().String()),
+				slog.String("error", err.Error()))
+			return
+		}
+	}
+
 	for {
 		if atomic.LoadInt32(&s.closed) == 1 {
 			return
@@ -202,7 +386,10 @@ func (s *Server) handleConn(conn net.Conn) {
 		}
 
 		s.metrics.RequestsTotal.Add(1)
-		response := s.processRequest(frame)
+		response := s.processRequest(ctx, frame, &peer{addr: conn.RemoteAddr(), secure: secure, role: role})
+		if response == nil {
+			continue
+		}
 
 		// Set write deadline
 		if s.opts.readTimeout > 0 {
@@ -221,7 +408,18 @@ func (s *Server) handleConn(conn net.Conn) {
 	}
 }
 
-func (s *Server) processRequest(req *Frame) *Frame {
+// peer is the client a request was received from.
+type peer struct {
+	addr   net.Addr
+	secure bool   // Modbus/TCP Security connection
+	role   string // role of the client certificate
+}
+
+// processRequest returns the response to a request, or nil when no
+// response must be sent (listen only mode). from is nil for requests
+// without a known client. Requests of Modbus/TCP Security clients are
+// authorized by role.
+func (s *Server) processRequest(ctx context.Context, req *Frame, from *peer) *Frame {
 	resp := &Frame{
 		Header: MBAPHeader{
 			TransactionID: req.Header.TransactionID,
@@ -243,44 +441,99 @@ func (s *Server) processRequest(req *Frame) *Frame {
 		slog.Uint64("unit_id", uint64(unitID)),
 		slog.String("func", fc.String()))
 
+	if listenOnly := s.comm.received(unitID); listenOnly && !isRestartCommunications(req.PDU) {
+		s.comm.ignored(unitID)
+		return nil
+	}
+
+	if from == nil {
+		from = &peer{}
+	}
+
 	var pdu []byte
-	var err error
+	if from.secure && !s.authorized(from.role, unitID, fc) {
+		pdu = s.buildException(fc, ExceptionIllegalFunction)
+	} else {
+		var err error
+		pdu, err = s.requestHandler()(ctx, &ServerRequest{
+			UnitID:        unitID,
+			TransactionID: req.Header.TransactionID,
+			PDU:           req.PDU,
+			RemoteAddr:    from.addr,
+			Role:          from.role,
+		})
+		if err != nil {
+			pdu = s.handleError(fc, err)
+		}
+	}
+
+	s.comm.sent(unitID, fc, pdu)
+	if pdu == nil {
+		return nil
+	}
+	resp.PDU = pdu
+	return resp
+}
+
+// serveRequest is the innermost RequestHandler: it serves a request with
+// the function registered for its code or the built-in implementation.
+func (s *Server) serveRequest(ctx context.Context, req *ServerRequest) ([]byte, error) {
+	fc := req.FunctionCode()
+	unitID := req.UnitID
+
+	if fn := s.functionHandler(fc); fn != nil {
+		return fn(unitID, req.PDU)
+	}
 
 	switch fc {
 	case FuncReadCoils:
-		pdu, err = s.handleReadCoils(unitID, req.PDU)
+		return s.handleReadCoils(unitID, req.PDU)
 	case FuncReadDiscreteInputs:
-		pdu, err = s.handleReadDiscreteInputs(unitID, req.PDU)
+		return s.handleReadDiscreteInputs(unitID, req.PDU)
 	case FuncReadHoldingRegisters:
-		pdu, err = s.handleReadHoldingRegisters(unitID, req.PDU)
+		return s.handleReadHoldingRegisters(unitID, req.PDU)
 	case FuncReadInputRegisters:
-		pdu, err = s.handleReadInputRegisters(unitID, req.PDU)
+		return s.handleReadInputRegisters(unitID, req.PDU)
 	case FuncWriteSingleCoil:
-		pdu, err = s.handleWriteSingleCoil(unitID, req.PDU)
+		return s.handleWriteSingleCoil(unitID, req.PDU)
 	case FuncWriteSingleRegister:
-		pdu, err = s.handleWriteSingleRegister(unitID, req.PDU)
+		return s.handleWriteSingleRegister(unitID, req.PDU)
 	case FuncReadExceptionStatus:
-		pdu, err = s.handleReadExceptionStatus(unitID, req.PDU)
+		return s.handleReadExceptionStatus(unitID, req.PDU)
 	case FuncDiagnostics:
-		pdu, err = s.handleDiagnostics(unitID, req.PDU)
+		return s.handleDiagnostics(unitID, req.PDU)
 	case FuncGetCommEventCounter:
-		pdu, err = s.handleGetCommEventCounter(unitID, req.PDU)
+		return s.handleGetCommEventCounter(unitID, req.PDU)
+	case FuncGetCommEventLog:
+		return s.handleGetCommEventLog(unitID, req.PDU)
 	case FuncWriteMultipleCoils:
-		pdu, err = s.handleWriteMultipleCoils(unitID, req.PDU)
+		return s.handleWriteMultipleCoils(unitID, req.PDU)
 	case FuncWriteMultipleRegisters:
-		pdu, err = s.handleWriteMultipleRegisters(unitID, req.PDU)
+		return s.handleWriteMultipleRegisters(unitID, req.PDU)
 	case FuncReportServerID:
-		pdu, err = s.handleReportServerID(unitID, req.PDU)
+		return s.handleReportServerID(unitID, req.PDU)
+	case FuncReadFileRecord:
+		return s.handleReadFileRecord(unitID, req.PDU)
+	case FuncWriteFileRecord:
+		return s.handleWriteFileRecord(unitID, req.PDU)
+	case FuncMaskWriteRegister:
+		return s.handleMaskWriteRegister(unitID, req.PDU)
+	case FuncReadWriteMultipleRegisters:
+		return s.handleReadWriteMultipleRegisters(unitID, req.PDU)
+	case FuncReadFIFOQueue:
+		return s.handleReadFIFOQueue(unitID, req.PDU)
+	case FuncEncapsulatedInterfaceTransport:
+		return s.handleEncapsulatedInterfaceTransport(unitID, req.PDU)
 	default:
-		pdu = s.buildException(fc, ExceptionIllegalFunction)
-	}
-
-	if err != nil {
-		pdu = s.handleError(fc, err)
+		return s.buildException(fc, ExceptionIllegalFunction), nil
 	}
+}
 
-	resp.PDU = pdu
-	return resp
+// isRestartCommunications reports whether pdu is a Restart Communications
+// request, the only one served in listen only mode.
+func isRestartCommunications(pdu []byte) bool {
+	return len(pdu) >= 3 && FunctionCode(pdu[0]) == FuncDiagnostics &&
+		binary.BigEndian.Uint16(pdu[1:3]) == DiagRestartCommunications
 }
 
 func (s *Server) buildException(fc FunctionCode, ec ExceptionCode) []byte {
@@ -288,7 +541,8 @@ func (s *Server) buildException(fc FunctionCode, ec ExceptionCode) []byte {
 }
 
 func (s *Server) handleError(fc FunctionCode, err error) []byte {
-	if modbusErr, ok := err.(*ModbusError); ok {
+	var modbusErr *ModbusError
+	if errors.As(err, &modbusErr) {
 		return s.buildException(fc, modbusErr.ExceptionCode)
 	}
 	s.opts.logger.Error("handler error",
@@ -496,20 +750,80 @@ func (s *Server) handleDiagnostics(unitID UnitID, pdu []byte) ([]byte, error) {
 	subFunc := binary.BigEndian.Uint16(pdu[1:3])
 	data := pdu[3:]
 
+	switch subFunc {
+	case DiagRestartCommunications:
+		// 0x0000 keeps the event log, 0xFF00 clears it
+		if len(data) != 2 || (data[0] != 0x00 && data[0] != 0xFF) || data[1] != 0x00 {
+			return s.buildException(FuncDiagnostics, ExceptionIllegalDataValue), nil
+		}
+		if s.comm.restart(unitID, data[0] == 0xFF) {
+			return nil, nil
+		}
+		return diagnosticsResponse(subFunc, data), nil
+
+	case DiagChangeASCIIInputDelimiter:
+		// Network transports have no delimiter; the request is acknowledged
+		if len(data) != 2 || data[1] != 0x00 {
+			return s.buildException(FuncDiagnostics, ExceptionIllegalDataValue), nil
+		}
+		return diagnosticsResponse(subFunc, data), nil
+
+	case DiagForceListenOnlyMode:
+		if !isZeroDiagnosticsData(data) {
+			return s.buildException(FuncDiagnostics, ExceptionIllegalDataValue), nil
+		}
+		s.comm.forceListenOnly(unitID)
+		return nil, nil
+
+	case DiagClearCountersAndDiagnosticRegister, DiagClearOverrunCounterAndFlag:
+		if !isZeroDiagnosticsData(data) {
+			return s.buildException(FuncDiagnostics, ExceptionIllegalDataValue), nil
+		}
+		if subFunc == DiagClearCountersAndDiagnosticRegister {
+			s.comm.clearCounters(unitID)
+		}
+		return diagnosticsResponse(subFunc, data), nil
+
+	case DiagReturnDiagnosticRegister,
+		DiagReturnBusMessageCount,
+		DiagReturnBusCommunicationErrorCount,
+		DiagReturnBusExceptionErrorCount,
+		DiagReturnServerMessageCount,
+		DiagReturnServerNoResponseCount,
+		DiagReturnServerNAKCount,
+		DiagReturnServerBusyCount,
+		DiagReturnBusCharacterOverrunCount:
+		if !isZeroDiagnosticsData(data) {
+			return s.buildException(FuncDiagnostics, ExceptionIllegalDataValue), nil
+		}
+		value := s.comm.diagnosticValue(unitID, subFunc)
+		return diagnosticsResponse(subFunc, []byte{byte(value >> 8), byte(value)}), nil
+	}
+
 	respData, err := s.handler.Diagnostics(unitID, subFunc, data)
 	if err != nil {
 		return nil, err
 	}
+	return diagnosticsResponse(subFunc, respData), nil
+}
 
-	resp := make([]byte, 3+len(respData))
+// diagnosticsResponse builds an FC08 response PDU.
+func diagnosticsResponse(subFunc uint16, data []byte) []byte {
+	resp := make([]byte, 3+len(data))
 	resp[0] = byte(FuncDiagnostics)
 	binary.BigEndian.PutUint16(resp[1:3], subFunc)
-	copy(resp[3:], respData)
-	return resp, nil
+	copy(resp[3:], data)
+	return resp
+}
+
+// isZeroDiagnosticsData reports whether data is the 0x0000 field required by
+// most FC08 sub-functions.
+func isZeroDiagnosticsData(data []byte) bool {
+	return len(data) == 2 && data[0] == 0x00 && data[1] == 0x00
 }
 
 func (s *Server) handleGetCommEventCounter(unitID UnitID, pdu []byte) ([]byte, error) {
-	status, eventCount, err := s.handler.GetCommEventCounter(unitID)
+	status, _, err := s.handler.GetCommEventCounter(unitID)
 	if err != nil {
 		return nil, err
 	}
@@ -517,7 +831,24 @@ func (s *Server) handleGetCommEventCounter(unitID UnitID, pdu []byte) ([]byte, e
 	resp := make([]byte, 5)
 	resp[0] = byte(FuncGetCommEventCounter)
 	binary.BigEndian.PutUint16(resp[1:3], status)
-	binary.BigEndian.PutUint16(resp[3:5], eventCount)
+	binary.BigEndian.PutUint16(resp[3:5], s.comm.eventCounter(unitID))
+	return resp, nil
+}
+
+func (s *Server) handleGetCommEventLog(unitID UnitID, pdu []byte) ([]byte, error) {
+	status, _, err := s.handler.GetCommEventCounter(unitID)
+	if err != nil {
+		return nil, err
+	}
+	eventCount, messageCount, events := s.comm.eventLog(unitID)
+
+	resp := make([]byte, 8+len(events))
+	resp[0] = byte(FuncGetCommEventLog)
+	resp[1] = byte(6 + len(events))
+	binary.BigEndian.PutUint16(resp[2:4], status)
+	binary.BigEndian.PutUint16(resp[4:6], eventCount)
+	binary.BigEndian.PutUint16(resp[6:8], messageCount)
+	copy(resp[8:], events)
 	return resp, nil
 }
 
@@ -529,7 +860,7 @@ func (s *Server) handleWriteMultipleCoils(unitID UnitID, pdu []byte) ([]byte, er
 	qty := binary.BigEndian.Uint16(pdu[3:5])
 	byteCount := int(pdu[5])
 
-	if qty < 1 || qty > MaxQuantityCoils {
+	if qty < 1 || qty > MaxQuantityWriteCoils {
 		return s.buildException(FuncWriteMultipleCoils, ExceptionIllegalDataValue), nil
 	}
 
@@ -613,6 +944,318 @@ func (s *Server) handleReportServerID(unitID UnitID, pdu []byte) ([]byte, error)
 	return resp, nil
 }
 
+func (s *Server) handleReadFileRecord(unitID UnitID, pdu []byte) ([]byte, error) {
+	h, ok := s.handler.(FileRecordHandler)
+	if !ok {
+		return s.buildException(FuncReadFileRecord, ExceptionIllegalFunction), nil
+	}
+	if len(pdu) < 2 {
+		return s.buildException(FuncReadFileRecord, ExceptionIllegalDataValue), nil
+	}
+	byteCount := int(pdu[1])
+	if byteCount < 0x07 || byteCount > 0xF5 || byteCount%7 != 0 || len(pdu) < 2+byteCount {
+		return s.buildException(FuncReadFileRecord, ExceptionIllegalDataValue), nil
+	}
+
+	resp := []byte{byte(FuncReadFileRecord), 0}
+	for sub := pdu[2 : 2+byteCount]; len(sub) > 0; sub = sub[7:] {
+		file := binary.BigEndian.Uint16(sub[1:3])
+		record := binary.BigEndian.Uint16(sub[3:5])
+		length := binary.BigEndian.Uint16(sub[5:7])
+
+		if sub[0] != FileRecordReferenceType || file == 0 || record > MaxFileRecordNumber {
+			return s.buildException(FuncReadFileRecord, ExceptionIllegalDataAddress), nil
+		}
+		if length < 1 || len(resp)+2+2*int(length) > MaxPDUSize {
+			return s.buildException(FuncReadFileRecord, ExceptionIllegalDataValue), nil
+		}
+
+		values, err := h.ReadFileRecord(unitID, file, record, length)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) != int(length) {
+			return nil, fmt.Errorf("file record handler returned %d registers, expected %d", len(values), length)
+		}
+		resp = append(resp, byte(1+2*length), FileRecordReferenceType)
+		resp = append(resp, Uint16sToBytes(values)...)
+	}
+	resp[1] = byte(len(resp) - 2)
+	return resp, nil
+}
+
+func (s *Server) handleWriteFileRecord(unitID UnitID, pdu []byte) ([]byte, error) {
+	h, ok := s.handler.(FileRecordHandler)
+	if !ok {
+		return s.buildException(FuncWriteFileRecord, ExceptionIllegalFunction), nil
+	}
+	if len(pdu) < 2 {
+		return s.buildException(FuncWriteFileRecord, ExceptionIllegalDataValue), nil
+	}
+	byteCount := int(pdu[1])
+	if byteCount < 0x09 || byteCount > 0xFB || len(pdu) < 2+byteCount {
+		return s.buildException(FuncWriteFileRecord, ExceptionIllegalDataValue), nil
+	}
+
+	// Validate every sub-request before writing any of them
+	var records []FileRecord
+	for sub := pdu[2 : 2+byteCount]; len(sub) > 0; {
+		if len(sub) < 7 {
+			return s.buildException(FuncWriteFileRecord, ExceptionIllegalDataValue), nil
+		}
+		file := binary.BigEndian.Uint16(sub[1:3])
+		record := binary.BigEndian.Uint16(sub[3:5])
+		length := int(binary.BigEndian.Uint16(sub[5:7]))
+
+		if sub[0] != FileRecordReferenceType || file == 0 || record > MaxFileRecordNumber {
+			return s.buildException(FuncWriteFileRecord, ExceptionIllegalDataAddress), nil
+		}
+		if length < 1 || len(sub) < 7+2*length {
+			return s.buildException(FuncWriteFileRecord, ExceptionIllegalDataValue), nil
+		}
+
+		records = append(records, FileRecord{
+			File:   file,
+			Record: record,
+			Data:   BytesToUint16s(sub[7 : 7+2*length]),
+		})
+		sub = sub[7+2*length:]
+	}
+
+	for _, r := range records {
+		if err := h.WriteFileRecord(unitID, r.File, r.Record, r.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	// The response echoes the request
+	resp := make([]byte, 2+byteCount)
+	copy(resp, pdu)
+	return resp, nil
+}
+
+func (s *Server) handleMaskWriteRegister(unitID UnitID, pdu []byte) ([]byte, error) {
+	if len(pdu) < 7 {
+		return s.buildException(FuncMaskWriteRegister, ExceptionIllegalDataValue), nil
+	}
+	addr := binary.BigEndian.Uint16(pdu[1:3])
+	andMask := binary.BigEndian.Uint16(pdu[3:5])
+	orMask := binary.BigEndian.Uint16(pdu[5:7])
+
+	if h, ok := s.handler.(MaskWriteRegisterHandler); ok {
+		if err := h.MaskWriteRegister(unitID, addr, andMask, orMask); err != nil {
+			return nil, err
+		}
+	} else {
+		regs, err := s.handler.ReadHoldingRegisters(unitID, addr, 1)
+		if err != nil {
+			return nil, err
+		}
+		if len(regs) != 1 {
+			return s.buildException(FuncMaskWriteRegister, ExceptionServerDeviceFailure), nil
+		}
+		value := applyMask(regs[0], andMask, orMask)
+		if err := s.handler.WriteSingleRegister(unitID, addr, value); err != nil {
+			return nil, err
+		}
+	}
+
+	// The response echoes the request
+	resp := make([]byte, 7)
+	copy(resp, pdu[:7])
+	return resp, nil
+}
+
+// applyMask computes the result of a mask write register (FC22).
+func applyMask(value, andMask, orMask uint16) uint16 {
+	return (value & andMask) | (orMask &^ andMask)
+}
+
+func (s *Server) handleReadWriteMultipleRegisters(unitID UnitID, pdu []byte) ([]byte, error) {
+	if len(pdu) < 10 {
+		return s.buildException(FuncReadWriteMultipleRegisters, ExceptionIllegalDataValue), nil
+	}
+	readAddr := binary.BigEndian.Uint16(pdu[1:3])
+	readQty := binary.BigEndian.Uint16(pdu[3:5])
+	writeAddr := binary.BigEndian.Uint16(pdu[5:7])
+	writeQty := binary.BigEndian.Uint16(pdu[7:9])
+	byteCount := int(pdu[9])
+
+	if readQty < 1 || readQty > MaxQuantityRegisters ||
+		writeQty < 1 || writeQty > MaxQuantityReadWriteRegisters {
+		return s.buildException(FuncReadWriteMultipleRegisters, ExceptionIllegalDataValue), nil
+	}
+
+	if uint32(readAddr)+uint32(readQty) > 65536 || uint32(writeAddr)+uint32(writeQty) > 65536 {
+		return s.buildException(FuncReadWriteMultipleRegisters, ExceptionIllegalDataAddress), nil
+	}
+
+	expectedBytes := int(writeQty * 2)
+	if byteCount != expectedBytes || len(pdu) < 10+byteCount {
+		return s.buildException(FuncReadWriteMultipleRegisters, ExceptionIllegalDataValue), nil
+	}
+
+	values := make([]uint16, writeQty)
+	for i := uint16(0); i < writeQty; i++ {
+		values[i] = binary.BigEndian.Uint16(pdu[10+i*2:])
+	}
+
+	var regs []uint16
+	var err error
+	if h, ok := s.handler.(ReadWriteMultipleRegistersHandler); ok {
+		regs, err = h.ReadWriteMultipleRegisters(unitID, readAddr, readQty, writeAddr, values)
+	} else {
+		// The write is performed before the read
+		if err = s.handler.WriteMultipleRegisters(unitID, writeAddr, values); err == nil {
+			regs, err = s.handler.ReadHoldingRegisters(unitID, readAddr, readQty)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if uint16(len(regs)) != readQty {
+		return s.buildException(FuncReadWriteMultipleRegisters, ExceptionServerDeviceFailure), nil
+	}
+
+	resp := make([]byte, 2+len(regs)*2)
+	resp[0] = byte(FuncReadWriteMultipleRegisters)
+	resp[1] = byte(len(regs) * 2)
+	for i, v := range regs {
+		binary.BigEndian.PutUint16(resp[2+i*2:], v)
+	}
+	return resp, nil
+}
+
+func (s *Server) handleReadFIFOQueue(unitID UnitID, pdu []byte) ([]byte, error) {
+	h, ok := s.handler.(FIFOQueueHandler)
+	if !ok {
+		return s.buildException(FuncReadFIFOQueue, ExceptionIllegalFunction), nil
+	}
+	if len(pdu) < 3 {
+		return s.buildException(FuncReadFIFOQueue, ExceptionIllegalDataValue), nil
+	}
+	addr := binary.BigEndian.Uint16(pdu[1:3])
+
+	values, err := h.ReadFIFOQueue(unitID, addr)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) > MaxFIFOCount {
+		return s.buildException(FuncReadFIFOQueue, ExceptionIllegalDataValue), nil
+	}
+
+	resp := make([]byte, 5+len(values)*2)
+	resp[0] = byte(FuncReadFIFOQueue)
+	binary.BigEndian.PutUint16(resp[1:3], uint16(2+len(values)*2))
+	binary.BigEndian.PutUint16(resp[3:5], uint16(len(values)))
+	for i, v := range values {
+		binary.BigEndian.PutUint16(resp[5+i*2:], v)
+	}
+	return resp, nil
+}
+
+func (s *Server) handleEncapsulatedInterfaceTransport(unitID UnitID, pdu []byte) ([]byte, error) {
+	if len(pdu) < 2 {
+		return s.buildException(FuncEncapsulatedInterfaceTransport, ExceptionIllegalDataValue), nil
+	}
+	switch pdu[1] {
+	case MEIReadDeviceIdentification:
+		return s.handleReadDeviceIdentification(unitID, pdu)
+	default:
+		return s.buildException(FuncEncapsulatedInterfaceTransport, ExceptionIllegalFunction), nil
+	}
+}
+
+func (s *Server) handleReadDeviceIdentification(unitID UnitID, pdu []byte) ([]byte, error) {
+	h, ok := s.handler.(DeviceIdentificationHandler)
+	if !ok {
+		return s.buildException(FuncEncapsulatedInterfaceTransport, ExceptionIllegalFunction), nil
+	}
+	if len(pdu) < 4 {
+		return s.buildException(FuncEncapsulatedInterfaceTransport, ExceptionIllegalDataValue), nil
+	}
+	readCode := pdu[2]
+	objectID := pdu[3]
+
+	var last uint8
+	switch readCode {
+	case ReadDeviceIDBasic:
+		last = DeviceIDMajorMinorRevision
+	case ReadDeviceIDRegular:
+		last = 0x7F
+	case ReadDeviceIDExtended, ReadDeviceIDIndividual:
+		last = 0xFF
+	default:
+		return s.buildException(FuncEncapsulatedInterfaceTransport, ExceptionIllegalDataValue), nil
+	}
+
+	objects, err := h.DeviceIdentification(unitID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Header: function code, MEI type, read code, conformity level,
+	// more follows, next object ID, number of objects
+	resp := []byte{
+		byte(FuncEncapsulatedInterfaceTransport), MEIReadDeviceIdentification,
+		readCode, deviceIDConformityLevel(objects), 0x00, 0x00, 0x00,
+	}
+
+	if readCode == ReadDeviceIDIndividual {
+		value, ok := objects[objectID]
+		if !ok {
+			return s.buildException(FuncEncapsulatedInterfaceTransport, ExceptionIllegalDataAddress), nil
+		}
+		resp[6] = 1
+		return appendDeviceObject(resp, objectID, value), nil
+	}
+
+	// An unknown starting object restarts the stream at the beginning
+	if _, ok := objects[objectID]; !ok || objectID > last {
+		objectID = DeviceIDVendorName
+	}
+
+	for id := int(objectID); id <= int(last); id++ {
+		value, ok := objects[uint8(id)]
+		if !ok {
+			continue
+		}
+		if resp[6] > 0 && len(resp)+2+len(value) > MaxPDUSize {
+			resp[4] = 0xFF
+			resp[5] = uint8(id)
+			break
+		}
+		resp = appendDeviceObject(resp, uint8(id), value)
+		resp[6]++
+	}
+	return resp, nil
+}
+
+// deviceIDConformityLevel reports the identification level of a set of
+// objects. Individual access is always supported.
+func deviceIDConformityLevel(objects map[uint8][]byte) uint8 {
+	level := ReadDeviceIDBasic
+	for id := range objects {
+		switch {
+		case id >= 0x80:
+			level = max(level, ReadDeviceIDExtended)
+		case id > DeviceIDMajorMinorRevision:
+			level = max(level, ReadDeviceIDRegular)
+		}
+	}
+	return 0x80 | level
+}
+
+// appendDeviceObject appends an identification object to a response,
+// truncating its value to the space left in the PDU.
+func appendDeviceObject(resp []byte, id uint8, value []byte) []byte {
+	if room := MaxPDUSize - len(resp) - 2; len(value) > room {
+		value = value[:room]
+	}
+	resp = append(resp, id, byte(len(value)))
+	return append(resp, value...)
+}
+
 // timeNow is a variable for testing
 var timeNow = time.Now
 
@@ -625,7 +1268,9 @@ type MemoryHandler struct {
 	holdingRegs    map[UnitID][]uint16
 	inputRegs      map[UnitID][]uint16
 	serverID       []byte
-	eventCounter   uint16
+	files          map[UnitID]map[uint16][]uint16
+	fifos          map[UnitID]map[uint16][]uint16
+	deviceID       map[uint8][]byte
 	initialized    map[UnitID]bool
 }
 
@@ -636,8 +1281,15 @@ func NewMemoryHandler(coilSize, registerSize int) *MemoryHandler {
 		discreteInputs: make(map[UnitID][]bool),
 		holdingRegs:    make(map[UnitID][]uint16),
 		inputRegs:      make(map[UnitID][]uint16),
+		files:          make(map[UnitID]map[uint16][]uint16),
+		fifos:          make(map[UnitID]map[uint16][]uint16),
 		serverID:       []byte("Modbus Server"),
-		initialized:    make(map[UnitID]bool),
+		deviceID: map[uint8][]byte{
+			DeviceIDVendorName:         []byte("Edgeo SCADA"),
+			DeviceIDProductCode:        []byte("MemoryHandler"),
+			DeviceIDMajorMinorRevision: []byte(Version),
+		},
+		initialized: make(map[UnitID]bool),
 	}
 }
 
@@ -678,7 +1330,7 @@ func (h *MemoryHandler) ReadCoils(unitID UnitID, addr, qty uint16) ([]bool, erro
 	}
 
 	result := make([]bool, qty)
-	copy(result, h.coils[unitID][addr:addr+qty])
+	copy(result, h.coils[unitID][int(addr):int(addr)+int(qty)])
 	return result, nil
 }
 
@@ -693,7 +1345,7 @@ func (h *MemoryHandler) ReadDiscreteInputs(unitID UnitID, addr, qty uint16) ([]b
 	}
 
 	result := make([]bool, qty)
-	copy(result, h.discreteInputs[unitID][addr:addr+qty])
+	copy(result, h.discreteInputs[unitID][int(addr):int(addr)+int(qty)])
 	return result, nil
 }
 
@@ -736,7 +1388,7 @@ func (h *MemoryHandler) ReadHoldingRegisters(unitID UnitID, addr, qty uint16) ([
 	}
 
 	result := make([]uint16, qty)
-	copy(result, h.holdingRegs[unitID][addr:addr+qty])
+	copy(result, h.holdingRegs[unitID][int(addr):int(addr)+int(qty)])
 	return result, nil
 }
 
@@ -751,7 +1403,7 @@ func (h *MemoryHandler) ReadInputRegisters(unitID UnitID, addr, qty uint16) ([]u
 	}
 
 	result := make([]uint16, qty)
-	copy(result, h.inputRegs[unitID][addr:addr+qty])
+	copy(result, h.inputRegs[unitID][int(addr):int(addr)+int(qty)])
 	return result, nil
 }
 
@@ -783,6 +1435,97 @@ func (h *MemoryHandler) WriteMultipleRegisters(unitID UnitID, addr uint16, value
 	return nil
 }
 
+// MaskWriteRegister applies the masks to a register under the write lock,
+// so concurrent masters cannot interleave between the read and the write.
+func (h *MemoryHandler) MaskWriteRegister(unitID UnitID, addr, andMask, orMask uint16) error {
+	h.getOrInitUnit(unitID)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if int(addr) >= len(h.holdingRegs[unitID]) {
+		return NewModbusError(FuncMaskWriteRegister, ExceptionIllegalDataAddress)
+	}
+
+	h.holdingRegs[unitID][addr] = applyMask(h.holdingRegs[unitID][addr], andMask, orMask)
+	return nil
+}
+
+// ReadWriteMultipleRegisters writes values and then reads registers while
+// holding the lock, so the operation is atomic.
+func (h *MemoryHandler) ReadWriteMultipleRegisters(unitID UnitID, readAddr, readQty, writeAddr uint16, values []uint16) ([]uint16, error) {
+	h.getOrInitUnit(unitID)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	regs := h.holdingRegs[unitID]
+	if int(writeAddr)+len(values) > len(regs) || int(readAddr)+int(readQty) > len(regs) {
+		return nil, NewModbusError(FuncReadWriteMultipleRegisters, ExceptionIllegalDataAddress)
+	}
+
+	copy(regs[writeAddr:], values)
+
+	result := make([]uint16, readQty)
+	copy(result, regs[readAddr:])
+	return result, nil
+}
+
+func (h *MemoryHandler) ReadFileRecord(unitID UnitID, file, record, length uint16) ([]uint16, error) {
+	if int(record)+int(length) > MaxFileRecordNumber+1 {
+		return nil, NewModbusError(FuncReadFileRecord, ExceptionIllegalDataAddress)
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	// Files that were never written read as zeros
+	result := make([]uint16, length)
+	if data, ok := h.files[unitID][file]; ok {
+		copy(result, data[record:])
+	}
+	return result, nil
+}
+
+func (h *MemoryHandler) WriteFileRecord(unitID UnitID, file, record uint16, values []uint16) error {
+	if int(record)+len(values) > MaxFileRecordNumber+1 {
+		return NewModbusError(FuncWriteFileRecord, ExceptionIllegalDataAddress)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	copy(h.fileLocked(unitID, file)[record:], values)
+	return nil
+}
+
+// fileLocked returns the records of a file, allocating it if needed.
+// Must be called with write lock held.
+func (h *MemoryHandler) fileLocked(unitID UnitID, file uint16) []uint16 {
+	if h.files[unitID] == nil {
+		h.files[unitID] = make(map[uint16][]uint16)
+	}
+	data, ok := h.files[unitID][file]
+	if !ok {
+		data = make([]uint16, MaxFileRecordNumber+1)
+		h.files[unitID][file] = data
+	}
+	return data
+}
+
+func (h *MemoryHandler) ReadFIFOQueue(unitID UnitID, addr uint16) ([]uint16, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	queue, ok := h.fifos[unitID][addr]
+	if !ok {
+		return nil, NewModbusError(FuncReadFIFOQueue, ExceptionIllegalDataAddress)
+	}
+	result := make([]uint16, len(queue))
+	copy(result, queue)
+	return result, nil
+}
+
 func (h *MemoryHandler) ReadExceptionStatus(unitID UnitID) (uint8, error) {
 	return 0, nil
 }
@@ -799,10 +1542,9 @@ func (h *MemoryHandler) Diagnostics(unitID UnitID, subFunc uint16, data []byte)
 	}
 }
 
+// GetCommEventCounter reports a ready status; the event count is kept by the Server.
 func (h *MemoryHandler) GetCommEventCounter(unitID UnitID) (status uint16, eventCount uint16, err error) {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
-	return 0xFFFF, h.eventCounter, nil
+	return 0x0000, 0, nil
 }
 
 func (h *MemoryHandler) ReportServerID(unitID UnitID) ([]byte, error) {
@@ -822,6 +1564,29 @@ func (h *MemoryHandler) SetServerID(id []byte) {
 	copy(h.serverID, id)
 }
 
+func (h *MemoryHandler) DeviceIdentification(unitID UnitID) (map[uint8][]byte, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	// Return a copy
+	result := make(map[uint8][]byte, len(h.deviceID))
+	for id, value := range h.deviceID {
+		result[id] = append([]byte(nil), value...)
+	}
+	return result, nil
+}
+
+// SetDeviceIdentification sets a device identification object returned by
+// read device identification (FC43/14). A nil value removes the object.
+func (h *MemoryHandler) SetDeviceIdentification(objectID uint8, value []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if value == nil {
+		delete(h.deviceID, objectID)
+		return
+	}
+	h.deviceID[objectID] = append([]byte(nil), value...)
+}
+
 // SetCoil sets a coil value directly.
 func (h *MemoryHandler) SetCoil(unitID UnitID, addr uint16, value bool) {
 	h.mu.Lock()
@@ -862,6 +1627,46 @@ func (h *MemoryHandler) SetInputRegister(unitID UnitID, addr, value uint16) {
 	}
 }
 
+// SetFileRecord sets file records directly, starting at record.
+func (h *MemoryHandler) SetFileRecord(unitID UnitID, file, record uint16, values []uint16) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if int(record) <= MaxFileRecordNumber {
+		copy(h.fileLocked(unitID, file)[record:], values)
+	}
+}
+
+// SetFIFOQueue makes addr a FIFO queue address holding values (FC24).
+// A nil values removes the queue.
+func (h *MemoryHandler) SetFIFOQueue(unitID UnitID, addr uint16, values []uint16) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if values == nil {
+		delete(h.fifos[unitID], addr)
+		return
+	}
+	if h.fifos[unitID] == nil {
+		h.fifos[unitID] = make(map[uint16][]uint16)
+	}
+	h.fifos[unitID][addr] = append([]uint16(nil), values...)
+}
+
+// PushFIFOQueue appends a value to the FIFO queue at addr, creating the
+// queue if needed. The oldest value is dropped once the queue holds
+// MaxFIFOCount values.
+func (h *MemoryHandler) PushFIFOQueue(unitID UnitID, addr uint16, value uint16) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.fifos[unitID] == nil {
+		h.fifos[unitID] = make(map[uint16][]uint16)
+	}
+	queue := append(h.fifos[unitID][addr], value)
+	if len(queue) > MaxFIFOCount {
+		queue = queue[len(queue)-MaxFIFOCount:]
+	}
+	h.fifos[unitID][addr] = queue
+}
+
 // ListenAndServeContext starts the server with context support.
 func (s *Server) ListenAndServeContext(ctx context.Context, addr string) error {
 	listener, err := net.Listen("tcp", addr)
@@ -15,7 +15,12 @@
 package modbus
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"net"
+	"sync"
 	"testing"
 	"time"
 )
@@ -173,6 +178,147 @@ func TestMemoryHandler_Diagnostics(t *testing.T) {
 	}
 }
 
+func TestMemoryHandler_ReadWriteMultipleRegisters(t *testing.T) {
+	handler := NewMemoryHandler(65536, 65536)
+	handler.SetHoldingRegister(1, 9, 90)
+
+	// The write is applied before the read
+	regs, err := handler.ReadWriteMultipleRegisters(1, 9, 3, 10, []uint16{100, 110})
+	if err != nil {
+		t.Fatalf("ReadWriteMultipleRegisters failed: %v", err)
+	}
+	expected := []uint16{90, 100, 110}
+	for i, v := range expected {
+		if regs[i] != v {
+			t.Errorf("Register[%d]: expected %d, got %d", 9+i, v, regs[i])
+		}
+	}
+
+	if _, err := handler.ReadWriteMultipleRegisters(1, 0, 1, 65535, []uint16{1, 2}); !IsIllegalDataAddress(err) {
+		t.Errorf("Expected illegal data address, got %v", err)
+	}
+}
+
+func TestMemoryHandler_MaskWriteRegister(t *testing.T) {
+	handler := NewMemoryHandler(65536, 65536)
+
+	// Example from the specification
+	handler.SetHoldingRegister(1, 4, 0x0012)
+	if err := handler.MaskWriteRegister(1, 4, 0x00F2, 0x0025); err != nil {
+		t.Fatalf("MaskWriteRegister failed: %v", err)
+	}
+	regs, _ := handler.ReadHoldingRegisters(1, 4, 1)
+	if regs[0] != 0x0017 {
+		t.Errorf("Expected 0x0017, got 0x%04X", regs[0])
+	}
+
+	// Concurrent masters setting different bits of the same register
+	var wg sync.WaitGroup
+	for bit := 0; bit < 16; bit++ {
+		wg.Add(1)
+		go func(bit int) {
+			defer wg.Done()
+			mask := uint16(1) << bit
+			handler.MaskWriteRegister(1, 5, ^mask, mask)
+		}(bit)
+	}
+	wg.Wait()
+
+	regs, _ = handler.ReadHoldingRegisters(1, 5, 1)
+	if regs[0] != 0xFFFF {
+		t.Errorf("Expected 0xFFFF, got 0x%04X", regs[0])
+	}
+}
+
+func TestServerMaskWriteRegister(t *testing.T) {
+	pdu := BuildMaskWriteRegisterPDU(4, 0x00F2, 0x0025)
+
+	handlers := map[string]func(*MemoryHandler) Handler{
+		"Atomic":   func(h *MemoryHandler) Handler { return h },
+		"Fallback": func(h *MemoryHandler) Handler { return basicHandler{h} },
+	}
+
+	for name, wrap := range handlers {
+		t.Run(name, func(t *testing.T) {
+			memory := NewMemoryHandler(65536, 65536)
+			memory.SetHoldingRegister(1, 4, 0x0012)
+			server := NewServer(wrap(memory))
+
+			resp := server.processRequest(context.Background(), &Frame{Header: MBAPHeader{UnitID: 1}, PDU: pdu}, nil)
+			if !bytes.Equal(resp.PDU, pdu) {
+				t.Errorf("Expected echo % X, got % X", pdu, resp.PDU)
+			}
+			regs, _ := memory.ReadHoldingRegisters(1, 4, 1)
+			if regs[0] != 0x0017 {
+				t.Errorf("Expected 0x0017, got 0x%04X", regs[0])
+			}
+		})
+	}
+
+	// A handler returning no register is a device failure
+	server := NewServer(emptyReadHandler{basicHandler{NewMemoryHandler(65536, 65536)}})
+	resp := server.processRequest(context.Background(), &Frame{Header: MBAPHeader{UnitID: 1}, PDU: pdu}, nil)
+	if !bytes.Equal(resp.PDU, []byte{0x96, byte(ExceptionServerDeviceFailure)}) {
+		t.Errorf("Expected server device failure exception, got % X", resp.PDU)
+	}
+}
+
+// basicHandler hides the optional interfaces implemented by MemoryHandler.
+type basicHandler struct {
+	Handler
+}
+
+// emptyReadHandler returns no registers to holding register reads.
+type emptyReadHandler struct {
+	basicHandler
+}
+
+func (emptyReadHandler) ReadHoldingRegisters(unitID UnitID, addr, qty uint16) ([]uint16, error) {
+	return nil, nil
+}
+
+func TestServerReadWriteMultipleRegisters(t *testing.T) {
+	pdu, err := BuildReadWriteMultipleRegistersPDU(0, 2, 1, []uint16{0xBEEF})
+	if err != nil {
+		t.Fatalf("BuildReadWriteMultipleRegistersPDU failed: %v", err)
+	}
+
+	handlers := map[string]func(*MemoryHandler) Handler{
+		"Atomic":   func(h *MemoryHandler) Handler { return h },
+		"Fallback": func(h *MemoryHandler) Handler { return basicHandler{h} },
+	}
+
+	for name, wrap := range handlers {
+		t.Run(name, func(t *testing.T) {
+			memory := NewMemoryHandler(65536, 65536)
+			memory.SetHoldingRegister(1, 0, 7)
+			server := NewServer(wrap(memory))
+
+			resp := server.processRequest(context.Background(), &Frame{Header: MBAPHeader{UnitID: 1}, PDU: pdu}, nil)
+			expected := []byte{0x17, 0x04, 0x00, 0x07, 0xBE, 0xEF}
+			if !bytes.Equal(resp.PDU, expected) {
+				t.Errorf("Expected % X, got % X", expected, resp.PDU)
+			}
+		})
+	}
+
+	// Malformed byte count
+	server := NewServer(NewMemoryHandler(65536, 65536))
+	bad := append([]byte(nil), pdu...)
+	bad[9] = 4
+	resp := server.processRequest(context.Background(), &Frame{Header: MBAPHeader{UnitID: 1}, PDU: bad}, nil)
+	if !bytes.Equal(resp.PDU, []byte{0x97, byte(ExceptionIllegalDataValue)}) {
+		t.Errorf("Expected illegal data value exception, got % X", resp.PDU)
+	}
+
+	// A handler returning fewer registers than requested is a device failure
+	server = NewServer(emptyReadHandler{basicHandler{NewMemoryHandler(65536, 65536)}})
+	resp = server.processRequest(context.Background(), &Frame{Header: MBAPHeader{UnitID: 1}, PDU: pdu}, nil)
+	if !bytes.Equal(resp.PDU, []byte{0x97, byte(ExceptionServerDeviceFailure)}) {
+		t.Errorf("Expected server device failure exception, got % X", resp.PDU)
+	}
+}
+
 func TestMemoryHandler_ServerID(t *testing.T) {
 	handler := NewMemoryHandler(65536, 65536)
 	unitID := UnitID(1)
@@ -189,6 +335,424 @@ func TestMemoryHandler_ServerID(t *testing.T) {
 	}
 }
 
+func TestMemoryHandler_EndOfAddressSpace(t *testing.T) {
+	handler := NewMemoryHandler(65536, 65536)
+	handler.SetHoldingRegister(1, 65535, 42)
+	handler.SetCoil(1, 65535, true)
+
+	regs, err := handler.ReadHoldingRegisters(1, 65534, 2)
+	if err != nil || regs[1] != 42 {
+		t.Errorf("Expected [0 42], got %v (%v)", regs, err)
+	}
+	coils, err := handler.ReadCoils(1, 65000, 536)
+	if err != nil || !coils[535] {
+		t.Errorf("Expected last coil set, got %v", err)
+	}
+}
+
+func TestServerHandleFunc(t *testing.T) {
+	server := NewServer(NewMemoryHandler(65536, 65536))
+	request := func(pdu []byte) []byte {
+		return server.processRequest(context.Background(), &Frame{Header: MBAPHeader{UnitID: 1}, PDU: pdu}, nil).PDU
+	}
+
+	server.HandleFunc(0x41, func(unitID UnitID, pdu []byte) ([]byte, error) {
+		if len(pdu) < 2 {
+			return nil, NewModbusError(0x41, ExceptionIllegalDataValue)
+		}
+		return append([]byte{0x41, byte(unitID)}, pdu[1:]...), nil
+	})
+
+	if resp := request([]byte{0x41, 0xAA, 0xBB}); !bytes.Equal(resp, []byte{0x41, 0x01, 0xAA, 0xBB}) {
+		t.Errorf("Expected custom response, got % X", resp)
+	}
+	if resp := request([]byte{0x41}); !bytes.Equal(resp, []byte{0xC1, 0x03}) {
+		t.Errorf("Expected illegal data value exception, got % X", resp)
+	}
+	if resp := request([]byte{0x42}); !bytes.Equal(resp, []byte{0xC2, 0x01}) {
+		t.Errorf("Expected illegal function for unregistered code, got % X", resp)
+	}
+
+	// Registered functions replace standard ones until removed
+	server.HandleFunc(FuncReadExceptionStatus, func(unitID UnitID, pdu []byte) ([]byte, error) {
+		return []byte{byte(FuncReadExceptionStatus), 0x5A}, nil
+	})
+	if resp := request(BuildReadExceptionStatusPDU()); !bytes.Equal(resp, []byte{0x07, 0x5A}) {
+		t.Errorf("Expected overridden response, got % X", resp)
+	}
+	server.HandleFunc(FuncReadExceptionStatus, nil)
+	if resp := request(BuildReadExceptionStatusPDU()); !bytes.Equal(resp, []byte{0x07, 0x00}) {
+		t.Errorf("Expected built-in response, got % X", resp)
+	}
+}
+
+func TestServerDiagnosticCounters(t *testing.T) {
+	server := NewServer(NewMemoryHandler(65536, 65536))
+	request := func(pdu []byte) []byte {
+		resp := server.processRequest(context.Background(), &Frame{Header: MBAPHeader{UnitID: 1}, PDU: pdu}, nil)
+		if resp == nil {
+			return nil
+		}
+		return resp.PDU
+	}
+	counter := func(subFunc uint16) uint16 {
+		t.Helper()
+		resp := request(BuildDiagnosticsPDU(subFunc, []byte{0x00, 0x00}))
+		_, data, err := ParseDiagnosticsResponse(resp)
+		if err != nil || len(data) != 2 {
+			t.Fatalf("Sub-function %02X failed: % X", subFunc, resp)
+		}
+		return uint16(data[0])<<8 | uint16(data[1])
+	}
+
+	read, _ := BuildReadHoldingRegistersPDU(0, 1)
+	request(read)
+	request([]byte{byte(FuncReadHoldingRegisters), 0x00, 0x00, 0x00, 0x00})
+
+	tests := []struct {
+		subFunc  uint16
+		expected uint16
+	}{
+		{DiagReturnBusMessageCount, 3},
+		{DiagReturnBusExceptionErrorCount, 1},
+		{DiagReturnServerMessageCount, 4},
+		{DiagReturnServerNoResponseCount, 0},
+		{DiagReturnServerNAKCount, 0},
+		{DiagReturnServerBusyCount, 0},
+		{DiagReturnBusCommunicationErrorCount, 0},
+		{DiagReturnBusCharacterOverrunCount, 0},
+	}
+	for _, tt := range tests {
+		if got := counter(tt.subFunc); got != tt.expected {
+			t.Errorf("Sub-function %02X: expected %d, got %d", tt.subFunc, tt.expected, got)
+		}
+	}
+
+	// Counter requests carry a 0x0000 data field
+	if resp := request(BuildDiagnosticsPDU(DiagReturnBusMessageCount, nil)); !bytes.Equal(resp, []byte{0x88, 0x03}) {
+		t.Errorf("Expected illegal data value, got % X", resp)
+	}
+
+	server.SetDiagnosticRegister(1, 0x1234)
+	if got := counter(DiagReturnDiagnosticRegister); got != 0x1234 {
+		t.Errorf("Expected diagnostic register 0x1234, got 0x%04X", got)
+	}
+
+	clear := BuildDiagnosticsPDU(DiagClearCountersAndDiagnosticRegister, []byte{0x00, 0x00})
+	if resp := request(clear); !bytes.Equal(resp, clear) {
+		t.Errorf("Expected echo, got % X", resp)
+	}
+	if got := counter(DiagReturnDiagnosticRegister); got != 0 {
+		t.Errorf("Expected cleared diagnostic register, got 0x%04X", got)
+	}
+	if got := counter(DiagReturnBusMessageCount); got != 2 {
+		t.Errorf("Expected bus message count 2 after clear, got %d", got)
+	}
+}
+
+func TestServerListenOnlyMode(t *testing.T) {
+	server := NewServer(NewMemoryHandler(65536, 65536))
+	request := func(pdu []byte) *Frame {
+		return server.processRequest(context.Background(), &Frame{Header: MBAPHeader{UnitID: 1}, PDU: pdu}, nil)
+	}
+
+	if resp := request(BuildDiagnosticsPDU(DiagForceListenOnlyMode, []byte{0x00, 0x00})); resp != nil {
+		t.Errorf("Expected no response to force listen only, got % X", resp.PDU)
+	}
+
+	read, _ := BuildReadHoldingRegistersPDU(0, 1)
+	if resp := request(read); resp != nil {
+		t.Errorf("Expected no response in listen only mode, got % X", resp.PDU)
+	}
+	if resp := request(BuildGetCommEventCounterPDU()); resp != nil {
+		t.Errorf("Expected no response in listen only mode, got % X", resp.PDU)
+	}
+
+	// Other units keep answering
+	if resp := server.processRequest(context.Background(), &Frame{Header: MBAPHeader{UnitID: 2}, PDU: read}, nil); resp == nil {
+		t.Error("Expected unit 2 to answer")
+	}
+
+	// Restart communications leaves listen only mode without responding
+	restart := BuildDiagnosticsPDU(DiagRestartCommunications, []byte{0x00, 0x00})
+	if resp := request(restart); resp != nil {
+		t.Errorf("Expected no response to restart in listen only mode, got % X", resp.PDU)
+	}
+	resp := request(read)
+	if resp == nil {
+		t.Fatal("Expected a response after restart")
+	}
+	if _, err := ParseRegistersResponse(resp.PDU, 1); err != nil {
+		t.Errorf("Read after restart failed: %v", err)
+	}
+
+	log, err := ParseGetCommEventLogResponse(request(BuildGetCommEventLogPDU()).PDU)
+	if err != nil {
+		t.Fatalf("ParseGetCommEventLogResponse failed: %v", err)
+	}
+	if log.MessageCount != 2 || log.EventCount != 1 {
+		t.Errorf("Expected counters cleared by restart, got %+v", log)
+	}
+	expected := []byte{
+		EventReceive,
+		EventSend, EventReceive, // read
+		EventSend, EventCommRestart,
+		EventReceive | EventReceiveListenOnly, // restart
+		EventReceive | EventReceiveListenOnly, // FC11
+		EventReceive | EventReceiveListenOnly, // read
+		EventSend | EventSendListenOnly, EventEnteredListenOnly, EventReceive,
+	}
+	if !bytes.Equal(log.Events, expected) {
+		t.Errorf("Expected events % X, got % X", expected, log.Events)
+	}
+
+	// Outside listen only mode the restart is echoed; 0xFF00 clears the log
+	restart = BuildDiagnosticsPDU(DiagRestartCommunications, []byte{0xFF, 0x00})
+	if resp := request(restart); resp == nil || !bytes.Equal(resp.PDU, restart) {
+		t.Errorf("Expected restart echo, got %v", resp)
+	}
+	log, _ = ParseGetCommEventLogResponse(request(BuildGetCommEventLogPDU()).PDU)
+	if !bytes.Equal(log.Events, []byte{EventReceive, EventSend, EventCommRestart}) {
+		t.Errorf("Expected cleared log, got % X", log.Events)
+	}
+
+	if resp := request(BuildDiagnosticsPDU(DiagRestartCommunications, []byte{0x12, 0x34})); resp == nil || !bytes.Equal(resp.PDU, []byte{0x88, 0x03}) {
+		t.Errorf("Expected illegal data value, got %v", resp)
+	}
+}
+
+func TestServerCommEventLog(t *testing.T) {
+	server := NewServer(NewMemoryHandler(65536, 65536))
+	request := func(unitID UnitID, pdu []byte) []byte {
+		return server.processRequest(context.Background(), &Frame{Header: MBAPHeader{UnitID: unitID}, PDU: pdu}, nil).PDU
+	}
+
+	read, _ := BuildReadHoldingRegistersPDU(0, 1)
+	request(1, read)
+	request(1, []byte{byte(FuncReadHoldingRegisters), 0x00, 0x00, 0x00, 0x00}) // illegal data value
+	request(1, []byte{0x41})                                                   // illegal function
+
+	// Exceptions and the counter request itself do not count as events
+	resp := request(1, BuildGetCommEventCounterPDU())
+	if !bytes.Equal(resp, []byte{0x0B, 0x00, 0x00, 0x00, 0x01}) {
+		t.Errorf("Expected status 0 and one event, got % X", resp)
+	}
+
+	log, err := ParseGetCommEventLogResponse(request(1, BuildGetCommEventLogPDU()))
+	if err != nil {
+		t.Fatalf("ParseGetCommEventLogResponse failed: %v", err)
+	}
+	if log.EventCount != 1 || log.MessageCount != 5 {
+		t.Errorf("Expected 1 event and 5 messages, got %d and %d", log.EventCount, log.MessageCount)
+	}
+	expected := []byte{
+		EventReceive,            // FC12
+		EventSend, EventReceive, // FC11
+		EventSend | EventSendReadException, EventReceive, // illegal function
+		EventSend | EventSendReadException, EventReceive, // illegal data value
+		EventSend, EventReceive, // read
+	}
+	if !bytes.Equal(log.Events, expected) {
+		t.Errorf("Expected events % X, got % X", expected, log.Events)
+	}
+
+	// Units are tracked separately
+	if resp := request(2, BuildGetCommEventCounterPDU()); !bytes.Equal(resp, []byte{0x0B, 0x00, 0x00, 0x00, 0x00}) {
+		t.Errorf("Expected no events for unit 2, got % X", resp)
+	}
+
+	// The log keeps the most recent events only
+	for i := 0; i < MaxCommEvents; i++ {
+		request(1, read)
+	}
+	log, _ = ParseGetCommEventLogResponse(request(1, BuildGetCommEventLogPDU()))
+	if len(log.Events) != MaxCommEvents || log.EventCount != 1+MaxCommEvents {
+		t.Errorf("Expected %d events logged and %d counted, got %d and %d",
+			MaxCommEvents, 1+MaxCommEvents, len(log.Events), log.EventCount)
+	}
+}
+
+func TestMemoryHandler_FIFOQueue(t *testing.T) {
+	handler := NewMemoryHandler(65536, 65536)
+
+	if _, err := handler.ReadFIFOQueue(1, 100); !IsIllegalDataAddress(err) {
+		t.Errorf("Expected illegal data address for non-FIFO address, got %v", err)
+	}
+
+	for i := uint16(0); i < MaxFIFOCount+2; i++ {
+		handler.PushFIFOQueue(1, 100, i)
+	}
+	values, err := handler.ReadFIFOQueue(1, 100)
+	if err != nil {
+		t.Fatalf("ReadFIFOQueue failed: %v", err)
+	}
+	if len(values) != MaxFIFOCount || values[0] != 2 || values[MaxFIFOCount-1] != MaxFIFOCount+1 {
+		t.Errorf("Expected the %d newest values, got %v", MaxFIFOCount, values)
+	}
+
+	// Reading does not clear the queue
+	if again, _ := handler.ReadFIFOQueue(1, 100); len(again) != MaxFIFOCount {
+		t.Errorf("Expected queue to keep %d values, got %d", MaxFIFOCount, len(again))
+	}
+
+	handler.SetFIFOQueue(1, 100, nil)
+	if _, err := handler.ReadFIFOQueue(1, 100); !IsIllegalDataAddress(err) {
+		t.Errorf("Expected queue to be removed, got %v", err)
+	}
+}
+
+func TestServerReadFIFOQueue(t *testing.T) {
+	handler := NewMemoryHandler(65536, 65536)
+	handler.SetFIFOQueue(1, 0x04DE, []uint16{0x01B8, 0x1284})
+	handler.SetFIFOQueue(1, 0x0500, make([]uint16, MaxFIFOCount+1))
+	server := NewServer(handler)
+
+	tests := []struct {
+		name     string
+		addr     uint16
+		expected []byte
+	}{
+		{"queue", 0x04DE, []byte{0x18, 0x00, 0x06, 0x00, 0x02, 0x01, 0xB8, 0x12, 0x84}},
+		{"too many values", 0x0500, []byte{0x98, byte(ExceptionIllegalDataValue)}},
+		{"not a queue", 0x0600, []byte{0x98, byte(ExceptionIllegalDataAddress)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := server.processRequest(context.Background(), &Frame{Header: MBAPHeader{UnitID: 1}, PDU: BuildReadFIFOQueuePDU(tt.addr)}, nil)
+			if !bytes.Equal(resp.PDU, tt.expected) {
+				t.Errorf("Expected % X, got % X", tt.expected, resp.PDU)
+			}
+		})
+	}
+
+	resp := NewServer(basicHandler{handler}).processRequest(context.Background(), &Frame{Header: MBAPHeader{UnitID: 1}, PDU: BuildReadFIFOQueuePDU(0x04DE)}, nil)
+	if !bytes.Equal(resp.PDU, []byte{0x98, byte(ExceptionIllegalFunction)}) {
+		t.Errorf("Expected illegal function exception, got % X", resp.PDU)
+	}
+}
+
+func TestServerFileRecord(t *testing.T) {
+	handler := NewMemoryHandler(65536, 65536)
+	server := NewServer(handler)
+
+	request := func(pdu []byte) []byte {
+		return server.processRequest(context.Background(), &Frame{Header: MBAPHeader{UnitID: 1}, PDU: pdu}, nil).PDU
+	}
+
+	write, _ := BuildWriteFileRecordPDU([]FileRecord{
+		{File: 4, Record: 7, Data: []uint16{0x06AF, 0x04BE, 0x100D}},
+		{File: 5, Record: 0, Data: []uint16{0x0001}},
+	})
+	if resp := request(write); !bytes.Equal(resp, write) {
+		t.Fatalf("Expected echo % X, got % X", write, resp)
+	}
+
+	read, _ := BuildReadFileRecordPDU([]FileSubRequest{{File: 4, Record: 8, Length: 2}, {File: 5, Record: 0, Length: 1}})
+	expected := []byte{0x14, 0x0A, 0x05, 0x06, 0x04, 0xBE, 0x10, 0x0D, 0x03, 0x06, 0x00, 0x01}
+	if resp := request(read); !bytes.Equal(resp, expected) {
+		t.Errorf("Expected % X, got % X", expected, resp)
+	}
+
+	// Records past the end of the file
+	read, _ = BuildReadFileRecordPDU([]FileSubRequest{{File: 4, Record: 9999, Length: 2}})
+	if resp := request(read); !bytes.Equal(resp, []byte{0x94, byte(ExceptionIllegalDataAddress)}) {
+		t.Errorf("Expected illegal data address exception, got % X", resp)
+	}
+
+	// Wrong reference type
+	bad := append([]byte(nil), write...)
+	bad[2] = 0x05
+	if resp := request(bad); !bytes.Equal(resp, []byte{0x95, byte(ExceptionIllegalDataAddress)}) {
+		t.Errorf("Expected illegal data address exception, got % X", resp)
+	}
+
+	// Byte count that does not cover the record data
+	bad = append([]byte(nil), write[:len(write)-2]...)
+	bad[1] -= 2
+	bad[9] = 0xFF
+	if resp := request(bad); !bytes.Equal(resp, []byte{0x95, byte(ExceptionIllegalDataValue)}) {
+		t.Errorf("Expected illegal data value exception, got % X", resp)
+	}
+	regs, _ := handler.ReadFileRecord(1, 4, 7, 1)
+	if regs[0] != 0x06AF {
+		t.Errorf("Rejected request must not write, got 0x%04X", regs[0])
+	}
+
+	resp := NewServer(basicHandler{handler}).processRequest(context.Background(), &Frame{Header: MBAPHeader{UnitID: 1}, PDU: read}, nil)
+	if !bytes.Equal(resp.PDU, []byte{0x94, byte(ExceptionIllegalFunction)}) {
+		t.Errorf("Expected illegal function exception, got % X", resp.PDU)
+	}
+}
+
+func TestServerReadDeviceIdentification(t *testing.T) {
+	handler := NewMemoryHandler(65536, 65536)
+	handler.SetDeviceIdentification(DeviceIDVendorName, []byte("ACME"))
+	handler.SetDeviceIdentification(DeviceIDProductCode, []byte("X1"))
+	handler.SetDeviceIdentification(DeviceIDMajorMinorRevision, []byte("2.1"))
+	handler.SetDeviceIdentification(DeviceIDModelName, []byte("Model"))
+	server := NewServer(handler)
+
+	request := func(h Handler, pdu []byte) []byte {
+		return NewServer(h).processRequest(context.Background(), &Frame{Header: MBAPHeader{UnitID: 1}, PDU: pdu}, nil).PDU
+	}
+
+	tests := []struct {
+		name     string
+		pdu      []byte
+		expected []byte
+	}{
+		{"basic", BuildReadDeviceIdentificationPDU(ReadDeviceIDBasic, 0), []byte{
+			0x2B, 0x0E, 0x01, 0x82, 0x00, 0x00, 0x03,
+			0x00, 0x04, 'A', 'C', 'M', 'E', 0x01, 0x02, 'X', '1', 0x02, 0x03, '2', '.', '1'}},
+		{"regular from object", BuildReadDeviceIdentificationPDU(ReadDeviceIDRegular, DeviceIDMajorMinorRevision), []byte{
+			0x2B, 0x0E, 0x02, 0x82, 0x00, 0x00, 0x02,
+			0x02, 0x03, '2', '.', '1', 0x05, 0x05, 'M', 'o', 'd', 'e', 'l'}},
+		{"unknown start restarts", BuildReadDeviceIdentificationPDU(ReadDeviceIDBasic, DeviceIDModelName), []byte{
+			0x2B, 0x0E, 0x01, 0x82, 0x00, 0x00, 0x03,
+			0x00, 0x04, 'A', 'C', 'M', 'E', 0x01, 0x02, 'X', '1', 0x02, 0x03, '2', '.', '1'}},
+		{"individual", BuildReadDeviceIdentificationPDU(ReadDeviceIDIndividual, DeviceIDModelName), []byte{
+			0x2B, 0x0E, 0x04, 0x82, 0x00, 0x00, 0x01, 0x05, 0x05, 'M', 'o', 'd', 'e', 'l'}},
+		{"individual unknown", BuildReadDeviceIdentificationPDU(ReadDeviceIDIndividual, DeviceIDVendorURL),
+			[]byte{0xAB, byte(ExceptionIllegalDataAddress)}},
+		{"invalid read code", BuildReadDeviceIdentificationPDU(0x05, 0), []byte{0xAB, byte(ExceptionIllegalDataValue)}},
+		{"other MEI type", []byte{0x2B, 0x0D, 0x00}, []byte{0xAB, byte(ExceptionIllegalFunction)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := server.processRequest(context.Background(), &Frame{Header: MBAPHeader{UnitID: 1}, PDU: tt.pdu}, nil)
+			if !bytes.Equal(resp.PDU, tt.expected) {
+				t.Errorf("Expected % X, got % X", tt.expected, resp.PDU)
+			}
+		})
+	}
+
+	t.Run("paging", func(t *testing.T) {
+		for id := uint8(0x80); id < 0x83; id++ {
+			handler.SetDeviceIdentification(id, bytes.Repeat([]byte{id}, 100))
+		}
+		resp, err := ParseReadDeviceIdentificationResponse(request(handler, BuildReadDeviceIdentificationPDU(ReadDeviceIDExtended, 0)))
+		if err != nil {
+			t.Fatalf("ParseReadDeviceIdentificationResponse failed: %v", err)
+		}
+		if !resp.MoreFollows || resp.NextObjectID != 0x82 || len(resp.Objects) != 6 {
+			t.Errorf("Expected 6 objects and more from 0x82, got %d objects, more=%v, next=0x%02X",
+				len(resp.Objects), resp.MoreFollows, resp.NextObjectID)
+		}
+		if resp.ConformityLevel != 0x83 {
+			t.Errorf("ConformityLevel: expected 0x83, got 0x%02X", resp.ConformityLevel)
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		resp := request(basicHandler{handler}, BuildReadDeviceIdentificationPDU(ReadDeviceIDBasic, 0))
+		if !bytes.Equal(resp, []byte{0xAB, byte(ExceptionIllegalFunction)}) {
+			t.Errorf("Expected illegal function exception, got % X", resp)
+		}
+	})
+}
+
 func TestMemoryHandler_MultipleUnits(t *testing.T) {
 	handler := NewMemoryHandler(65536, 65536)
 
@@ -251,3 +815,181 @@ func TestServerAddr(t *testing.T) {
 		t.Errorf("Addr mismatch: expected %s, got %s", expectedAddr, addr)
 	}
 }
+
+func TestServerUDP(t *testing.T) {
+	handler := NewMemoryHandler(65536, 65536)
+	handler.SetHoldingRegister(1, 0, 1111)
+	server := NewServer(handler)
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket failed: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- server.ServeUDP(conn) }()
+
+	client, err := NewUDPClient(conn.LocalAddr().String(), WithTimeout(2*time.Second))
+	if err != nil {
+		t.Fatalf("NewUDPClient failed: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	regs, err := client.ReadHoldingRegisters(ctx, 0, 1)
+	if err != nil {
+		t.Fatalf("ReadHoldingRegisters failed: %v", err)
+	}
+	if regs[0] != 1111 {
+		t.Errorf("Expected 1111, got %d", regs[0])
+	}
+
+	if err := client.WriteMultipleRegisters(ctx, 10, []uint16{1, 2, 3}); err != nil {
+		t.Fatalf("WriteMultipleRegisters failed: %v", err)
+	}
+
+	_, err = client.Diagnostics(ctx, 0x99, nil)
+	if !IsIllegalFunction(err) {
+		t.Errorf("Expected illegal function, got %v", err)
+	}
+
+	if total := server.Metrics().RequestsTotal.Value(); total != 3 {
+		t.Errorf("Expected 3 requests, got %d", total)
+	}
+
+	server.Close()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("ServeUDP returned %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("ServeUDP did not return after Close")
+	}
+}
+
+func TestServerMiddleware(t *testing.T) {
+	handler := NewMemoryHandler(65536, 65536)
+	handler.SetHoldingRegister(1, 0, 42)
+	server := NewServer(handler)
+
+	var mu sync.Mutex
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next RequestHandler) RequestHandler {
+			return func(ctx context.Context, req *ServerRequest) ([]byte, error) {
+				mu.Lock()
+				calls = append(calls, name)
+				mu.Unlock()
+				return next(ctx, req)
+			}
+		}
+	}
+	readOnly := func(next RequestHandler) RequestHandler {
+		return func(ctx context.Context, req *ServerRequest) ([]byte, error) {
+			if req.FunctionCode() == FuncWriteSingleRegister {
+				return nil, fmt.Errorf("read-only unit %d: %w", req.UnitID, NewModbusError(req.FunctionCode(), ExceptionIllegalFunction))
+			}
+			return next(ctx, req)
+		}
+	}
+	dropUnit2 := func(next RequestHandler) RequestHandler {
+		return func(ctx context.Context, req *ServerRequest) ([]byte, error) {
+			if req.UnitID == 2 {
+				return nil, nil
+			}
+			return next(ctx, req)
+		}
+	}
+	server.Use(record("outer"), readOnly)
+	server.Use(dropUnit2, record("inner"))
+	server.HandleFunc(100, func(unitID UnitID, pdu []byte) ([]byte, error) {
+		return []byte{100, 1}, nil
+	})
+
+	process := func(unitID UnitID, pdu []byte) *Frame {
+		return server.processRequest(context.Background(), &Frame{Header: MBAPHeader{UnitID: unitID}, PDU: pdu}, nil)
+	}
+
+	read, _ := BuildReadHoldingRegistersPDU(0, 1)
+	if resp := process(1, read); !bytes.Equal(resp.PDU, []byte{0x03, 0x02, 0x00, 42}) {
+		t.Errorf("Unexpected response % X", resp.PDU)
+	}
+	if fmt.Sprint(calls) != "[outer inner]" {
+		t.Errorf("Expected the middleware in order, got %v", calls)
+	}
+
+	// A wrapped *ModbusError is answered with its exception
+	write := BuildWriteSingleRegisterPDU(0, 7)
+	if resp := process(1, write); !bytes.Equal(resp.PDU, []byte{0x86, byte(ExceptionIllegalFunction)}) {
+		t.Errorf("Expected an illegal function exception, got % X", resp.PDU)
+	}
+	if regs, _ := handler.ReadHoldingRegisters(1, 0, 1); regs[0] != 42 {
+		t.Errorf("Expected the write to be rejected, got %d", regs[0])
+	}
+
+	if resp := process(2, read); resp != nil {
+		t.Errorf("Expected no response, got % X", resp.PDU)
+	}
+	if resp := process(1, []byte{100}); !bytes.Equal(resp.PDU, []byte{100, 1}) {
+		t.Errorf("Expected the registered function through the middleware, got % X", resp.PDU)
+	}
+}
+
+func TestServerMiddlewareTCP(t *testing.T) {
+	server := NewServer(NewMemoryHandler(65536, 65536))
+	requests := make(chan *ServerRequest, 1)
+	done := make(chan error, 1)
+	server.Use(func(next RequestHandler) RequestHandler {
+		return func(ctx context.Context, req *ServerRequest) ([]byte, error) {
+			requests <- req
+			go func() {
+				<-ctx.Done()
+				done <- ctx.Err()
+			}()
+			return next(ctx, req)
+		}
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	go server.Serve(listener)
+	defer server.Close()
+
+	client, err := NewClient(listener.Addr().String(), WithUnitID(5))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	ctx := context.Background()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	if _, err := client.ReadCoils(ctx, 0, 8); err != nil {
+		t.Fatalf("ReadCoils failed: %v", err)
+	}
+
+	req := <-requests
+	if req.UnitID != 5 || req.FunctionCode() != FuncReadCoils || req.RemoteAddr == nil {
+		t.Errorf("Unexpected request %+v", req)
+	}
+	if addr, ok := req.RemoteAddr.(*net.TCPAddr); !ok || !addr.IP.IsLoopback() {
+		t.Errorf("Unexpected remote address %v", req.RemoteAddr)
+	}
+
+	// The context of a request ends with its connection
+	client.Close()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("Request context not cancelled when the connection closed")
+	}
+}
@@ -0,0 +1,381 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sunspec
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/edgeo-scada/modbus"
+)
+
+// PointType is the SunSpec type of a point.
+type PointType int
+
+const (
+	TypeUint16 PointType = iota
+	TypeInt16
+	TypeUint32
+	TypeInt32
+	TypeAcc32
+	TypeEnum16
+	TypeBitfield16
+	TypeBitfield32
+	// TypeSunSSF is a scale factor: the power of ten applied to the points
+	// that refer to it.
+	TypeSunSSF
+	TypeString
+	TypePad
+)
+
+// String returns a string representation of PointType.
+func (t PointType) String() string {
+	switch t {
+	case TypeUint16:
+		return "uint16"
+	case TypeInt16:
+		return "int16"
+	case TypeUint32:
+		return "uint32"
+	case TypeInt32:
+		return "int32"
+	case TypeAcc32:
+		return "acc32"
+	case TypeEnum16:
+		return "enum16"
+	case TypeBitfield16:
+		return "bitfield16"
+	case TypeBitfield32:
+		return "bitfield32"
+	case TypeSunSSF:
+		return "sunssf"
+	case TypeString:
+		return "string"
+	case TypePad:
+		return "pad"
+	default:
+		return "unknown"
+	}
+}
+
+// PointDef describes a point of a model.
+type PointDef struct {
+	Name   string
+	Offset uint16 // from the first register after the model length
+	Type   PointType
+	Size   uint16 // number of registers
+	SF     string // name of the scale factor point, if any
+	Units  string
+}
+
+// ModelDef describes a model: its fixed block and, for models such as 160,
+// the repeating block that follows it.
+type ModelDef struct {
+	ID     uint16
+	Name   string
+	Points []PointDef
+
+	// Repeat describes the repeating block, of RepeatLength registers,
+	// following the FixedLength registers of the fixed block.
+	FixedLength  uint16
+	RepeatLength uint16
+	Repeat       []PointDef
+}
+
+// pointList builds the points of a block at consecutive offsets.
+type pointList struct {
+	points []PointDef
+	offset uint16
+}
+
+func (l *pointList) add(name string, typ PointType, sf, units string) *pointList {
+	size := uint16(1)
+	switch typ {
+	case TypeUint32, TypeInt32, TypeAcc32, TypeBitfield32:
+		size = 2
+	}
+	l.points = append(l.points, PointDef{Name: name, Offset: l.offset, Type: typ, Size: size, SF: sf, Units: units})
+	l.offset += size
+	return l
+}
+
+func (l *pointList) addString(name string, size uint16) *pointList {
+	l.points = append(l.points, PointDef{Name: name, Offset: l.offset, Type: TypeString, Size: size})
+	l.offset += size
+	return l
+}
+
+// phases adds a total point followed by its phase A, B and C points.
+func (l *pointList) phases(name, suffix string, typ PointType, sf, units string) *pointList {
+	l.add(name, typ, sf, units)
+	for _, ph := range []string{"A", "B", "C"} {
+		l.add(name+suffix+ph, typ, sf, units)
+	}
+	return l
+}
+
+func commonModel() *ModelDef {
+	l := &pointList{}
+	l.addString("Mn", 16).addString("Md", 16).addString("Opt", 8).
+		addString("Vr", 8).addString("SN", 16).
+		add("DA", TypeUint16, "", "").add("Pad", TypePad, "", "")
+	return &ModelDef{ID: 1, Name: "Common", Points: l.points}
+}
+
+func inverterModel(id uint16, name string) *ModelDef {
+	l := &pointList{}
+	l.phases("A", "ph", TypeUint16, "A_SF", "A").add("A_SF", TypeSunSSF, "", "")
+	for _, p := range []string{"PPVphAB", "PPVphBC", "PPVphCA", "PhVphA", "PhVphB", "PhVphC"} {
+		l.add(p, TypeUint16, "V_SF", "V")
+	}
+	l.add("V_SF", TypeSunSSF, "", "").
+		add("W", TypeInt16, "W_SF", "W").add("W_SF", TypeSunSSF, "", "").
+		add("Hz", TypeUint16, "Hz_SF", "Hz").add("Hz_SF", TypeSunSSF, "", "").
+		add("VA", TypeInt16, "VA_SF", "VA").add("VA_SF", TypeSunSSF, "", "").
+		add("VAr", TypeInt16, "VAr_SF", "var").add("VAr_SF", TypeSunSSF, "", "").
+		add("PF", TypeInt16, "PF_SF", "Pct").add("PF_SF", TypeSunSSF, "", "").
+		add("WH", TypeAcc32, "WH_SF", "Wh").add("WH_SF", TypeSunSSF, "", "").
+		add("DCA", TypeUint16, "DCA_SF", "A").add("DCA_SF", TypeSunSSF, "", "").
+		add("DCV", TypeUint16, "DCV_SF", "V").add("DCV_SF", TypeSunSSF, "", "").
+		add("DCW", TypeInt16, "DCW_SF", "W").add("DCW_SF", TypeSunSSF, "", "")
+	for _, p := range []string{"TmpCab", "TmpSnk", "TmpTrns", "TmpOt"} {
+		l.add(p, TypeInt16, "Tmp_SF", "C")
+	}
+	l.add("Tmp_SF", TypeSunSSF, "", "").
+		add("St", TypeEnum16, "", "").add("StVnd", TypeEnum16, "", "")
+	for _, p := range []string{"Evt1", "Evt2", "EvtVnd1", "EvtVnd2", "EvtVnd3", "EvtVnd4"} {
+		l.add(p, TypeBitfield32, "", "")
+	}
+	return &ModelDef{ID: id, Name: name, Points: l.points}
+}
+
+func mpptModel() *ModelDef {
+	l := &pointList{}
+	for _, p := range []string{"DCA_SF", "DCV_SF", "DCW_SF", "DCWH_SF"} {
+		l.add(p, TypeSunSSF, "", "")
+	}
+	l.add("Evt", TypeBitfield32, "", "").
+		add("N", TypeUint16, "", "").add("TmsPer", TypeUint16, "", "")
+
+	r := &pointList{}
+	r.add("ID", TypeUint16, "", "").addString("IDStr", 8).
+		add("DCA", TypeUint16, "DCA_SF", "A").
+		add("DCV", TypeUint16, "DCV_SF", "V").
+		add("DCW", TypeUint16, "DCW_SF", "W").
+		add("DCWH", TypeAcc32, "DCWH_SF", "Wh").
+		add("Tms", TypeUint32, "", "Secs").
+		add("Tmp", TypeInt16, "", "C").
+		add("DCSt", TypeEnum16, "", "").
+		add("DCEvt", TypeBitfield32, "", "")
+
+	return &ModelDef{
+		ID:           160,
+		Name:         "Multiple MPPT Inverter Extension",
+		Points:       l.points,
+		FixedLength:  l.offset,
+		RepeatLength: r.offset,
+		Repeat:       r.points,
+	}
+}
+
+func meterModel(id uint16, name string) *ModelDef {
+	l := &pointList{}
+	l.phases("A", "ph", TypeInt16, "A_SF", "A").add("A_SF", TypeSunSSF, "", "").
+		phases("PhV", "ph", TypeInt16, "V_SF", "V")
+	for _, p := range []string{"PPV", "PPVphAB", "PPVphBC", "PPVphCA"} {
+		l.add(p, TypeInt16, "V_SF", "V")
+	}
+	l.add("V_SF", TypeSunSSF, "", "").
+		add("Hz", TypeInt16, "Hz_SF", "Hz").add("Hz_SF", TypeSunSSF, "", "").
+		phases("W", "ph", TypeInt16, "W_SF", "W").add("W_SF", TypeSunSSF, "", "").
+		phases("VA", "ph", TypeInt16, "VA_SF", "VA").add("VA_SF", TypeSunSSF, "", "").
+		phases("VAR", "ph", TypeInt16, "VAR_SF", "var").add("VAR_SF", TypeSunSSF, "", "").
+		phases("PF", "ph", TypeInt16, "PF_SF", "Pct").add("PF_SF", TypeSunSSF, "", "").
+		phases("TotWhExp", "Ph", TypeAcc32, "TotWh_SF", "Wh").
+		phases("TotWhImp", "Ph", TypeAcc32, "TotWh_SF", "Wh").add("TotWh_SF", TypeSunSSF, "", "").
+		phases("TotVAhExp", "Ph", TypeAcc32, "TotVAh_SF", "VAh").
+		phases("TotVAhImp", "Ph", TypeAcc32, "TotVAh_SF", "VAh").add("TotVAh_SF", TypeSunSSF, "", "")
+	for _, p := range []string{"TotVArhImpQ1", "TotVArhImpQ2", "TotVArhExpQ3", "TotVArhExpQ4"} {
+		l.phases(p, "Ph", TypeAcc32, "TotVArh_SF", "varh")
+	}
+	l.add("TotVArh_SF", TypeSunSSF, "", "").add("Evt", TypeBitfield32, "", "")
+	return &ModelDef{ID: id, Name: name, Points: l.points}
+}
+
+// modelDefs holds the definitions of the supported models.
+var modelDefs = map[uint16]*ModelDef{}
+
+func init() {
+	for _, m := range []*ModelDef{
+		commonModel(),
+		inverterModel(101, "Inverter (Single Phase)"),
+		inverterModel(102, "Inverter (Split Phase)"),
+		inverterModel(103, "Inverter (Three Phase)"),
+		mpptModel(),
+		meterModel(201, "Meter (Single Phase)"),
+		meterModel(202, "Meter (Split Single Phase)"),
+		meterModel(203, "Meter (Wye Three Phase)"),
+		meterModel(204, "Meter (Delta Three Phase)"),
+	} {
+		modelDefs[m.ID] = m
+	}
+}
+
+// Lookup returns the definition of a model.
+func Lookup(id uint16) (*ModelDef, bool) {
+	def, ok := modelDefs[id]
+	return def, ok
+}
+
+// Value is a decoded point.
+type Value struct {
+	Name string
+	Type PointType
+
+	// Value is a float64 for scaled points, a string for strings and the
+	// Go type of the same size otherwise (uint32 for acc32 and bitfield32,
+	// uint16 for enum16 and bitfield16). It is nil when the device does not
+	// implement the point or its scale factor.
+	Value any
+	Units string
+}
+
+// Decoded is a decoded model.
+type Decoded struct {
+	ID     uint16
+	Name   string
+	Points []Value   // points of the fixed block, without scale factors
+	Blocks [][]Value // repeating blocks
+}
+
+// Point returns the point with the given name of the fixed block, or nil.
+func (d *Decoded) Point(name string) *Value {
+	for i := range d.Points {
+		if d.Points[i].Name == name {
+			return &d.Points[i]
+		}
+	}
+	return nil
+}
+
+// Decode decodes the points of a model with a definition. Points beyond
+// the length of the model are omitted.
+func (m *Model) Decode() (*Decoded, error) {
+	def, ok := Lookup(m.ID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownModel, m.ID)
+	}
+	if len(m.Registers) != int(m.Length) {
+		return nil, fmt.Errorf("%w: model %d has %d registers, expected %d", ErrInvalidModel, m.ID, len(m.Registers), m.Length)
+	}
+
+	fixed := m.Registers
+	if def.RepeatLength > 0 {
+		if len(fixed) < int(def.FixedLength) || (len(fixed)-int(def.FixedLength))%int(def.RepeatLength) != 0 {
+			return nil, fmt.Errorf("%w: model %d length %d does not match its blocks", ErrInvalidModel, m.ID, m.Length)
+		}
+		fixed = fixed[:def.FixedLength]
+	}
+
+	scales := scaleFactors(def.Points, fixed)
+	d := &Decoded{ID: m.ID, Name: def.Name, Points: decodeBlock(def.Points, fixed, scales)}
+	for off := int(def.FixedLength); def.RepeatLength > 0 && off < len(m.Registers); off += int(def.RepeatLength) {
+		block := m.Registers[off : off+int(def.RepeatLength)]
+		d.Blocks = append(d.Blocks, decodeBlock(def.Repeat, block, scales))
+	}
+	return d, nil
+}
+
+// scaleFactors returns the implemented scale factors of a block by name.
+func scaleFactors(points []PointDef, regs []uint16) map[string]int16 {
+	scales := make(map[string]int16)
+	for _, p := range points {
+		if p.Type == TypeSunSSF && int(p.Offset) < len(regs) && regs[p.Offset] != 0x8000 {
+			scales[p.Name] = int16(regs[p.Offset])
+		}
+	}
+	return scales
+}
+
+// decodeBlock decodes the points of a block.
+func decodeBlock(points []PointDef, regs []uint16, scales map[string]int16) []Value {
+	var values []Value
+	for _, p := range points {
+		if p.Type == TypeSunSSF || p.Type == TypePad || int(p.Offset+p.Size) > len(regs) {
+			continue
+		}
+		v := Value{Name: p.Name, Type: p.Type, Units: p.Units}
+		v.Value = decodePoint(p, regs[p.Offset:p.Offset+p.Size])
+		if p.SF != "" && v.Value != nil {
+			if sf, ok := scales[p.SF]; ok {
+				v.Value = scale(v.Value, sf)
+			} else {
+				v.Value = nil
+			}
+		}
+		values = append(values, v)
+	}
+	return values
+}
+
+// decodePoint returns the value of a point, or nil if it is not
+// implemented.
+func decodePoint(p PointDef, regs []uint16) any {
+	switch p.Type {
+	case TypeUint16, TypeEnum16, TypeBitfield16:
+		if regs[0] != 0xFFFF {
+			return regs[0]
+		}
+	case TypeInt16:
+		if regs[0] != 0x8000 {
+			return int16(regs[0])
+		}
+	case TypeUint32, TypeBitfield32:
+		if v := modbus.OrderABCD.Uint32(regs); v != 0xFFFFFFFF {
+			return v
+		}
+	case TypeAcc32:
+		if v := modbus.OrderABCD.Uint32(regs); v != 0 {
+			return v
+		}
+	case TypeInt32:
+		if v := modbus.OrderABCD.Uint32(regs); v != 0x80000000 {
+			return int32(v)
+		}
+	case TypeString:
+		return strings.TrimRight(string(modbus.OrderABCD.Bytes(regs)), "\x00")
+	}
+	return nil
+}
+
+// scale applies a scale factor to an integer value.
+func scale(value any, sf int16) float64 {
+	var f float64
+	switch v := value.(type) {
+	case uint16:
+		f = float64(v)
+	case int16:
+		f = float64(v)
+	case uint32:
+		f = float64(v)
+	case int32:
+		f = float64(v)
+	}
+	if sf < 0 {
+		return f / math.Pow10(-int(sf))
+	}
+	return f * math.Pow10(int(sf))
+}
@@ -0,0 +1,167 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sunspec discovers and decodes the SunSpec information models that
+// solar inverters, meters and other devices expose in Modbus holding
+// registers: a "SunS" marker at a base address followed by a chain of
+// blocks, each starting with a model ID and the length of its body.
+package sunspec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/edgeo-scada/modbus"
+)
+
+// Marker holds the "SunS" identifier stored in the two registers at the
+// base address.
+var Marker = [2]uint16{0x5375, 0x6E53}
+
+// EndModelID is the ID of the block that ends the model chain.
+const EndModelID = 0xFFFF
+
+// DefaultBaseAddresses are the base addresses searched for the marker, in
+// order.
+var DefaultBaseAddresses = []uint16{40000, 0, 50000}
+
+var (
+	// ErrNotFound indicates that no SunSpec marker was found.
+	ErrNotFound = errors.New("sunspec: marker not found")
+
+	// ErrInvalidModel indicates a malformed model chain or model.
+	ErrInvalidModel = errors.New("sunspec: invalid model")
+
+	// ErrUnknownModel indicates a model without definition.
+	ErrUnknownModel = errors.New("sunspec: unknown model")
+)
+
+// Reader reads holding registers; *modbus.Client implements it.
+type Reader interface {
+	ReadHoldingRegisters(ctx context.Context, addr, qty uint16) ([]uint16, error)
+}
+
+// Option is a functional option for Discover.
+type Option func(*options)
+
+type options struct {
+	bases []uint16
+}
+
+// WithBaseAddresses sets the base addresses searched for the marker.
+func WithBaseAddresses(addrs ...uint16) Option {
+	return func(o *options) {
+		o.bases = addrs
+	}
+}
+
+// Model is a block of the model chain.
+type Model struct {
+	ID        uint16
+	Address   uint16   // address of the model ID register
+	Length    uint16   // number of registers after the model length
+	Registers []uint16 // the Length registers of the model
+}
+
+// Device is the model chain of a SunSpec device.
+type Device struct {
+	Base   uint16 // address of the marker
+	Models []Model
+}
+
+// Model returns the first model with the given ID, or nil.
+func (d *Device) Model(id uint16) *Model {
+	for i := range d.Models {
+		if d.Models[i].ID == id {
+			return &d.Models[i]
+		}
+	}
+	return nil
+}
+
+// Discover searches the base addresses for the SunSpec marker and reads
+// the model chain that follows it. If reading the chain fails, the models
+// read so far are returned with the error.
+func Discover(ctx context.Context, r Reader, opts ...Option) (*Device, error) {
+	o := &options{bases: DefaultBaseAddresses}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	var lastErr error
+	for _, base := range o.bases {
+		regs, err := r.ReadHoldingRegisters(ctx, base, 2)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			lastErr = err
+			continue
+		}
+		if len(regs) == 2 && regs[0] == Marker[0] && regs[1] == Marker[1] {
+			return readChain(ctx, r, base)
+		}
+	}
+	if lastErr != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotFound, lastErr)
+	}
+	return nil, ErrNotFound
+}
+
+// readChain reads the models following the marker at base.
+func readChain(ctx context.Context, r Reader, base uint16) (*Device, error) {
+	dev := &Device{Base: base}
+	addr := int(base) + 2
+	for addr+2 <= 65536 {
+		header, err := readRegisters(ctx, r, addr, 2)
+		if err != nil {
+			return dev, err
+		}
+		id, length := header[0], header[1]
+		if id == EndModelID {
+			return dev, nil
+		}
+
+		start := addr + 2
+		if start+int(length) > 65536 {
+			return dev, fmt.Errorf("%w: model %d at %d exceeds the address space", ErrInvalidModel, id, addr)
+		}
+		regs, err := readRegisters(ctx, r, start, int(length))
+		if err != nil {
+			return dev, err
+		}
+		dev.Models = append(dev.Models, Model{ID: id, Address: uint16(addr), Length: length, Registers: regs})
+		addr = start + int(length)
+	}
+	return dev, fmt.Errorf("%w: model chain has no end", ErrInvalidModel)
+}
+
+// readRegisters reads count registers at addr, in as many requests as
+// needed.
+func readRegisters(ctx context.Context, r Reader, addr, count int) ([]uint16, error) {
+	regs := make([]uint16, 0, count)
+	for len(regs) < count {
+		qty := min(count-len(regs), modbus.MaxQuantityRegisters)
+		values, err := r.ReadHoldingRegisters(ctx, uint16(addr+len(regs)), uint16(qty))
+		if err != nil {
+			return nil, err
+		}
+		if len(values) != qty {
+			return nil, fmt.Errorf("%w: expected %d registers, got %d", modbus.ErrInvalidResponse, qty, len(values))
+		}
+		regs = append(regs, values...)
+	}
+	return regs, nil
+}
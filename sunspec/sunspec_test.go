@@ -0,0 +1,207 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sunspec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/edgeo-scada/modbus"
+)
+
+// registerMap is a Reader over an in-memory register map that raises an
+// illegal data address exception outside of its defined registers.
+type registerMap struct {
+	regs   map[int]uint16
+	maxQty uint16
+}
+
+func (m *registerMap) ReadHoldingRegisters(ctx context.Context, addr, qty uint16) ([]uint16, error) {
+	m.maxQty = max(m.maxQty, qty)
+	values := make([]uint16, qty)
+	for i := range values {
+		v, ok := m.regs[int(addr)+i]
+		if !ok {
+			return nil, &modbus.ModbusError{FunctionCode: modbus.FuncReadHoldingRegisters, ExceptionCode: modbus.ExceptionIllegalDataAddress}
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// set stores values from addr.
+func (m *registerMap) set(addr int, values ...uint16) int {
+	for i, v := range values {
+		m.regs[addr+i] = v
+	}
+	return addr + len(values)
+}
+
+// model stores a model of the given length with values set at offsets,
+// other registers being not implemented.
+func (m *registerMap) model(addr int, id, length uint16, values map[uint16]uint16) int {
+	def, _ := Lookup(id)
+	regs := make([]uint16, length)
+	fill := func(points []PointDef, block uint16) {
+		for _, p := range points {
+			for i := block + p.Offset; i < block+p.Offset+p.Size && i < length; i++ {
+				switch p.Type {
+				case TypeInt16, TypeSunSSF:
+					regs[i] = 0x8000
+				case TypeUint16, TypeEnum16, TypeBitfield16, TypeUint32, TypeBitfield32:
+					regs[i] = 0xFFFF
+				}
+			}
+		}
+	}
+	if def != nil {
+		fill(def.Points, 0)
+		for block := def.FixedLength; def.RepeatLength > 0 && block < length; block += def.RepeatLength {
+			fill(def.Repeat, block)
+		}
+	}
+	for off, v := range values {
+		regs[off] = v
+	}
+	return m.set(addr, append([]uint16{id, length}, regs...)...)
+}
+
+func TestModelLengths(t *testing.T) {
+	tests := []struct {
+		id     uint16
+		length uint16
+	}{
+		{1, 66}, {101, 50}, {102, 50}, {103, 50}, {201, 105}, {202, 105}, {203, 105}, {204, 105},
+	}
+	for _, tt := range tests {
+		def, ok := Lookup(tt.id)
+		if !ok {
+			t.Fatalf("Model %d not defined", tt.id)
+		}
+		last := def.Points[len(def.Points)-1]
+		if last.Offset+last.Size != tt.length {
+			t.Errorf("Model %d: expected length %d, got %d", tt.id, tt.length, last.Offset+last.Size)
+		}
+	}
+
+	def, _ := Lookup(160)
+	if def.FixedLength != 8 || def.RepeatLength != 20 {
+		t.Errorf("Model 160: expected 8+20 registers, got %d+%d", def.FixedLength, def.RepeatLength)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	m := &registerMap{regs: make(map[int]uint16)}
+	addr := m.set(40000, Marker[0], Marker[1])
+
+	common := map[uint16]uint16{0: 0x4163, 1: 0x6D65, 16: 0x5831, 48: 0x3432, 64: 1}
+	addr = m.model(addr, 1, 66, common)
+	inverter := map[uint16]uint16{
+		0: 1234, 4: 0xFFFE, // A = 12.34 A
+		8: 2305, 11: 0xFFFF, // PhVphA = 230.5 V
+		12: 2800, 13: 0, // W = 2800 W
+		22: 0x0001, 23: 0x0000, 24: 1, // WH = 655360 Wh
+		31: 0xFFF6, 35: 0, // TmpCab = -10 C, others not implemented
+		36: 4, // St
+	}
+	addr = m.model(addr, 103, 50, inverter)
+	mppt := map[uint16]uint16{
+		0: 0xFFFF, 1: 0xFFFF, 2: 0, 3: 0, 6: 2,
+		8: 1, 9: 0x5056, 10: 0x3100, 19: 3500, // DCW = 3500 W
+		28: 2, 39: 1200,
+	}
+	addr = m.model(addr, 160, 48, mppt)
+	addr = m.model(addr, 64000, 200, nil) // vendor model read in two requests
+	m.set(addr, EndModelID, 0)
+
+	dev, err := Discover(context.Background(), m)
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+	if dev.Base != 40000 || len(dev.Models) != 4 {
+		t.Fatalf("Expected 4 models at 40000, got %d at %d", len(dev.Models), dev.Base)
+	}
+	if m.maxQty > modbus.MaxQuantityRegisters {
+		t.Errorf("Read %d registers in one request", m.maxQty)
+	}
+	if dev.Model(103) == nil || dev.Model(103).Address != 40070 {
+		t.Errorf("Expected model 103 at 40070, got %+v", dev.Model(103))
+	}
+
+	d, err := dev.Model(1).Decode()
+	if err != nil {
+		t.Fatalf("Decode common failed: %v", err)
+	}
+	for name, want := range map[string]any{"Mn": "Acme", "Md": "X1", "SN": "42", "Opt": "", "DA": uint16(1)} {
+		if got := d.Point(name).Value; got != want {
+			t.Errorf("%s: expected %v, got %v", name, want, got)
+		}
+	}
+
+	d, err = dev.Model(103).Decode()
+	if err != nil {
+		t.Fatalf("Decode inverter failed: %v", err)
+	}
+	for name, want := range map[string]any{
+		"A": 12.34, "AphA": nil, "PhVphA": 230.5, "W": 2800.0, "WH": 655360.0,
+		"TmpCab": -10.0, "TmpSnk": nil, "Hz": nil, "St": uint16(4), "Evt1": nil,
+	} {
+		if got := d.Point(name).Value; got != want {
+			t.Errorf("%s: expected %v (%T), got %v (%T)", name, want, want, got, got)
+		}
+	}
+
+	d, err = dev.Model(160).Decode()
+	if err != nil {
+		t.Fatalf("Decode MPPT failed: %v", err)
+	}
+	if len(d.Blocks) != 2 {
+		t.Fatalf("Expected 2 MPPT blocks, got %d", len(d.Blocks))
+	}
+	if got := fmt.Sprintf("%v %v %v %v", d.Blocks[0][0].Value, d.Blocks[0][1].Value, d.Blocks[0][4].Value, d.Blocks[1][4].Value); got != "1 PV1 3500 1200" {
+		t.Errorf("Unexpected MPPT values %s", got)
+	}
+
+	if _, err := dev.Models[3].Decode(); !errors.Is(err, ErrUnknownModel) {
+		t.Errorf("Expected ErrUnknownModel, got %v", err)
+	}
+}
+
+func TestDiscoverErrors(t *testing.T) {
+	m := &registerMap{regs: make(map[int]uint16)}
+	m.set(0, 1, 2)
+	if _, err := Discover(context.Background(), m); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	// Marker at a custom base, then a truncated chain
+	addr := m.set(1000, Marker[0], Marker[1])
+	addr = m.model(addr, 1, 66, nil)
+	m.set(addr, 101, 50)
+	dev, err := Discover(context.Background(), m, WithBaseAddresses(1000))
+	if !modbus.IsException(err, modbus.ExceptionIllegalDataAddress) {
+		t.Errorf("Expected illegal data address, got %v", err)
+	}
+	if dev == nil || len(dev.Models) != 1 {
+		t.Errorf("Expected the common model, got %+v", dev)
+	}
+
+	bad := Model{ID: 160, Length: 10, Registers: make([]uint16, 10)}
+	if _, err := bad.Decode(); !errors.Is(err, ErrInvalidModel) {
+		t.Errorf("Expected ErrInvalidModel, got %v", err)
+	}
+}
@@ -0,0 +1,640 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modbus
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// DataType is the type of the value held by a Tag.
+type DataType int
+
+const (
+	// TypeBool is a coil or discrete input, or the bit Bit of a register.
+	TypeBool DataType = iota
+	TypeInt16
+	TypeUint16
+	TypeInt32
+	TypeUint32
+	TypeInt64
+	TypeUint64
+	TypeFloat32
+	TypeFloat64
+	// TypeString holds two characters per register over Length registers.
+	TypeString
+	// TypeBCD holds four decimal digits per register over Length registers.
+	TypeBCD
+	// TypeBitfield is the Bits bits of a register starting at bit Bit.
+	TypeBitfield
+)
+
+// String returns a string representation of DataType.
+func (t DataType) String() string {
+	switch t {
+	case TypeBool:
+		return "bool"
+	case TypeInt16:
+		return "int16"
+	case TypeUint16:
+		return "uint16"
+	case TypeInt32:
+		return "int32"
+	case TypeUint32:
+		return "uint32"
+	case TypeInt64:
+		return "int64"
+	case TypeUint64:
+		return "uint64"
+	case TypeFloat32:
+		return "float32"
+	case TypeFloat64:
+		return "float64"
+	case TypeString:
+		return "string"
+	case TypeBCD:
+		return "bcd"
+	case TypeBitfield:
+		return "bitfield"
+	default:
+		return "unknown"
+	}
+}
+
+// dataTypeAliases are the other names of data types accepted by
+// ParseDataType, as found in device register maps.
+var dataTypeAliases = map[string]DataType{
+	"bit": TypeBool, "s16": TypeInt16, "i16": TypeInt16, "u16": TypeUint16,
+	"s32": TypeInt32, "i32": TypeInt32, "u32": TypeUint32,
+	"s64": TypeInt64, "i64": TypeInt64, "u64": TypeUint64,
+	"float": TypeFloat32, "f32": TypeFloat32, "double": TypeFloat64, "f64": TypeFloat64,
+	"ascii": TypeString, "str": TypeString,
+}
+
+// ParseDataType parses a data type name, as returned by DataType.String,
+// or one of the short names u16, s16, u32, s32, u64, s64, f32, f64, float,
+// double, bit and ascii, ignoring case.
+func ParseDataType(s string) (DataType, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	for t := TypeBool; t <= TypeBitfield; t++ {
+		if t.String() == name {
+			return t, nil
+		}
+	}
+	if t, ok := dataTypeAliases[name]; ok {
+		return t, nil
+	}
+	return 0, fmt.Errorf("%w: unknown data type %q", ErrInvalidValue, s)
+}
+
+// Tag describes a typed value of a device: where it is stored, how its
+// registers are encoded and how the raw value maps to engineering units.
+type Tag struct {
+	Name    string
+	Table   Table
+	Address uint16
+	Type    DataType
+
+	// Length is the number of registers of a TypeString or TypeBCD value;
+	// zero counts as 1.
+	Length uint16
+
+	// Bit is the bit of a TypeBool register value or the first bit of a
+	// TypeBitfield; Bits is the width of a TypeBitfield.
+	Bit  uint8
+	Bits uint8
+
+	// ByteOrder is the layout of multi-register values; strings only
+	// follow its byte swap, and the bits of TypeBool and TypeBitfield are
+	// those of the register as read. WordOrder LowWordFirst reverses the
+	// registers of numeric values, see ByteOrder.WithWordOrder.
+	ByteOrder ByteOrder
+	WordOrder WordOrder
+
+	// Numeric values are scaled to engineering values as
+	// raw*Scale + Offset, where a zero Scale counts as 1. Scaled values are
+	// read and written as float64.
+	Scale  float64
+	Offset float64
+	Unit   string
+}
+
+// Quantity returns the number of coils or registers holding the value.
+func (t *Tag) Quantity() int {
+	switch t.Type {
+	case TypeInt32, TypeUint32, TypeFloat32:
+		return 2
+	case TypeInt64, TypeUint64, TypeFloat64:
+		return 4
+	case TypeString, TypeBCD:
+		return int(max(t.Length, 1))
+	default:
+		return 1
+	}
+}
+
+// Point returns the point holding the value, for use with PlanReads.
+func (t *Tag) Point() Point {
+	return Point{Table: t.Table, Address: t.Address, Width: uint16(t.Quantity())}
+}
+
+// isBitTable reports whether the tag is stored in coils or discrete inputs.
+func (t *Tag) isBitTable() bool {
+	return t.Table == TableCoils || t.Table == TableDiscreteInputs
+}
+
+// scaled reports whether the tag converts raw values to engineering values.
+func (t *Tag) scaled() bool {
+	return (t.Scale != 0 && t.Scale != 1) || t.Offset != 0
+}
+
+// Validate checks that the tag can be read.
+func (t *Tag) Validate() error {
+	if t.Table < TableCoils || t.Table > TableInputRegisters {
+		return fmt.Errorf("%w: %s: unknown table %d", ErrInvalidTag, t.Name, int(t.Table))
+	}
+	if t.Type < TypeBool || t.Type > TypeBitfield {
+		return fmt.Errorf("%w: %s: unknown data type %d", ErrInvalidTag, t.Name, int(t.Type))
+	}
+	if t.ByteOrder&^OrderDCBA != 0 {
+		return fmt.Errorf("%w: %s: unknown byte order %d", ErrInvalidTag, t.Name, int(t.ByteOrder))
+	}
+	if t.WordOrder < HighWordFirst || t.WordOrder > LowWordFirst {
+		return fmt.Errorf("%w: %s: unknown word order %d", ErrInvalidTag, t.Name, int(t.WordOrder))
+	}
+	if t.WordOrder == LowWordFirst && t.ByteOrder&^orderSwapBytes != 0 {
+		return fmt.Errorf("%w: %s: word order with byte order %s, which already orders the registers", ErrInvalidTag, t.Name, t.ByteOrder)
+	}
+	if t.isBitTable() && t.Type != TypeBool {
+		return fmt.Errorf("%w: %s: %s tag in %s", ErrInvalidTag, t.Name, t.Type, t.Table)
+	}
+	if int(t.Address)+t.Quantity() > 65536 {
+		return fmt.Errorf("%w: %s: address range exceeds 65535", ErrInvalidTag, t.Name)
+	}
+	if t.Quantity() > MaxQuantityWriteRegisters {
+		return fmt.Errorf("%w: %s: length exceeds %d registers", ErrInvalidTag, t.Name, MaxQuantityWriteRegisters)
+	}
+	switch t.Type {
+	case TypeBool:
+		if !t.isBitTable() && t.Bit > 15 {
+			return fmt.Errorf("%w: %s: bit %d exceeds 15", ErrInvalidTag, t.Name, t.Bit)
+		}
+	case TypeBitfield:
+		if t.Bits == 0 || int(t.Bit)+int(t.Bits) > 16 {
+			return fmt.Errorf("%w: %s: bitfield %d+%d does not fit a register", ErrInvalidTag, t.Name, t.Bit, t.Bits)
+		}
+	case TypeBCD:
+		if t.Quantity() > 4 {
+			return fmt.Errorf("%w: %s: BCD values hold at most 16 digits", ErrInvalidTag, t.Name)
+		}
+	}
+	return nil
+}
+
+// Overlaps reports whether the tags share a coil, discrete input or
+// register. Tags holding disjoint bits of the same register do not
+// overlap.
+func (t *Tag) Overlaps(u *Tag) bool {
+	if t.Table != u.Table || !t.Point().overlaps(u.Point()) {
+		return false
+	}
+	return !t.isRegisterBits() || !u.isRegisterBits() || t.bitMask()&u.bitMask() != 0
+}
+
+// order returns the byte order of the registers of the value.
+func (t *Tag) order() ByteOrder {
+	if t.Type == TypeString {
+		return t.ByteOrder & orderSwapBytes
+	}
+	return t.ByteOrder.WithWordOrder(t.WordOrder)
+}
+
+// Decode converts the registers of a register tag to its value: bool for
+// TypeBool, string for TypeString, uint64 for TypeBCD, uint16 for
+// TypeBitfield and the Go type of the same name otherwise. Scaled numeric
+// values are returned as float64.
+func (t *Tag) Decode(regs []uint16) (any, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	if t.isBitTable() {
+		return nil, fmt.Errorf("%w: %s: %s are not registers", ErrInvalidTag, t.Name, t.Table)
+	}
+	if len(regs) != t.Quantity() {
+		return nil, fmt.Errorf("%w: %s: expected %d registers, got %d", ErrInvalidValue, t.Name, t.Quantity(), len(regs))
+	}
+
+	switch t.Type {
+	case TypeBool:
+		return regs[0]&(1<<t.Bit) != 0, nil
+	case TypeBitfield:
+		return regs[0] >> t.Bit & (1<<t.Bits - 1), nil
+	case TypeString:
+		return strings.TrimRight(string(t.order().Bytes(regs)), "\x00"), nil
+	}
+
+	var raw uint64
+	for _, b := range t.order().Bytes(regs) {
+		raw = raw<<8 | uint64(b)
+	}
+
+	var value any
+	var f float64
+	switch t.Type {
+	case TypeInt16:
+		value, f = int16(raw), float64(int16(raw))
+	case TypeUint16:
+		value, f = uint16(raw), float64(uint16(raw))
+	case TypeInt32:
+		value, f = int32(raw), float64(int32(raw))
+	case TypeUint32:
+		value, f = uint32(raw), float64(uint32(raw))
+	case TypeInt64:
+		value, f = int64(raw), float64(int64(raw))
+	case TypeUint64:
+		value, f = raw, float64(raw)
+	case TypeFloat32:
+		v := math.Float32frombits(uint32(raw))
+		value, f = v, float64(v)
+	case TypeFloat64:
+		v := math.Float64frombits(raw)
+		value, f = v, v
+	case TypeBCD:
+		v, err := decodeBCD(raw, 4*t.Quantity())
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidValue, t.Name, err)
+		}
+		value, f = v, float64(v)
+	}
+
+	if t.scaled() {
+		scale := t.Scale
+		if scale == 0 {
+			scale = 1
+		}
+		return f*scale + t.Offset, nil
+	}
+	return value, nil
+}
+
+// Encode converts a value to the registers of a register tag. Integer
+// types accept any Go integer or integral float within their range, float
+// types any Go number, and scaled tags any number in engineering units.
+// TypeBool and TypeBitfield return the register with only the value bits
+// set; see Client.WriteTag for the read-modify-write of the other bits.
+func (t *Tag) Encode(value any) ([]uint16, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	if t.isBitTable() {
+		return nil, fmt.Errorf("%w: %s: %s are not registers", ErrInvalidTag, t.Name, t.Table)
+	}
+
+	switch t.Type {
+	case TypeBool:
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s: expected bool, got %T", ErrInvalidValue, t.Name, value)
+		}
+		if b {
+			return []uint16{1 << t.Bit}, nil
+		}
+		return []uint16{0}, nil
+
+	case TypeString:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s: expected string, got %T", ErrInvalidValue, t.Name, value)
+		}
+		b := make([]byte, 2*t.Quantity())
+		if len(s) > len(b) {
+			return nil, fmt.Errorf("%w: %s: string longer than %d bytes", ErrInvalidValue, t.Name, len(b))
+		}
+		copy(b, s)
+		return t.order().Registers(b), nil
+	}
+
+	if t.scaled() {
+		f, ok := floatValue(value)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s: expected a number, got %T", ErrInvalidValue, t.Name, value)
+		}
+		scale := t.Scale
+		if scale == 0 {
+			scale = 1
+		}
+		f = (f - t.Offset) / scale
+		if t.Type != TypeFloat32 && t.Type != TypeFloat64 {
+			f = math.Round(f)
+		}
+		value = f
+	}
+
+	var raw uint64
+	var err error
+	switch t.Type {
+	case TypeInt16:
+		raw, err = integerBits(value, true, 16)
+	case TypeUint16:
+		raw, err = integerBits(value, false, 16)
+	case TypeInt32:
+		raw, err = integerBits(value, true, 32)
+	case TypeUint32:
+		raw, err = integerBits(value, false, 32)
+	case TypeInt64:
+		raw, err = integerBits(value, true, 64)
+	case TypeUint64:
+		raw, err = integerBits(value, false, 64)
+	case TypeBitfield:
+		raw, err = integerBits(value, false, int(t.Bits))
+		raw <<= t.Bit
+	case TypeFloat32, TypeFloat64:
+		f, ok := floatValue(value)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s: expected a number, got %T", ErrInvalidValue, t.Name, value)
+		}
+		if t.Type == TypeFloat32 {
+			raw = uint64(math.Float32bits(float32(f)))
+		} else {
+			raw = math.Float64bits(f)
+		}
+	case TypeBCD:
+		var n uint64
+		if n, err = integerBits(value, false, 64); err == nil {
+			raw, err = encodeBCD(n, 4*t.Quantity())
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidValue, t.Name, err)
+	}
+	if t.Type == TypeBitfield {
+		return []uint16{uint16(raw)}, nil
+	}
+
+	b := make([]byte, 2*t.Quantity())
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i] = byte(raw)
+		raw >>= 8
+	}
+	return t.order().Registers(b), nil
+}
+
+// floatValue converts any Go number to float64.
+func floatValue(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// integerBits converts any Go integer, or integral float, to the two's
+// complement representation of a signed or unsigned integer of the given
+// width, checking its range.
+func integerBits(value any, signed bool, bits int) (uint64, error) {
+	var n int64 // value when negative
+	var u uint64
+	switch v := value.(type) {
+	case int:
+		n = int64(v)
+	case int8:
+		n = int64(v)
+	case int16:
+		n = int64(v)
+	case int32:
+		n = int64(v)
+	case int64:
+		n = v
+	case uint:
+		u = uint64(v)
+	case uint8:
+		u = uint64(v)
+	case uint16:
+		u = uint64(v)
+	case uint32:
+		u = uint64(v)
+	case uint64:
+		u = v
+	case float32, float64:
+		f, _ := floatValue(v)
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxUint64 {
+			return 0, fmt.Errorf("%v is not an integer in range", v)
+		}
+		if f < 0 {
+			n = int64(f)
+		} else {
+			u = uint64(f)
+		}
+	default:
+		return 0, fmt.Errorf("expected an integer, got %T", value)
+	}
+	if n > 0 {
+		u, n = uint64(n), 0
+	}
+
+	mask := uint64(math.MaxUint64)
+	if bits < 64 {
+		mask = 1<<bits - 1
+	}
+	switch {
+	case n < 0 && !signed:
+		return 0, fmt.Errorf("%d is negative", n)
+	case n < 0:
+		if bits < 64 && n < -(1<<(bits-1)) {
+			return 0, fmt.Errorf("%d overflows int%d", n, bits)
+		}
+		return uint64(n) & mask, nil
+	case signed && u > mask>>1:
+		return 0, fmt.Errorf("%d overflows int%d", u, bits)
+	case u > mask:
+		return 0, fmt.Errorf("%d overflows %d bits", u, bits)
+	}
+	return u, nil
+}
+
+// decodeBCD decodes the given number of BCD digits of raw.
+func decodeBCD(raw uint64, digits int) (uint64, error) {
+	var v uint64
+	for i := digits - 1; i >= 0; i-- {
+		d := raw >> (4 * i) & 0xF
+		if d > 9 {
+			return 0, fmt.Errorf("invalid BCD digit 0x%X", d)
+		}
+		v = v*10 + d
+	}
+	return v, nil
+}
+
+// encodeBCD encodes v on the given number of BCD digits.
+func encodeBCD(v uint64, digits int) (uint64, error) {
+	var raw uint64
+	for i := 0; i < digits; i++ {
+		raw |= (v % 10) << (4 * i)
+		v /= 10
+	}
+	if v != 0 {
+		return 0, fmt.Errorf("value does not fit %d BCD digits", digits)
+	}
+	return raw, nil
+}
+
+// ReadTag reads the value of a tag; see Tag.Decode for the returned types.
+func (c *Client) ReadTag(ctx context.Context, tag *Tag) (any, error) {
+	return c.ReadTagWithUnit(ctx, c.UnitID(), tag)
+}
+
+// WriteTag writes the value of a tag; see Tag.Encode for the accepted
+// types. TypeBool and TypeBitfield register tags are written with a mask
+// write (FC22) that leaves the other bits of the register unchanged.
+// Discrete inputs and input registers are read-only.
+func (c *Client) WriteTag(ctx context.Context, tag *Tag, value any) error {
+	return c.WriteTagWithUnit(ctx, c.UnitID(), tag, value)
+}
+
+// ReadTagWithUnit reads the value of a tag using a specific unit ID.
+func (c *Client) ReadTagWithUnit(ctx context.Context, unitID UnitID, tag *Tag) (any, error) {
+	if err := tag.Validate(); err != nil {
+		return nil, err
+	}
+
+	qty := uint16(tag.Quantity())
+	var bits []bool
+	var regs []uint16
+	var err error
+	switch tag.Table {
+	case TableCoils:
+		bits, err = c.ReadCoilsWithUnit(ctx, unitID, tag.Address, qty)
+	case TableDiscreteInputs:
+		bits, err = c.ReadDiscreteInputsWithUnit(ctx, unitID, tag.Address, qty)
+	case TableHoldingRegisters:
+		regs, err = c.ReadHoldingRegistersWithUnit(ctx, unitID, tag.Address, qty)
+	case TableInputRegisters:
+		regs, err = c.ReadInputRegistersWithUnit(ctx, unitID, tag.Address, qty)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if bits != nil {
+		return bits[0], nil
+	}
+	return tag.Decode(regs)
+}
+
+// ReadTags reads the values of several tags with as few requests as
+// PlanReads can build; see Tag.Decode for the returned types. If some
+// requests fail or some values cannot be decoded, the values of their tags
+// are nil and the first error is returned with the other values.
+func (c *Client) ReadTags(ctx context.Context, tags []*Tag, opts ...PlanOption) ([]any, error) {
+	return c.ReadTagsWithUnit(ctx, c.UnitID(), tags, opts...)
+}
+
+// ReadTagsWithUnit reads the values of several tags using a specific unit
+// ID.
+func (c *Client) ReadTagsWithUnit(ctx context.Context, unitID UnitID, tags []*Tag, opts ...PlanOption) ([]any, error) {
+	values, _, err := c.readTags(ctx, unitID, tags, opts...)
+	return values, err
+}
+
+// readTags reads the values of several tags like ReadTagsWithUnit, and also
+// returns the error of each tag that could not be read or decoded.
+func (c *Client) readTags(ctx context.Context, unitID UnitID, tags []*Tag, opts ...PlanOption) ([]any, []error, error) {
+	points := make([]Point, len(tags))
+	for i, tag := range tags {
+		if err := tag.Validate(); err != nil {
+			return nil, nil, err
+		}
+		points[i] = tag.Point()
+	}
+	plan, err := PlanReads(points, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	results, readErr := c.ExecutePlanWithUnit(ctx, unitID, plan)
+	values := make([]any, len(tags))
+	errs := make([]error, len(tags))
+	for i, tag := range tags {
+		switch {
+		case results[i].Err != nil:
+			errs[i] = results[i].Err
+		case results[i].Bits != nil:
+			values[i] = results[i].Bits[0]
+		default:
+			if values[i], errs[i] = tag.Decode(results[i].Registers); errs[i] != nil && readErr == nil {
+				readErr = errs[i]
+			}
+		}
+	}
+	return values, errs, readErr
+}
+
+// WriteTagWithUnit writes the value of a tag using a specific unit ID.
+func (c *Client) WriteTagWithUnit(ctx context.Context, unitID UnitID, tag *Tag, value any) error {
+	if err := tag.Validate(); err != nil {
+		return err
+	}
+
+	switch tag.Table {
+	case TableCoils:
+		b, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("%w: %s: expected bool, got %T", ErrInvalidValue, tag.Name, value)
+		}
+		return c.WriteSingleCoilWithUnit(ctx, unitID, tag.Address, b)
+	case TableDiscreteInputs, TableInputRegisters:
+		return fmt.Errorf("%w: %s: %s are read-only", ErrInvalidTag, tag.Name, tag.Table)
+	}
+
+	regs, err := tag.Encode(value)
+	if err != nil {
+		return err
+	}
+	switch {
+	case tag.Type == TypeBool:
+		mask := uint16(1) << tag.Bit
+		return c.MaskWriteRegisterWithUnit(ctx, unitID, tag.Address, ^mask, regs[0])
+	case tag.Type == TypeBitfield:
+		mask := uint16(1<<tag.Bits-1) << tag.Bit
+		return c.MaskWriteRegisterWithUnit(ctx, unitID, tag.Address, ^mask, regs[0])
+	case len(regs) == 1:
+		return c.WriteSingleRegisterWithUnit(ctx, unitID, tag.Address, regs[0])
+	default:
+		return c.WriteMultipleRegistersWithUnit(ctx, unitID, tag.Address, regs)
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestTagCodec(t *testing.T) {
+	hr := func(typ DataType) Tag {
+		return Tag{Table: TableHoldingRegisters, Type: typ}
+	}
+	with := func(tag Tag, fn func(*Tag)) Tag {
+		fn(&tag)
+		return tag
+	}
+
+	tests := []struct {
+		name  string
+		tag   Tag
+		regs  []uint16
+		value any
+	}{
+		{"int16", hr(TypeInt16), []uint16{0xFFFE}, int16(-2)},
+		{"uint16", hr(TypeUint16), []uint16{0xFFFE}, uint16(65534)},
+		{"int32", hr(TypeInt32), []uint16{0xFFFE, 0x1DC0}, int32(-123456)},
+		{"uint32 CDAB", with(hr(TypeUint32), func(t *Tag) { t.ByteOrder = OrderCDAB }), []uint16{0x5678, 0x1234}, uint32(0x12345678)},
+		{"int64", hr(TypeInt64), []uint16{0xFFFF, 0xFFFF, 0xFFFF, 0xFFFF}, int64(-1)},
+		{"uint64", hr(TypeUint64), []uint16{0x0123, 0x4567, 0x89AB, 0xCDEF}, uint64(0x0123456789ABCDEF)},
+		{"float32", hr(TypeFloat32), []uint16{0x3FC0, 0x0000}, float32(1.5)},
+		{"float32 BADC", with(hr(TypeFloat32), func(t *Tag) { t.ByteOrder = OrderBADC }), []uint16{0xC03F, 0x0000}, float32(1.5)},
+		{"float32 DCBA", with(hr(TypeFloat32), func(t *Tag) { t.ByteOrder = OrderDCBA }), []uint16{0x0000, 0xC03F}, float32(1.5)},
+		{"float64", hr(TypeFloat64), []uint16{0x4009, 0x21FB, 0x5444, 0x2D18}, 3.141592653589793},
+		{"string", with(hr(TypeString), func(t *Tag) { t.Length = 2 }), []uint16{0x4142, 0x4300}, "ABC"},
+		{"string byte swapped", with(hr(TypeString), func(t *Tag) { t.Length = 2; t.ByteOrder = OrderDCBA }), []uint16{0x4241, 0x0043}, "ABC"},
+		{"bcd", hr(TypeBCD), []uint16{0x1234}, uint64(1234)},
+		{"bcd two registers", with(hr(TypeBCD), func(t *Tag) { t.Length = 2 }), []uint16{0x0012, 0x3456}, uint64(123456)},
+		{"bitfield", with(hr(TypeBitfield), func(t *Tag) { t.Bit, t.Bits = 4, 3 }), []uint16{0x0050}, uint16(5)},
+		{"bitfield BADC", with(hr(TypeBitfield), func(t *Tag) { t.Bits, t.ByteOrder = 4, OrderBADC }), []uint16{0x0005}, uint16(5)},
+		{"bool in register", with(hr(TypeBool), func(t *Tag) { t.Bit = 15 }), []uint16{0x8000}, true},
+		{"scaled", with(hr(TypeInt16), func(t *Tag) { t.Scale, t.Offset = 0.5, -40 }), []uint16{130}, 25.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := tt.tag.Decode(tt.regs)
+			if err != nil {
+				t.Fatalf("Decode failed: %v", err)
+			}
+			if value != tt.value {
+				t.Errorf("Decode: expected %v (%T), got %v (%T)", tt.value, tt.value, value, value)
+			}
+
+			regs, err := tt.tag.Encode(tt.value)
+			if err != nil {
+				t.Fatalf("Encode failed: %v", err)
+			}
+			if fmt.Sprint(regs) != fmt.Sprint(tt.regs) {
+				t.Errorf("Encode: expected %04X, got %04X", tt.regs, regs)
+			}
+		})
+	}
+}
+
+func TestTagEncodeConversions(t *testing.T) {
+	tag := Tag{Table: TableHoldingRegisters, Type: TypeInt16}
+	for _, value := range []any{-2, int8(-2), int64(-2), -2.0} {
+		if regs, err := tag.Encode(value); err != nil || regs[0] != 0xFFFE {
+			t.Errorf("Encode(%T): expected FFFE, got %v (%v)", value, regs, err)
+		}
+	}
+
+	tag.Type = TypeFloat32
+	if regs, err := tag.Encode(2); err != nil || regs[0] != 0x4000 {
+		t.Errorf("Expected 2.0, got %v (%v)", regs, err)
+	}
+
+	tag = Tag{Table: TableHoldingRegisters, Type: TypeUint16, Scale: 0.1}
+	if regs, err := tag.Encode(21.37); err != nil || regs[0] != 214 {
+		t.Errorf("Expected rounded raw value 214, got %v (%v)", regs, err)
+	}
+}
+
+func TestTagErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		tag    Tag
+		value  any
+		target error
+	}{
+		{"int16 overflow", Tag{Type: TypeInt16, Table: TableHoldingRegisters}, 40000, ErrInvalidValue},
+		{"int16 underflow", Tag{Type: TypeInt16, Table: TableHoldingRegisters}, -40000, ErrInvalidValue},
+		{"negative unsigned", Tag{Type: TypeUint32, Table: TableHoldingRegisters}, -1, ErrInvalidValue},
+		{"fraction", Tag{Type: TypeInt32, Table: TableHoldingRegisters}, 1.5, ErrInvalidValue},
+		{"wrong type", Tag{Type: TypeUint16, Table: TableHoldingRegisters}, "1", ErrInvalidValue},
+		{"string too long", Tag{Type: TypeString, Table: TableHoldingRegisters}, "ABC", ErrInvalidValue},
+		{"bcd overflow", Tag{Type: TypeBCD, Table: TableHoldingRegisters}, 12345, ErrInvalidValue},
+		{"bitfield overflow", Tag{Type: TypeBitfield, Table: TableHoldingRegisters, Bits: 2}, 4, ErrInvalidValue},
+		{"float in coils", Tag{Type: TypeFloat32, Table: TableCoils}, 1.0, ErrInvalidTag},
+		{"bit 16", Tag{Type: TypeBool, Table: TableHoldingRegisters, Bit: 16}, true, ErrInvalidTag},
+		{"bitfield too wide", Tag{Type: TypeBitfield, Table: TableHoldingRegisters, Bit: 10, Bits: 7}, 0, ErrInvalidTag},
+		{"bcd too long", Tag{Type: TypeBCD, Table: TableHoldingRegisters, Length: 5}, 0, ErrInvalidTag},
+		{"end of address space", Tag{Type: TypeFloat32, Table: TableHoldingRegisters, Address: 65535}, 1.0, ErrInvalidTag},
+		{"unknown byte order", Tag{Type: TypeFloat32, Table: TableHoldingRegisters, ByteOrder: 8}, 1.0, ErrInvalidTag},
+		{"unknown word order", Tag{Type: TypeFloat32, Table: TableHoldingRegisters, WordOrder: 5}, 1.0, ErrInvalidTag},
+		{"word order with CDAB", Tag{Type: TypeFloat32, Table: TableHoldingRegisters, ByteOrder: OrderCDAB, WordOrder: LowWordFirst}, 1.0, ErrInvalidTag},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.tag.Encode(tt.value); !errors.Is(err, tt.target) {
+				t.Errorf("Expected %v, got %v", tt.target, err)
+			}
+		})
+	}
+
+	bcd := Tag{Type: TypeBCD, Table: TableHoldingRegisters}
+	if _, err := bcd.Decode([]uint16{0x12A4}); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Expected ErrInvalidValue for invalid BCD digit, got %v", err)
+	}
+	if _, err := bcd.Decode([]uint16{1, 2}); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Expected ErrInvalidValue for wrong register count, got %v", err)
+	}
+}
+
+func TestClientTags(t *testing.T) {
+	handler := NewMemoryHandler(65536, 65536)
+	handler.SetInputRegister(1, 0, 0x1234)
+	client, err := NewClientWithTransport(&loopbackTransport{server: NewServer(handler)}, WithUnitID(1))
+	if err != nil {
+		t.Fatalf("NewClientWithTransport failed: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	speed := &Tag{Name: "speed", Table: TableHoldingRegisters, Address: 10, Type: TypeFloat32, ByteOrder: OrderCDAB, Unit: "rpm"}
+	if err := client.WriteTag(ctx, speed, 1480.5); err != nil {
+		t.Fatalf("WriteTag failed: %v", err)
+	}
+	if regs, _ := handler.ReadHoldingRegisters(1, 10, 2); fmt.Sprintf("%04X", regs) != "[1000 44B9]" {
+		t.Errorf("Expected low word first, got %04X", regs)
+	}
+	if value, err := client.ReadTag(ctx, speed); err != nil || value != float32(1480.5) {
+		t.Errorf("Expected 1480.5, got %v (%v)", value, err)
+	}
+
+	// Bits are written without changing the rest of the register
+	handler.SetHoldingRegister(1, 20, 0xF00F)
+	if err := client.WriteTag(ctx, &Tag{Table: TableHoldingRegisters, Address: 20, Type: TypeBool, Bit: 0}, false); err != nil {
+		t.Fatalf("WriteTag bool failed: %v", err)
+	}
+	if err := client.WriteTag(ctx, &Tag{Table: TableHoldingRegisters, Address: 20, Type: TypeBitfield, Bit: 4, Bits: 4}, 0xA); err != nil {
+		t.Fatalf("WriteTag bitfield failed: %v", err)
+	}
+	if regs, _ := handler.ReadHoldingRegisters(1, 20, 1); regs[0] != 0xF0AE {
+		t.Errorf("Expected F0AE, got %04X", regs[0])
+	}
+
+	coil := &Tag{Table: TableCoils, Address: 3, Type: TypeBool}
+	if err := client.WriteTag(ctx, coil, true); err != nil {
+		t.Fatalf("WriteTag coil failed: %v", err)
+	}
+	if value, err := client.ReadTag(ctx, coil); err != nil || value != true {
+		t.Errorf("Expected coil set, got %v (%v)", value, err)
+	}
+
+	input := &Tag{Table: TableInputRegisters, Address: 0, Type: TypeBCD}
+	if value, err := client.ReadTag(ctx, input); err != nil || value != uint64(1234) {
+		t.Errorf("Expected 1234, got %v (%v)", value, err)
+	}
+	if err := client.WriteTag(ctx, input, 1); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("Expected ErrInvalidTag for read-only table, got %v", err)
+	}
+
+	// A value that cannot be decoded does not discard the others
+	handler.SetInputRegister(1, 1, 0x12A4)
+	invalid := &Tag{Name: "invalid", Table: TableInputRegisters, Address: 1, Type: TypeBCD}
+	values, err := client.ReadTags(ctx, []*Tag{input, invalid, speed})
+	if !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Expected ErrInvalidValue, got %v", err)
+	}
+	if len(values) != 3 || values[0] != uint64(1234) || values[1] != nil || values[2] != float32(1480.5) {
+		t.Errorf("Expected [1234 <nil> 1480.5], got %v", values)
+	}
+}
+
+func TestParseTableAndDataType(t *testing.T) {
+	tables := map[string]Table{
+		"coil": TableCoils, "DI": TableDiscreteInputs, " holding ": TableHoldingRegisters,
+		"InputRegisters": TableInputRegisters,
+	}
+	for s, want := range tables {
+		if got, err := ParseTable(s); err != nil || got != want {
+			t.Errorf("ParseTable(%q): expected %s, got %s (%v)", s, want, got, err)
+		}
+	}
+	if _, err := ParseTable("registers"); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Expected ErrInvalidValue, got %v", err)
+	}
+
+	types := map[string]DataType{
+		"float32": TypeFloat32, "FLOAT": TypeFloat32, "s32": TypeInt32, "U16": TypeUint16,
+		"double": TypeFloat64, "bcd": TypeBCD, "ascii": TypeString,
+	}
+	for s, want := range types {
+		if got, err := ParseDataType(s); err != nil || got != want {
+			t.Errorf("ParseDataType(%q): expected %s, got %s (%v)", s, want, got, err)
+		}
+	}
+	if _, err := ParseDataType("int128"); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Expected ErrInvalidValue, got %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modbus
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+)
+
+// RoleOID is the X.509 certificate extension carrying the Modbus role
+// defined by the Modbus/TCP Security specification.
+var RoleOID = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 50316, 802, 1}
+
+// RoleFromCertificate returns the Modbus role carried by cert, or an empty
+// string if the certificate has no role extension.
+func RoleFromCertificate(cert *x509.Certificate) (string, error) {
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(RoleOID) {
+			continue
+		}
+		var role string
+		rest, err := asn1.Unmarshal(ext.Value, &role)
+		if err != nil {
+			return "", fmt.Errorf("modbus: invalid role extension: %w", err)
+		}
+		if len(rest) > 0 {
+			return "", fmt.Errorf("modbus: invalid role extension: trailing data")
+		}
+		return role, nil
+	}
+	return "", nil
+}
+
+// RoleAuthorizer is an optional interface for handlers served over TLS.
+// Each request is authorized against the role found in the client
+// certificate; requests that are not authorized are answered with an
+// illegal function exception without reaching the handler.
+type RoleAuthorizer interface {
+	Authorize(role string, unitID UnitID, fc FunctionCode) bool
+}
+
+// ListenAndServeTLS starts a Modbus/TCP Security server on the given
+// address, usually on DefaultTLSPort. As required by the specification,
+// clients must present a certificate verified against config.ClientCAs
+// unless config.ClientAuth says otherwise, and TLS 1.2 is the minimum version.
+// config must hold the server certificate.
+func (s *Server) ListenAndServeTLS(addr string, config *tls.Config) error {
+	if config == nil {
+		return errors.New("modbus: TLS config cannot be nil")
+	}
+	config = config.Clone()
+	if config.MinVersion == 0 {
+		config.MinVersion = tls.VersionTLS12
+	}
+	if config.ClientAuth == tls.NoClientCert {
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	listener, err := tls.Listen("tcp", addr, config)
+	if err != nil {
+		return err
+	}
+	return s.Serve(listener)
+}
+
+// tlsRole completes the TLS handshake on conn and returns the role of the
+// client certificate.
+func (s *Server) tlsRole(conn *tls.Conn) (string, error) {
+	if s.opts.readTimeout > 0 {
+		conn.SetDeadline(timeNow().Add(s.opts.readTimeout))
+	}
+	if err := conn.Handshake(); err != nil {
+		return "", err
+	}
+
+	state := conn.ConnectionState()
+	if len(state.PeerCertificates) == 0 {
+		return "", nil
+	}
+	return RoleFromCertificate(state.PeerCertificates[0])
+}
+
+// authorized reports whether the handler authorizes role to send requests
+// with function code fc to a unit. Handlers that are not a RoleAuthorizer
+// authorize every request.
+func (s *Server) authorized(role string, unitID UnitID, fc FunctionCode) bool {
+	authorizer, ok := s.handler.(RoleAuthorizer)
+	if !ok || authorizer.Authorize(role, unitID, fc) {
+		return true
+	}
+
+	s.opts.logger.Debug("request not authorized",
+		slog.String("role", role),
+		slog.Uint64("unit_id", uint64(unitID)),
+		slog.String("func", fc.String()))
+	return false
+}
+
+// tcpConnOf returns the TCP connection underlying conn, if any.
+func tcpConnOf(conn net.Conn) (*net.TCPConn, bool) {
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		conn = tlsConn.NetConn()
+	}
+	tcpConn, ok := conn.(*net.TCPConn)
+	return tcpConn, ok
+}
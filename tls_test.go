@@ -0,0 +1,299 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modbus
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+)
+
+// testCA issues certificates for TLS tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pool *x509.CertPool
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate failed: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate failed: %v", err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &testCA{cert: cert, key: key, pool: pool}
+}
+
+// issue creates a leaf certificate. A non-nil role is added as the
+// Modbus role extension.
+func (ca *testCA) issue(t *testing.T, name string, usage x509.ExtKeyUsage, role []byte) tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	if role != nil {
+		tmpl.ExtraExtensions = []pkix.Extension{{Id: RoleOID, Value: role}}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("CreateCertificate failed: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func encodeRole(t *testing.T, role string) []byte {
+	t.Helper()
+	value, err := asn1.MarshalWithParams(role, "utf8")
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	return value
+}
+
+func TestRoleFromCertificate(t *testing.T) {
+	ca := newTestCA(t)
+
+	tests := []struct {
+		name    string
+		role    []byte
+		want    string
+		wantErr bool
+	}{
+		{"WithRole", encodeRole(t, "Operator"), "Operator", false},
+		{"NoRole", nil, "", false},
+		{"Malformed", []byte{0x0C, 0x05, 'a'}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsCert := ca.issue(t, "client", x509.ExtKeyUsageClientAuth, tt.role)
+			cert, err := x509.ParseCertificate(tlsCert.Certificate[0])
+			if err != nil {
+				t.Fatalf("ParseCertificate failed: %v", err)
+			}
+
+			role, err := RoleFromCertificate(cert)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if role != tt.want {
+				t.Errorf("Expected role %q, got %q", tt.want, role)
+			}
+		})
+	}
+}
+
+// roleHandler lets operators read registers and engineers do anything.
+type roleHandler struct {
+	*MemoryHandler
+	roles chan string
+}
+
+func (h *roleHandler) Authorize(role string, unitID UnitID, fc FunctionCode) bool {
+	h.roles <- role
+	switch role {
+	case "Engineer":
+		return true
+	case "Operator":
+		return fc == FuncReadHoldingRegisters
+	default:
+		return false
+	}
+}
+
+func TestTLSClientServer(t *testing.T) {
+	ca := newTestCA(t)
+	serverCert := ca.issue(t, "server", x509.ExtKeyUsageServerAuth, nil)
+
+	handler := &roleHandler{
+		MemoryHandler: NewMemoryHandler(65536, 65536),
+		roles:         make(chan string, 16),
+	}
+	handler.SetHoldingRegister(1, 0, 802)
+
+	server := NewServer(handler)
+	go server.ListenAndServeTLS("127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientCAs:    ca.pool,
+	})
+	defer server.Close()
+
+	var addr string
+	for i := 0; i < 100 && addr == ""; i++ {
+		if a := server.Addr(); a != nil {
+			addr = a.String()
+		} else {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	if addr == "" {
+		t.Fatal("Server did not start")
+	}
+
+	ctx := context.Background()
+	dial := func(t *testing.T, certs ...tls.Certificate) *Client {
+		t.Helper()
+		client, err := NewClient(addr, WithTimeout(2*time.Second), WithTLSConfig(&tls.Config{
+			Certificates: certs,
+			RootCAs:      ca.pool,
+		}))
+		if err != nil {
+			t.Fatalf("NewClient failed: %v", err)
+		}
+		t.Cleanup(func() { client.Close() })
+		return client
+	}
+
+	t.Run("Operator", func(t *testing.T) {
+		client := dial(t, ca.issue(t, "hmi", x509.ExtKeyUsageClientAuth, encodeRole(t, "Operator")))
+		if err := client.Connect(ctx); err != nil {
+			t.Fatalf("Connect failed: %v", err)
+		}
+
+		regs, err := client.ReadHoldingRegisters(ctx, 0, 1)
+		if err != nil {
+			t.Fatalf("ReadHoldingRegisters failed: %v", err)
+		}
+		if regs[0] != 802 {
+			t.Errorf("Expected 802, got %d", regs[0])
+		}
+		if role := <-handler.roles; role != "Operator" {
+			t.Errorf("Expected role Operator, got %q", role)
+		}
+
+		err = client.WriteSingleRegister(ctx, 0, 1)
+		if !IsIllegalFunction(err) {
+			t.Errorf("Expected illegal function, got %v", err)
+		}
+		<-handler.roles
+	})
+
+	t.Run("Engineer", func(t *testing.T) {
+		client := dial(t, ca.issue(t, "eng", x509.ExtKeyUsageClientAuth, encodeRole(t, "Engineer")))
+		if err := client.Connect(ctx); err != nil {
+			t.Fatalf("Connect failed: %v", err)
+		}
+
+		if err := client.WriteSingleRegister(ctx, 1, 42); err != nil {
+			t.Fatalf("WriteSingleRegister failed: %v", err)
+		}
+		<-handler.roles
+	})
+
+	t.Run("NoClientCertificate", func(t *testing.T) {
+		client := dial(t)
+		err := client.Connect(ctx)
+		if err == nil {
+			// With TLS 1.3 the rejection surfaces on the first exchange
+			_, err = client.ReadHoldingRegisters(ctx, 0, 1)
+		}
+		if err == nil {
+			t.Error("Expected client without certificate to be rejected")
+		}
+	})
+}
+
+func TestListenAndServeTLSNilConfig(t *testing.T) {
+	if err := NewServer(NewMemoryHandler(1, 1)).ListenAndServeTLS("127.0.0.1:0", nil); err == nil {
+		t.Error("Expected an error for a nil TLS config")
+	}
+}
+
+func TestServerAuthorizationCounters(t *testing.T) {
+	handler := &roleHandler{
+		MemoryHandler: NewMemoryHandler(65536, 65536),
+		roles:         make(chan string, 64),
+	}
+	server := NewServer(handler)
+	operator := &peer{secure: true, role: "Operator"}
+	engineer := &peer{secure: true, role: "Engineer"}
+	request := func(from *peer, pdu []byte) *Frame {
+		return server.processRequest(context.Background(), &Frame{Header: MBAPHeader{UnitID: 1}, PDU: pdu}, from)
+	}
+	counter := func(subFunc uint16) uint16 {
+		t.Helper()
+		resp := request(engineer, BuildDiagnosticsPDU(subFunc, []byte{0x00, 0x00}))
+		_, data, err := ParseDiagnosticsResponse(resp.PDU)
+		if err != nil || len(data) != 2 {
+			t.Fatalf("Sub-function %02X failed: % X", subFunc, resp.PDU)
+		}
+		return uint16(data[0])<<8 | uint16(data[1])
+	}
+
+	// Rejected requests are counted like any other
+	write := BuildWriteSingleRegisterPDU(0, 1)
+	messages := counter(DiagReturnBusMessageCount)
+	if resp := request(operator, write); resp == nil || resp.PDU[0] != 0x86 || resp.PDU[1] != byte(ExceptionIllegalFunction) {
+		t.Fatalf("Expected an illegal function exception, got %v", resp)
+	}
+	if got := counter(DiagReturnBusMessageCount); got != messages+2 {
+		t.Errorf("Expected bus message count %d, got %d", messages+2, got)
+	}
+	if got := counter(DiagReturnBusExceptionErrorCount); got != 1 {
+		t.Errorf("Expected bus exception count 1, got %d", got)
+	}
+
+	// In listen only mode they get no response either
+	if resp := request(engineer, BuildDiagnosticsPDU(DiagForceListenOnlyMode, []byte{0x00, 0x00})); resp != nil {
+		t.Fatalf("Expected no response to force listen only, got % X", resp.PDU)
+	}
+	if resp := request(operator, write); resp != nil {
+		t.Errorf("Expected no response in listen only mode, got % X", resp.PDU)
+	}
+	if regs, _ := handler.ReadHoldingRegisters(1, 0, 1); regs[0] != 0 {
+		t.Errorf("Expected the write to be ignored, got %d", regs[0])
+	}
+}
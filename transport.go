@@ -0,0 +1,174 @@
+// Copyright 2025 Edgeo SCADA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modbus
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/edgeo-scada/modbus/internal/transport"
+)
+
+// streamTransport is a byte stream that carries one request/response
+// exchange at a time. It is implemented by the TCP and serial transports.
+type streamTransport interface {
+	Connect(ctx context.Context) error
+	Close() error
+	Exchange(ctx context.Context, data []byte, read transport.ReadFunc) ([]byte, error)
+}
+
+// tcpTransport frames PDUs with an MBAP header for Modbus TCP.
+type tcpTransport struct {
+	conn    *transport.TCPTransport
+	txIDGen TransactionIDGenerator
+}
+
+// Connect establishes the TCP connection.
+func (t *tcpTransport) Connect(ctx context.Context) error {
+	return t.conn.Connect(ctx)
+}
+
+// Close closes the TCP connection.
+func (t *tcpTransport) Close() error {
+	return t.conn.Close()
+}
+
+// Send sends a PDU in an MBAP frame and returns the response PDU.
+func (t *tcpTransport) Send(ctx context.Context, unitID UnitID, pdu []byte) ([]byte, error) {
+	txID := t.txIDGen.Next()
+	frame := Frame{
+		Header: MBAPHeader{
+			TransactionID: txID,
+			ProtocolID:    ProtocolID,
+			UnitID:        unitID,
+		},
+		PDU: pdu,
+	}
+
+	respData, err := t.conn.Send(ctx, frame.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeMBAPResponse(respData, txID, unitID)
+}
+
+// pipelinedTransport frames PDUs with an MBAP header and keeps several
+// transactions in flight on one connection.
+type pipelinedTransport struct {
+	conn    *transport.Pipeline
+	txIDGen TransactionIDGenerator
+}
+
+// Connect establishes the connection and starts the response reader.
+func (t *pipelinedTransport) Connect(ctx context.Context) error {
+	return t.conn.Connect(ctx)
+}
+
+// Close closes the connection.
+func (t *pipelinedTransport) Close() error {
+	return t.conn.Close()
+}
+
+// Send sends a PDU in an MBAP frame and waits for the response with the
+// same transaction ID. It may be called concurrently.
+func (t *pipelinedTransport) Send(ctx context.Context, unitID UnitID, pdu []byte) ([]byte, error) {
+	txID := t.txIDGen.Next()
+	frame := Frame{
+		Header: MBAPHeader{
+			TransactionID: txID,
+			ProtocolID:    ProtocolID,
+			UnitID:        unitID,
+		},
+		PDU: pdu,
+	}
+
+	respData, err := t.conn.Send(ctx, txID, frame.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeMBAPResponse(respData, txID, unitID)
+}
+
+// decodeMBAPResponse decodes an MBAP response frame and validates that it
+// answers the request with the given transaction and unit IDs.
+func decodeMBAPResponse(respData []byte, txID uint16, unitID UnitID) ([]byte, error) {
+	var respFrame Frame
+	if err := respFrame.Decode(respData); err != nil {
+		return nil, err
+	}
+
+	// Validate transaction ID
+	if respFrame.Header.TransactionID != txID {
+		return nil, fmt.Errorf("%w: transaction ID mismatch (expected %d, got %d)",
+			ErrInvalidResponse, txID, respFrame.Header.TransactionID)
+	}
+
+	// Validate unit ID
+	if respFrame.Header.UnitID != unitID {
+		return nil, fmt.Errorf("%w: unit ID mismatch (expected %d, got %d)",
+			ErrInvalidResponse, unitID, respFrame.Header.UnitID)
+	}
+
+	return respFrame.PDU, nil
+}
+
+// udpTransport frames PDUs with an MBAP header, one frame per datagram.
+type udpTransport struct {
+	conn    *transport.UDPTransport
+	txIDGen TransactionIDGenerator
+}
+
+func newUDPTransport(addr string, timeout time.Duration, retransmits int) *udpTransport {
+	return &udpTransport{
+		conn: transport.NewUDPTransport(addr, timeout, retransmits),
+	}
+}
+
+// Connect creates the UDP socket.
+func (t *udpTransport) Connect(ctx context.Context) error {
+	return t.conn.Connect(ctx)
+}
+
+// Close closes the UDP socket.
+func (t *udpTransport) Close() error {
+	return t.conn.Close()
+}
+
+// Send sends a PDU in an MBAP datagram and returns the response PDU.
+// Datagrams carrying another transaction ID are stale and dropped.
+func (t *udpTransport) Send(ctx context.Context, unitID UnitID, pdu []byte) ([]byte, error) {
+	txID := t.txIDGen.Next()
+	frame := Frame{
+		Header: MBAPHeader{
+			TransactionID: txID,
+			ProtocolID:    ProtocolID,
+			UnitID:        unitID,
+		},
+		PDU: pdu,
+	}
+
+	respData, err := t.conn.Exchange(ctx, frame.Encode(), func(resp []byte) bool {
+		return len(resp) >= MBAPHeaderSize && binary.BigEndian.Uint16(resp[0:2]) == txID
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeMBAPResponse(respData, txID, unitID)
+}
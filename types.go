@@ -12,11 +12,12 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Package modbus provides a Modbus TCP client and server implementation.
+// Package modbus provides a Modbus TCP and RTU client and a Modbus TCP server implementation.
 package modbus
 
 import (
 	"context"
+	"net"
 	"time"
 )
 
@@ -28,18 +29,25 @@ type FunctionCode uint8
 
 // Standard Modbus function codes.
 const (
-	FuncReadCoils              FunctionCode = 0x01
-	FuncReadDiscreteInputs     FunctionCode = 0x02
-	FuncReadHoldingRegisters   FunctionCode = 0x03
-	FuncReadInputRegisters     FunctionCode = 0x04
-	FuncWriteSingleCoil        FunctionCode = 0x05
-	FuncWriteSingleRegister    FunctionCode = 0x06
-	FuncReadExceptionStatus    FunctionCode = 0x07
-	FuncDiagnostics            FunctionCode = 0x08
-	FuncGetCommEventCounter    FunctionCode = 0x0B
-	FuncWriteMultipleCoils     FunctionCode = 0x0F
-	FuncWriteMultipleRegisters FunctionCode = 0x10
-	FuncReportServerID         FunctionCode = 0x11
+	FuncReadCoils                      FunctionCode = 0x01
+	FuncReadDiscreteInputs             FunctionCode = 0x02
+	FuncReadHoldingRegisters           FunctionCode = 0x03
+	FuncReadInputRegisters             FunctionCode = 0x04
+	FuncWriteSingleCoil                FunctionCode = 0x05
+	FuncWriteSingleRegister            FunctionCode = 0x06
+	FuncReadExceptionStatus            FunctionCode = 0x07
+	FuncDiagnostics                    FunctionCode = 0x08
+	FuncGetCommEventCounter            FunctionCode = 0x0B
+	FuncGetCommEventLog                FunctionCode = 0x0C
+	FuncWriteMultipleCoils             FunctionCode = 0x0F
+	FuncWriteMultipleRegisters         FunctionCode = 0x10
+	FuncReportServerID                 FunctionCode = 0x11
+	FuncReadFileRecord                 FunctionCode = 0x14
+	FuncWriteFileRecord                FunctionCode = 0x15
+	FuncMaskWriteRegister              FunctionCode = 0x16
+	FuncReadWriteMultipleRegisters     FunctionCode = 0x17
+	FuncReadFIFOQueue                  FunctionCode = 0x18
+	FuncEncapsulatedInterfaceTransport FunctionCode = 0x2B
 )
 
 // Diagnostic sub-function codes (FC08).
@@ -61,11 +69,39 @@ const (
 	DiagClearOverrunCounterAndFlag          uint16 = 0x14
 )
 
+// MEI types carried by encapsulated interface transport (FC43).
+const (
+	MEIReadDeviceIdentification uint8 = 0x0E
+)
+
+// Read device ID codes (FC43/14).
+const (
+	ReadDeviceIDBasic      uint8 = 0x01 // stream access to the basic objects
+	ReadDeviceIDRegular    uint8 = 0x02 // stream access to the basic and regular objects
+	ReadDeviceIDExtended   uint8 = 0x03 // stream access to all objects
+	ReadDeviceIDIndividual uint8 = 0x04 // access to one specific object
+)
+
+// Device identification object IDs (FC43/14).
+const (
+	DeviceIDVendorName          uint8 = 0x00
+	DeviceIDProductCode         uint8 = 0x01
+	DeviceIDMajorMinorRevision  uint8 = 0x02
+	DeviceIDVendorURL           uint8 = 0x03
+	DeviceIDProductName         uint8 = 0x04
+	DeviceIDModelName           uint8 = 0x05
+	DeviceIDUserApplicationName uint8 = 0x06
+)
+
 // Protocol constants.
 const (
 	// MaxQuantityCoils is the maximum number of coils that can be read/written.
 	MaxQuantityCoils = 2000
 
+	// MaxQuantityWriteCoils is the maximum number of coils that can be written
+	// by a write multiple coils request (FC15).
+	MaxQuantityWriteCoils = 1968
+
 	// MaxQuantityDiscreteInputs is the maximum number of discrete inputs that can be read.
 	MaxQuantityDiscreteInputs = 2000
 
@@ -75,6 +111,23 @@ const (
 	// MaxQuantityWriteRegisters is the maximum number of registers that can be written.
 	MaxQuantityWriteRegisters = 123
 
+	// MaxQuantityReadWriteRegisters is the maximum number of registers that can be
+	// written by a read/write multiple registers request (FC23).
+	MaxQuantityReadWriteRegisters = 121
+
+	// MaxFIFOCount is the maximum number of registers in a FIFO queue (FC24).
+	MaxFIFOCount = 31
+
+	// FileRecordReferenceType is the reference type of every file record
+	// sub-request (FC20/FC21).
+	FileRecordReferenceType = 6
+
+	// MaxFileRecordNumber is the highest record number in a file (FC20/FC21).
+	MaxFileRecordNumber = 9999
+
+	// MaxPDUSize is the maximum size of a PDU in bytes.
+	MaxPDUSize = 253
+
 	// MBAPHeaderSize is the size of the MBAP header in bytes.
 	MBAPHeaderSize = 7
 
@@ -86,6 +139,37 @@ const (
 
 	// DefaultPort is the default Modbus TCP port.
 	DefaultPort = 502
+
+	// DefaultTLSPort is the default Modbus/TCP Security port.
+	DefaultTLSPort = 802
+
+	// DefaultBaudRate is the default serial line speed for Modbus RTU.
+	DefaultBaudRate = 19200
+)
+
+// Parity represents the parity setting of a serial line.
+type Parity byte
+
+// Serial line parity settings.
+const (
+	ParityNone Parity = 'N'
+	ParityEven Parity = 'E'
+	ParityOdd  Parity = 'O'
+)
+
+// Framing selects how PDUs are framed on a TCP connection.
+type Framing int
+
+// Framing modes for TCP clients.
+const (
+	// FramingTCP frames PDUs with an MBAP header (standard Modbus TCP).
+	FramingTCP Framing = iota
+
+	// FramingRTU sends raw RTU frames with CRC, as used by serial device servers.
+	FramingRTU
+
+	// FramingASCII sends ASCII frames with LRC.
+	FramingASCII
 )
 
 // Coil values for write operations.
@@ -107,11 +191,49 @@ type Response interface {
 }
 
 // Transporter defines the interface for sending and receiving Modbus frames.
+// Send takes a request PDU and returns the response PDU; framing, checksums
+// and response matching are the responsibility of the transport.
 type Transporter interface {
 	Send(ctx context.Context, unitID UnitID, pdu []byte) ([]byte, error)
 	Close() error
 }
 
+// Connector is implemented by transports that must establish a connection
+// before the first request is sent. Client.Connect calls it when the
+// transport implements it.
+type Connector interface {
+	Connect(ctx context.Context) error
+}
+
+// FunctionHandler serves one function code on the server side, see
+// Server.HandleFunc. It receives the request PDU, function code included,
+// and returns the response PDU. A *ModbusError is answered with an exception
+// response; a nil PDU with a nil error sends no response.
+type FunctionHandler func(unitID UnitID, pdu []byte) ([]byte, error)
+
+// ServerRequest is a request received by the server, see Server.Use.
+type ServerRequest struct {
+	UnitID        UnitID
+	TransactionID uint16
+	PDU           []byte   // request PDU, function code included
+	RemoteAddr    net.Addr // address of the client, nil if unknown
+	Role          string   // Modbus/TCP Security role of the client, if any
+}
+
+// FunctionCode returns the function code of the request.
+func (r *ServerRequest) FunctionCode() FunctionCode {
+	return FunctionCode(r.PDU[0])
+}
+
+// RequestHandler serves a request and returns the response PDU, function
+// code included. As for FunctionHandler, a *ModbusError is answered with an
+// exception response and a nil PDU with a nil error sends no response.
+type RequestHandler func(ctx context.Context, req *ServerRequest) ([]byte, error)
+
+// Middleware wraps the handling of requests by the server, to log, audit,
+// authorize, rate limit or alter them. It calls next to serve a request.
+type Middleware func(next RequestHandler) RequestHandler
+
 // Handler defines the interface for handling Modbus requests on the server side.
 type Handler interface {
 	// Coil operations
@@ -128,11 +250,57 @@ type Handler interface {
 
 	// Diagnostic operations
 	ReadExceptionStatus(unitID UnitID) (uint8, error)
+	// Diagnostics answers Return Query Data and non-standard sub-functions
+	// of FC08. The counter and control sub-functions are handled by the Server.
 	Diagnostics(unitID UnitID, subFunc uint16, data []byte) ([]byte, error)
+	// GetCommEventCounter reports the status word of FC11 and FC12 (0xFFFF
+	// while a previous command is still being processed, 0x0000 otherwise).
+	// The event count is maintained by the Server; the returned one is ignored.
 	GetCommEventCounter(unitID UnitID) (status uint16, eventCount uint16, err error)
 	ReportServerID(unitID UnitID) ([]byte, error)
 }
 
+// ReadWriteMultipleRegistersHandler is an optional interface for handlers
+// that perform read/write multiple registers (FC23) as a single operation.
+// The write must be applied before the read. Handlers that do not implement
+// it are served with WriteMultipleRegisters followed by ReadHoldingRegisters.
+type ReadWriteMultipleRegistersHandler interface {
+	ReadWriteMultipleRegisters(unitID UnitID, readAddr, readQty, writeAddr uint16, values []uint16) ([]uint16, error)
+}
+
+// MaskWriteRegisterHandler is an optional interface for handlers that
+// apply mask write register (FC22) atomically. Handlers that do not
+// implement it are served with a read followed by a write.
+type MaskWriteRegisterHandler interface {
+	MaskWriteRegister(unitID UnitID, addr, andMask, orMask uint16) error
+}
+
+// FileRecordHandler is an optional interface for handlers that store file
+// records (FC20/FC21). Each sub-request of a request is passed separately.
+// Handlers that do not implement it answer FC20 and FC21 with an illegal
+// function exception.
+type FileRecordHandler interface {
+	ReadFileRecord(unitID UnitID, file, record, length uint16) ([]uint16, error)
+	WriteFileRecord(unitID UnitID, file, record uint16, values []uint16) error
+}
+
+// FIFOQueueHandler is an optional interface for handlers that expose FIFO
+// queues (FC24). ReadFIFOQueue returns the queued registers without
+// removing them. Handlers that do not implement it answer FC24 with an
+// illegal function exception.
+type FIFOQueueHandler interface {
+	ReadFIFOQueue(unitID UnitID, addr uint16) ([]uint16, error)
+}
+
+// DeviceIdentificationHandler is an optional interface for handlers that
+// support read device identification (FC43/14). It returns the objects of
+// the device keyed by object ID; the server selects the objects for the
+// requested access and splits them into pages. Handlers that do not
+// implement it answer FC43 with an illegal function exception.
+type DeviceIdentificationHandler interface {
+	DeviceIdentification(unitID UnitID) (map[uint8][]byte, error)
+}
+
 // ConnectionState represents the state of a client connection.
 type ConnectionState int
 